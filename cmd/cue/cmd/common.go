@@ -84,6 +84,16 @@ func exitOnErr(cmd *Command, err error, fatal bool) {
 		return
 	}
 
+	if format, _ := errorsFormat(cmd); format != errorsText {
+		// Errors are printed as a single document by Command.Run.
+		cmd.errs = append(cmd.errs, diagnostics(err)...)
+		cmd.hasErr = true
+		if fatal {
+			exit()
+		}
+		return
+	}
+
 	// Link x/text as our localizer.
 	p := message.NewPrinter(getLang())
 	format := func(w io.Writer, format string, args ...interface{}) {
@@ -107,6 +117,12 @@ func exitOnErr(cmd *Command, err error, fatal bool) {
 }
 
 func loadFromArgs(cmd *Command, args []string, cfg *load.Config) []*build.Instance {
+	if cfg == nil {
+		cfg = &load.Config{}
+	}
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = cacheDir()
+	}
 	binst := load.Instances(args, cfg)
 	if len(binst) == 0 {
 		return nil
@@ -115,6 +131,24 @@ func loadFromArgs(cmd *Command, args []string, cfg *load.Config) []*build.Instan
 	return binst
 }
 
+// cacheDir reports the directory for storing archives of imported packages
+// as configured by the CUECACHE environment variable, or "" if archives
+// should not be used.
+func cacheDir() string {
+	switch dir := os.Getenv("CUECACHE"); dir {
+	case "", "off":
+		return ""
+	case "on":
+		dir, err := load.DefaultCacheDir()
+		if err != nil {
+			return ""
+		}
+		return dir
+	default:
+		return dir
+	}
+}
+
 // A buildPlan defines what should be done based on command line
 // arguments and flags.
 //
@@ -236,6 +270,13 @@ func newStreamingIterator(b *buildPlan) *streamingIterator {
 			"cannot combine data streaming with multiple instances")}
 	}
 
+	if i.base.Exists() {
+		// Formats like CSV use the schema to determine the types of values.
+		cfg := *i.cfg
+		cfg.Schema = i.base
+		i.cfg = &cfg
+	}
+
 	return i
 }
 
@@ -419,7 +460,8 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 			switch f.Encoding {
 			case build.Protobuf:
 				p.orphanedSchema = append(p.orphanedSchema, f)
-			case build.YAML, build.JSON, build.Text:
+			case build.YAML, build.JSON, build.JSONC, build.HCL, build.XML, build.CSV, build.TSV,
+				build.Env, build.Properties, build.Text:
 				p.orphanedData = append(p.orphanedData, f)
 			default:
 				return nil, errors.Newf(token.NoPos,
@@ -0,0 +1,328 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/text/message"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Formats for the --errors flag.
+const (
+	errorsText  = "text"
+	errorsJSON  = "json"
+	errorsSARIF = "sarif"
+)
+
+// defaultErrorCode is reported for errors that do not have a code.
+const defaultErrorCode errors.Code = "error"
+
+func addErrorsFlag(f *pflag.FlagSet) {
+	f.String(string(flagErrors), errorsText,
+		"format for reporting errors: text, json, or sarif")
+}
+
+// errorsFormat reports the format selected with the --errors flag.
+func errorsFormat(cmd *Command) (string, error) {
+	switch f := flagErrors.String(cmd); f {
+	case "", errorsText:
+		return errorsText, nil
+	case errorsJSON, errorsSARIF:
+		return f, nil
+	default:
+		return "", errors.Newf(token.NoPos,
+			"invalid value %q for --errors: must be text, json, or sarif", f)
+	}
+}
+
+// diagnostic is the JSON representation of an error.
+type diagnostic struct {
+	Code           errors.Code `json:"code"`
+	Message        string      `json:"message"`
+	Path           []string    `json:"path,omitempty"`
+	Positions      []position  `json:"positions,omitempty"`
+	InputPositions []position  `json:"inputPositions,omitempty"`
+	Suggestions    []string    `json:"suggestions,omitempty"`
+}
+
+type position struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// diagnostics converts err to a list of diagnostics. Filenames are made
+// relative to the current directory.
+func diagnostics(err error) []diagnostic {
+	p := message.NewPrinter(getLang())
+	cwd, _ := os.Getwd()
+
+	positions := func(a []token.Pos) (ps []position) {
+		seen := map[token.Pos]bool{}
+		for _, pos := range a {
+			if !pos.IsValid() || seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			pp := pos.Position()
+			file := pp.Filename
+			if cwd != "" {
+				if rel, err := filepath.Rel(cwd, file); err == nil {
+					file = rel
+				}
+			}
+			ps = append(ps, position{
+				File:   filepath.ToSlash(file),
+				Line:   pp.Line,
+				Column: pp.Column,
+			})
+		}
+		return ps
+	}
+
+	if e, ok := err.(errors.Error); ok {
+		err = errors.Sanitize(e)
+	}
+	var a []diagnostic
+	for _, e := range errors.Errors(err) {
+		code := errors.CodeOf(e)
+		if code == "" {
+			code = defaultErrorCode
+		}
+		a = append(a, diagnostic{
+			Code:           code,
+			Message:        errorMessage(p, e),
+			Path:           e.Path(),
+			Positions:      positions(errors.Positions(e)),
+			InputPositions: positions(e.InputPositions()),
+			Suggestions:    errors.Suggestions(e),
+		})
+	}
+	return a
+}
+
+// errorMessage returns the localized message of err without its path.
+func errorMessage(p *message.Printer, err errors.Error) string {
+	w := &strings.Builder{}
+	errors.Print(w, err, &errors.Config{
+		Format: func(w io.Writer, format string, args ...interface{}) {
+			p.Fprintf(w, format, args...)
+		},
+	})
+	// Print writes the message on the first line, followed by a colon and
+	// the positions on subsequent lines.
+	msg := strings.SplitN(w.String(), "\n", 2)[0]
+	msg = strings.TrimSuffix(msg, ":")
+	if path := strings.Join(err.Path(), "."); path != "" {
+		msg = strings.TrimPrefix(msg, path+": ")
+	}
+	return msg
+}
+
+// printErrors writes the errors collected by exitOnErr if a structured format
+// was selected for reporting errors. The output is written even if there
+// are no errors, so that tools can distinguish a successful run from a
+// failure to run.
+func (c *Command) printErrors() {
+	format, err := errorsFormat(c)
+	if err != nil || format == errorsText {
+		return
+	}
+	if err := printDiagnostics(c.Command.OutOrStderr(), format, c.errs); err != nil {
+		fmt.Fprintln(c.Command.OutOrStderr(), err)
+	}
+}
+
+// printDiagnostics writes errs to w in the given format.
+func printDiagnostics(w io.Writer, format string, errs []diagnostic) error {
+	if errs == nil {
+		errs = []diagnostic{}
+	}
+	var doc interface{} = errs
+	if format == errorsSARIF {
+		doc = sarifLog(errs)
+	}
+	b, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// SARIF 2.1.0 types. Only the subset needed for reporting results is defined.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+type sarifDoc struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                   `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+func sarifLog(errs []diagnostic) *sarifDoc {
+	driver := sarifDriver{
+		Name:           "cue",
+		InformationURI: "https://cuelang.org",
+		Rules:          []sarifRule{},
+	}
+	if version != defaultVersion {
+		driver.Version = version
+	}
+	ruleIndex := map[errors.Code]int{}
+	results := []sarifResult{}
+	for _, e := range errs {
+		index, ok := ruleIndex[e.Code]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndex[e.Code] = index
+			driver.Rules = append(driver.Rules, sarifRule{ID: string(e.Code)})
+		}
+		r := sarifResult{
+			RuleID:    string(e.Code),
+			RuleIndex: index,
+			Level:     "error",
+			Message:   sarifMessage{Text: e.Message},
+		}
+		var logical []sarifLogicalLocation
+		if len(e.Path) > 0 {
+			logical = []sarifLogicalLocation{{
+				FullyQualifiedName: strings.Join(e.Path, "."),
+			}}
+		}
+		// The primary position, if any, is the first.
+		for i, p := range append(e.Positions, e.InputPositions...) {
+			loc := sarifLocation{PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(p.File)},
+				Region:           sarifRegion{StartLine: p.Line, StartColumn: p.Column},
+			}}
+			if i == 0 {
+				loc.LogicalLocations = logical
+				r.Locations = append(r.Locations, loc)
+				continue
+			}
+			if containsLocation(r.RelatedLocations, loc) ||
+				containsLocation(r.Locations, loc) {
+				continue
+			}
+			id := len(r.RelatedLocations)
+			loc.ID = &id
+			r.RelatedLocations = append(r.RelatedLocations, loc)
+		}
+		if len(r.Locations) == 0 && logical != nil {
+			r.Locations = []sarifLocation{{LogicalLocations: logical}}
+		}
+		results = append(results, r)
+	}
+	return &sarifDoc{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	}
+}
+
+func containsLocation(a []sarifLocation, loc sarifLocation) bool {
+	for _, l := range a {
+		if *l.PhysicalLocation == *loc.PhysicalLocation {
+			return true
+		}
+	}
+	return false
+}
+
+// sarifURI converts a file name to a URI reference. Relative file names
+// result in relative references.
+func sarifURI(file string) string {
+	if filepath.IsAbs(file) {
+		path := filepath.ToSlash(file)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path // Windows drive letter
+		}
+		u := url.URL{Scheme: "file", Path: path}
+		return u.String()
+	}
+	u := url.URL{Path: file}
+	return u.String()
+}
@@ -78,13 +78,46 @@ Formats
 The following formats are recognized:
 
 json    output as JSON
-               Outputs any CUE value.
+               Outputs any CUE value. Use json+canonical for
+               canonical JSON as defined by RFC 8785, for
+               instance for hashing or signing. Numbers that
+               cannot be represented exactly result in an error.
 
 text    output as raw text
                 The evaluated value must be of type string.
 
+hcl     output as HCL
+                The evaluated value must be a struct.
+
+xml     output as XML
+                The evaluated value must be a struct with a single
+                field, which is converted to the root element.
+
+csv     output as comma-separated values
+                The evaluated value must be a list of structs
+                with null, boolean, number, or string fields.
+
+tsv     output as tab-separated values
+                Same as csv, but with tabs as separators.
+
+env     output as a dotenv file
+                The evaluated value must be a struct with null,
+                boolean, number, or string fields.
+
+properties  output as a Java properties file
+                The evaluated value must be a struct. Fields of
+                nested structs are written with dotted keys; it
+                is an error if two fields result in the same key.
+
+ts      output as TypeScript declarations
+                Outputs the top-level definitions as TypeScript
+                interfaces and type aliases. It is an error if
+                there are none, as is the case for a value
+                selected with --expression.
+
 yaml    output as YAML
-                Outputs any CUE value.
+                Outputs any CUE value. With yaml+docs, doc
+                comments are written as YAML comments.
 `,
 
 		RunE: mkRunE(c, runExport),
@@ -92,6 +125,7 @@ yaml    output as YAML
 
 	addOutFlags(cmd.Flags(), true)
 	addOrphanFlags(cmd.Flags())
+	addErrorsFlag(cmd.Flags())
 
 	cmd.Flags().Bool(string(flagEscape), false, "use HTML escaping")
 
@@ -45,6 +45,7 @@ const (
 	flagWithContext flagName = "with-context"
 	flagOut         flagName = "out"
 	flagOutFile     flagName = "outfile"
+	flagErrors      flagName = "errors"
 )
 
 func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
@@ -88,6 +89,11 @@ func (f flagName) Bool(cmd *Command) bool {
 	return v
 }
 
+func (f flagName) Int(cmd *Command) int {
+	v, _ := cmd.Flags().GetInt(string(f))
+	return v
+}
+
 func (f flagName) String(cmd *Command) string {
 	v, _ := cmd.Flags().GetString(string(f))
 	return v
@@ -15,20 +15,47 @@
 package cmd
 
 import (
+	"fmt"
+	"path"
+
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/encoding"
 )
 
 func newFmtCmd(c *Command) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "fmt [-s] [inputs]",
+		Use:   "fmt [-s] [--imports] [--sort-fields] [--max-width n] [--structs mode] [inputs]",
 		Short: "formats CUE configuration files",
 		Long: `Fmt formats the given files or the files for the given packages in place
+
+With --imports, fmt also manages the import declarations of the files it
+formats: unused imports are removed, imports are added for unresolved
+identifiers that name a package of the standard library or one of the
+packages being formatted, such as strings in strings.ToUpper, and the imports
+are sorted with the standard library imports grouped first. An import is only
+added if exactly one package has the given name.
+
+The following flags control the layout of the output, so that a single
+canonical layout can be enforced:
+
+  --sort-fields   sort the fields of structs alphabetically, definitions
+                  first; blank lines and comments that are not attached to
+                  a field separate the groups of fields that are sorted
+  --max-width n   split lists and binary expressions that would exceed a
+                  line width of n over multiple lines
+  --structs mode  expand or collapse structs with a single field that are
+                  the value of a field; mode is one of:
+                      expand    a: {
+                                    b: 1
+                                }
+                      collapse  a: b: 1
 `,
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
 			plan, err := parseArgs(cmd, args, &config{loadCfg: &load.Config{
@@ -41,6 +68,21 @@ func newFmtCmd(c *Command) *cobra.Command {
 			if flagSimplify.Bool(cmd) {
 				opts = append(opts, format.Simplify())
 			}
+			if flagSortFields.Bool(cmd) {
+				opts = append(opts, format.SortFields())
+			}
+			if w := flagMaxWidth.Int(cmd); w > 0 {
+				opts = append(opts, format.MaxWidth(w))
+			}
+			switch mode := flagStructs.String(cmd); mode {
+			case "":
+			case "expand":
+				opts = append(opts, format.ExpandStructs())
+			case "collapse":
+				opts = append(opts, format.CollapseStructs())
+			default:
+				return fmt.Errorf("invalid value %q for --structs: must be expand or collapse", mode)
+			}
 
 			cfg := *plan.encConfig
 			cfg.Format = opts
@@ -59,8 +101,9 @@ func newFmtCmd(c *Command) *cobra.Command {
 						Encoding: build.CUE,
 					})
 				}
-				for _, file := range all {
-					files := []*ast.File{}
+
+				decoded := make([][]*ast.File, len(all))
+				for i, file := range all {
 					d := encoding.NewDecoder(file, &cfg)
 					defer d.Close()
 					for ; !d.Done(); d.Next() {
@@ -70,13 +113,19 @@ func newFmtCmd(c *Command) *cobra.Command {
 							f = fix(f)
 						}
 
-						files = append(files, f)
+						decoded[i] = append(decoded[i], f)
 					}
+				}
+
+				if flagImports.Bool(cmd) {
+					fixImports(inst, plan.insts, all, decoded)
+				}
 
+				for i, file := range all {
 					e, err := encoding.NewEncoder(file, &cfg)
 					exitOnErr(cmd, err, true)
 
-					for _, f := range files {
+					for _, f := range decoded[i] {
 						err := e.EncodeFile(f)
 						exitOnErr(cmd, err, false)
 					}
@@ -86,5 +135,76 @@ func newFmtCmd(c *Command) *cobra.Command {
 			return nil
 		}),
 	}
+
+	cmd.Flags().Bool(string(flagImports), false,
+		"add missing, remove unused, and sort imports")
+	cmd.Flags().Bool(string(flagSortFields), false,
+		"sort fields alphabetically, definitions first")
+	cmd.Flags().Int(string(flagMaxWidth), 0,
+		"preferred maximum line width")
+	cmd.Flags().String(string(flagStructs), "",
+		"expand or collapse single-field structs (expand|collapse)")
+
 	return cmd
 }
+
+const (
+	flagImports    flagName = "imports"
+	flagSortFields flagName = "sort-fields"
+	flagMaxWidth   flagName = "max-width"
+	flagStructs    flagName = "structs"
+)
+
+// fixImports fixes the imports of the CUE files of inst. Imports are added for
+// packages of the standard library and the other packages of insts, which
+// are the packages being formatted. Identifiers are not considered to name a
+// package if they are declared at the top level of any file of the package.
+func fixImports(inst *build.Instance, insts []*build.Instance, files []*build.File, decoded [][]*ast.File) {
+	declared := map[string]bool{}
+	for i, file := range files {
+		if file.Encoding != build.CUE {
+			continue
+		}
+		for _, f := range decoded[i] {
+			for _, d := range f.Decls {
+				switch x := d.(type) {
+				case *ast.Field:
+					if name, _, err := ast.LabelName(x.Label); err == nil {
+						declared[name] = true
+					}
+				case *ast.Alias:
+					declared[x.Ident.Name] = true
+				}
+			}
+		}
+	}
+
+	paths := map[string][]string{}
+	for _, p := range internal.BuiltinPackages() {
+		name := path.Base(p)
+		paths[name] = append(paths[name], p)
+	}
+	seen := map[string]bool{inst.ImportPath: true}
+	for _, p := range insts {
+		if p.PkgName != "" && p.ImportPath != "" && !seen[p.ImportPath] {
+			seen[p.ImportPath] = true
+			paths[p.PkgName] = append(paths[p.PkgName], p.ImportPath)
+		}
+	}
+
+	lookup := func(name string) string {
+		if declared[name] || len(paths[name]) != 1 {
+			return ""
+		}
+		return paths[name][0]
+	}
+
+	for i, file := range files {
+		if file.Encoding != build.CUE {
+			continue
+		}
+		for _, f := range decoded[i] {
+			astutil.FixImports(f, lookup)
+		}
+	}
+}
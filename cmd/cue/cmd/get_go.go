@@ -119,6 +119,38 @@ Go structs are converted to cue structs adhering to the following conventions:
 	  between the generated CUE and the original Go.
 
 
+Struct Tags
+
+Constraints on fields may be specified with struct tags. The expression in a
+"cue" tag, as interpreted by the cuego package, is unified with the type of
+the field. The expression may refer to other fields of the same struct.
+The "opt" and "req" options mark a field as optional or required. For
+instance, the Go struct
+
+	type Sum struct {
+		A int ` + "`" + `cue:"C-B"` + "`" + `
+		B int ` + "`" + `cue:"C-A"` + "`" + `
+		C int ` + "`" + `cue:"A+B & >=5"` + "`" + `
+	}
+
+translates to
+
+	Sum :: {
+		A: int & C-B
+		B: int & C-A
+		C: int & A+B & >=5
+	}
+
+The rules of "validate" tags, as used by github.com/go-playground/validator,
+are translated to equivalent CUE constraints where possible. The supported
+rules are required, omitempty, min, max, len, gt, gte, lt, and lte for strings,
+numbers, lists, and maps, oneof for strings and numbers, and email and url for
+strings. For strings, lists, and maps, lengths are expressed using the
+builtin strings, list, and struct packages. The rules of a field marked
+omitempty do not apply to its zero value. Other rules are reported and
+otherwise ignored.
+
+
 Native CUE Constraints
 
 Native CUE constraints may be defined in separate cue files alongside the
@@ -160,7 +192,8 @@ Constants and Enums
 Go does not have an enum or sum type. Conventionally, a type that is supposed
 to be an enum is followed by a const block with the allowed values for that
 type. However, as that is only a guideline and not a hard rule, these cases
-cannot be translated to CUE disjunctions automatically.
+are not translated to CUE disjunctions by default. See the --enums flag
+below for how to do so.
 
 Constant values, however, are generated in a way that makes it easy to convert
 a type to a proper enum using native CUE constraints. For instance, the Go type
@@ -201,22 +234,55 @@ This tells CUE that only the values enumerated by enumSwitch are valid
 values for Switch. Note that there are now two definitions of Switch.
 CUE handles this in the usual way by unifying the two definitions, in which case
 the more restrictive enum interpretation of Switch remains.
-`,
-		// - TODO: interpret cuego's struct tags and annotations.
 
+With --enums=auto, cue get go applies this constraint itself for types that
+look like enums. A type is considered an enum if all its constants are
+exported and it either has at least two constants declared in a single const
+block, as is the case with iota, or it has a String method generated by the
+stringer tool. The above Go type then translates into
+
+	Switch :: enumSwitch
+
+	enumSwitch :: Off | On
+
+	Off :: int & 0
+	On ::  int & 1
+
+Note that the constants of an enum are defined in terms of the underlying type,
+as the enum type itself is defined in terms of its constants. To exclude a type from this interpretation, add a //cue:noenum directive to
+its doc comment:
+
+	//cue:noenum
+	type Flags int
+
+The --enums=manual mode, which is the default, never constrains a type to its
+constants.
+`,
 		RunE: mkRunE(c, extract),
 	}
 
 	cmd.Flags().StringP(string(flagExclude), "e", "",
 		"comma-separated list of regexps of entries")
+	cmd.Flags().String(string(flagEnums), enumsManual,
+		"how to translate enums: manual or auto")
 
 	return cmd
 }
 
 const (
 	flagExclude flagName = "exclude"
+	flagEnums   flagName = "enums"
+)
+
+// Modes for the --enums flag.
+const (
+	enumsManual = "manual"
+	enumsAuto   = "auto"
 )
 
+// noEnumDirective excludes a type from being interpreted as an enum.
+const noEnumDirective = "//cue:noenum"
+
 var cueTestRoot string // the CUE module root for test purposes.
 
 func (e *extractor) initExclusions(str string) {
@@ -252,14 +318,19 @@ type extractor struct {
 	usedPkgs map[string]bool
 
 	// per file
-	cmap       ast.CommentMap
-	pkg        *packages.Package
-	consts     map[string][]string
-	pkgNames   map[string]pkgInfo
-	usedInFile map[string]bool
+	cmap         ast.CommentMap
+	pkg          *packages.Package
+	consts       map[string][]string
+	constBlocks  map[string]bool // types with several constants in one block
+	noEnums      map[string]bool // types marked with the noenum directive
+	stringers    map[string]bool // types with a stringer-generated String method
+	pkgNames     map[string]pkgInfo
+	usedInFile   map[string]bool
+	usedBuiltins map[string]bool // builtin CUE packages used in file
 
 	exclusions []*regexp.Regexp
 	exclude    string
+	enums      string
 }
 
 type pkgInfo struct {
@@ -318,6 +389,13 @@ var (
 // - consider not including types with any dropped fields.
 
 func extract(cmd *Command, args []string) error {
+	enums := flagEnums.String(cmd)
+	switch enums {
+	case enumsManual, enumsAuto:
+	default:
+		return fmt.Errorf("invalid value %q for --enums: must be manual or auto", enums)
+	}
+
 	// determine module root:
 	binst := loadFromArgs(cmd, []string{"."}, nil)[0]
 
@@ -346,6 +424,7 @@ func extract(cmd *Command, args []string) error {
 		stderr: cmd.Stderr(),
 		pkgs:   pkgs,
 		orig:   map[types.Type]*ast.StructType{},
+		enums:  enums,
 	}
 
 	e.initExclusions(flagExclude.String(cmd))
@@ -383,12 +462,21 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 	e.recordTypeInfo(p)
 
 	e.consts = map[string][]string{}
+	e.constBlocks = map[string]bool{}
+	e.noEnums = map[string]bool{}
+	e.stringers = map[string]bool{}
 
 	for _, f := range p.Syntax {
+		stringer := isStringerFile(f)
 		for _, d := range f.Decls {
 			switch x := d.(type) {
 			case *ast.GenDecl:
 				e.recordConsts(x)
+				e.recordNoEnums(x)
+			case *ast.FuncDecl:
+				if stringer {
+					e.recordStringer(x)
+				}
 			}
 		}
 	}
@@ -405,12 +493,16 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 	if e.exclude != "" {
 		args += " --exclude=" + e.exclude
 	}
+	if e.enums != enumsManual {
+		args += " --enums=" + e.enums
+	}
 
 	for i, f := range p.Syntax {
 		e.cmap = ast.NewCommentMap(p.Fset, f, f.Comments)
 
 		e.pkgNames = map[string]pkgInfo{}
 		e.usedInFile = map[string]bool{}
+		e.usedBuiltins = map[string]bool{}
 
 		for _, spec := range f.Imports {
 			pkgPath, _ := strconv.Unquote(spec.Path.Value)
@@ -444,6 +536,11 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 		for k := range e.usedInFile {
 			pkgs = append(pkgs, k)
 		}
+		for k := range e.usedBuiltins {
+			if !e.usedInFile[k] {
+				pkgs = append(pkgs, k)
+			}
+		}
 		sort.Strings(pkgs)
 
 		pkg := &cueast.Package{Name: e.ident(p.Name)}
@@ -461,6 +558,10 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 			imports := &cueast.ImportDecl{}
 			f.Decls = append(f.Decls, imports)
 			for _, s := range pkgs {
+				if !e.usedInFile[s] {
+					imports.Specs = append(imports.Specs, cueast.NewImport(nil, s))
+					continue
+				}
 				info := e.pkgNames[s]
 				spec := cueast.NewImport(nil, info.id)
 				if p.Imports[s].Name != info.name {
@@ -546,6 +647,7 @@ func (e *extractor) recordConsts(x *ast.GenDecl) {
 	if x.Tok != token.CONST {
 		return
 	}
+	count := map[string]int{}
 	for _, s := range x.Specs {
 		v, ok := s.(*ast.ValueSpec)
 		if !ok {
@@ -554,10 +656,91 @@ func (e *extractor) recordConsts(x *ast.GenDecl) {
 		for _, n := range v.Names {
 			typ := e.pkg.TypesInfo.TypeOf(n).String()
 			e.consts[typ] = append(e.consts[typ], n.Name)
+			count[typ]++
+		}
+	}
+	for typ, n := range count {
+		if n > 1 {
+			e.constBlocks[typ] = true
 		}
 	}
 }
 
+// isStringerFile reports whether f was generated by the stringer tool.
+func isStringerFile(f *ast.File) bool {
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if strings.HasPrefix(c.Text, `// Code generated by "stringer `) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordStringer records the receiver type of x if x is a String method.
+func (e *extractor) recordStringer(x *ast.FuncDecl) {
+	if x.Name.Name != "String" || x.Recv == nil || len(x.Recv.List) != 1 {
+		return
+	}
+	typ := e.pkg.TypesInfo.TypeOf(x.Recv.List[0].Type)
+	if typ == nil {
+		return
+	}
+	e.stringers[typ.String()] = true
+}
+
+// recordNoEnums records the types declared in x that are marked with the
+// noenum directive.
+func (e *extractor) recordNoEnums(x *ast.GenDecl) {
+	if x.Tok != token.TYPE {
+		return
+	}
+	for _, s := range x.Specs {
+		v, ok := s.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		if hasDirective(x.Doc, noEnumDirective) ||
+			hasDirective(v.Doc, noEnumDirective) {
+			e.noEnums[e.pkg.TypesInfo.TypeOf(v.Name).String()] = true
+		}
+	}
+}
+
+func hasDirective(g *ast.CommentGroup, directive string) bool {
+	if g == nil {
+		return false
+	}
+	for _, c := range g.List {
+		if c.Text == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnum reports whether the given type should be constrained to its
+// constants.
+func (e *extractor) isEnum(typ string) bool {
+	consts := e.consts[typ]
+	if e.enums != enumsAuto || len(consts) == 0 || e.noEnums[typ] {
+		return false
+	}
+	if !e.constBlocks[typ] && !e.stringers[typ] {
+		return false
+	}
+	for _, name := range consts {
+		if !ast.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
 func (e *extractor) strLabel(name string) cueast.Label {
 	return cueast.NewString(name)
 }
@@ -589,8 +772,9 @@ func (e *extractor) reportDecl(x *ast.GenDecl) (a []cueast.Decl) {
 			}
 
 			typ := e.pkg.TypesInfo.TypeOf(v.Name)
-			enums := e.consts[typ.String()]
 			name := v.Name.Name
+			enumName := "enum" + name
+			isEnum := false
 			switch tn, ok := e.pkg.TypesInfo.Defs[v.Name].(*types.TypeName); {
 			case ok:
 				if altType := e.altType(tn.Type()); altType != nil {
@@ -610,7 +794,11 @@ func (e *extractor) reportDecl(x *ast.GenDecl) (a []cueast.Decl) {
 					a = append(a, e.def(x.Doc, name, s, true))
 					break
 				}
-				// TODO: only print original type if value is not marked as enum.
+				if e.isEnum(typ.String()) {
+					isEnum = true
+					a = append(a, e.def(x.Doc, name, e.ident(enumName), true))
+					break
+				}
 				underlying := e.pkg.TypesInfo.TypeOf(v.Type)
 				f, _ := e.makeField(name, cuetoken.ISA, underlying, x.Doc, true)
 				a = append(a, f)
@@ -618,9 +806,17 @@ func (e *extractor) reportDecl(x *ast.GenDecl) (a []cueast.Decl) {
 
 			}
 
+			// Unexported constants are not generated.
+			var enums []string
+			for _, c := range e.consts[typ.String()] {
+				if ast.IsExported(c) {
+					enums = append(enums, c)
+				}
+			}
 			if len(enums) > 0 {
-				enumName := "enum" + name
-				a[len(a)-1].AddComment(internal.NewComment(false, enumName))
+				if !isEnum {
+					a[len(a)-1].AddComment(internal.NewComment(false, enumName))
+				}
 
 				var x cueast.Expr = e.ident(enums[0])
 				cueast.SetRelPos(x, cuetoken.Newline)
@@ -678,6 +874,11 @@ func (e *extractor) reportDecl(x *ast.GenDecl) (a []cueast.Decl) {
 					switch s {
 					case "byte", "string", "error":
 					default:
+						// The type of an enum is defined in terms of its
+						// constants, so these use the underlying type.
+						if e.isEnum(s) {
+							typ = typ.Underlying()
+						}
 						cv = cueast.NewBinExpr(cuetoken.AND, e.makeType(typ), cv)
 					}
 				}
@@ -741,6 +942,13 @@ func makeDoc(g *ast.CommentGroup, isDoc bool) *cueast.CommentGroup {
 		switch c[1] {
 		case '/':
 			//-style comment (no newline at the end)
+			if c == noEnumDirective {
+				// Drop the directive and the empty lines preceding it.
+				for n := len(a); n > 0 && a[n-1].Text == "//"; n-- {
+					a = a[:n-1]
+				}
+				continue
+			}
 			a = append(a, &cueast.Comment{Text: c})
 
 		case '*':
@@ -1010,6 +1218,15 @@ func (e *extractor) addFields(x *types.Struct, st *cueast.StructLit) {
 			}
 		}
 	}
+	// Expressions in cue tags may refer to other fields, in which case these
+	// need to be referable by identifier.
+	hasCueTags := false
+	for i := 0; i < x.NumFields(); i++ {
+		if _, ok := reflect.StructTag(x.Tag(i)).Lookup("cue"); ok {
+			hasCueTags = true
+		}
+	}
+
 	count := 0
 	for i := 0; i < x.NumFields(); i++ {
 		f := x.Field(i)
@@ -1051,9 +1268,20 @@ func (e *extractor) addFields(x *types.Struct, st *cueast.StructLit) {
 		if _, ok := f.Type().(*types.Pointer); ok {
 			kind = cuetoken.OPTION
 		}
+		switch {
+		case e.isRequired(tag):
+			kind = cuetoken.COLON
+		case hasFlag(tag, "cue", "opt", 1):
+			kind = cuetoken.OPTION
+		}
 		field, cueType := e.makeField(name, kind, f.Type(), docs[i], count > 0)
+		if hasCueTags && cueast.IsValidIdent(name) {
+			field.Label = e.ident(name)
+		}
 		add(field)
 
+		e.addConstraints(field, f, tag)
+
 		// Add field tag to convert back to Go.
 		typeName := f.Type().String()
 		// simplify type names:
@@ -1125,6 +1353,293 @@ func (e *extractor) isOptional(tag string) bool {
 		hasFlag(tag, "yaml", "omitempty", 1)
 }
 
+// isRequired reports whether a field is marked as required by a cue or
+// validate tag.
+func (e *extractor) isRequired(tag string) bool {
+	return hasFlag(tag, "cue", "req", 1) ||
+		hasFlag(tag, "validate", "required", 0)
+}
+
+// addConstraints adds the constraints defined by the cue and validate tags of
+// a Go struct field to the value of the corresponding CUE field. Tags that
+// cannot be translated are reported.
+func (e *extractor) addConstraints(field *cueast.Field, f *types.Var, tag string) {
+	var constraints []cueast.Expr
+
+	tags := reflect.StructTag(tag)
+	if t := tags.Get("cue"); t != "" {
+		if p := strings.Index(t, ","); p >= 0 {
+			t = t[:p]
+		}
+		if t != "" {
+			expr, err := parser.ParseExpr("", t)
+			if err != nil {
+				e.warnf(f.Pos(), "invalid cue tag %q for field %s: %v",
+					t, f.Name(), err)
+			} else {
+				constraints = appendConjuncts(constraints, expr)
+			}
+		}
+	}
+
+	if t := tags.Get("validate"); t != "" {
+		var rules []cueast.Expr
+		for _, v := range strings.Split(t, ",") {
+			switch v {
+			case "", "required", "omitempty":
+				continue
+			}
+			expr := e.validateConstraint(f.Type(), v)
+			if expr == nil {
+				e.warnf(f.Pos(), "cannot translate validate tag %q for field %s",
+					v, f.Name())
+				continue
+			}
+			rules = appendConjuncts(rules, expr)
+		}
+
+		// The rules of an omitempty field do not apply to its zero value.
+		zero := e.zeroValue(f.Type())
+		if len(rules) > 0 && zero != nil &&
+			hasFlag(tag, "validate", "omitempty", 0) {
+			x := rules[0]
+			for _, y := range rules[1:] {
+				x = cueast.NewBinExpr(cuetoken.AND, x, y)
+			}
+			rules = []cueast.Expr{cueast.NewBinExpr(cuetoken.OR, zero, x)}
+		}
+		constraints = append(constraints, rules...)
+	}
+
+	// Pointers translate to null | T, in which case the constraints apply
+	// to T. Required pointers may not be null.
+	value := &field.Value
+	if x, ok := field.Value.(*cueast.BinaryExpr); ok && x.Op == cuetoken.OR {
+		if ident, ok := x.X.(*cueast.Ident); ok && ident.Name == "null" {
+			value = &x.Y
+			if e.isRequired(tag) {
+				field.Value = x.Y
+				value = &field.Value
+			}
+		}
+	}
+	for _, c := range constraints {
+		*value = cueast.NewBinExpr(cuetoken.AND, parens(*value), parens(c))
+	}
+}
+
+// appendConjuncts appends the conjuncts of x to a.
+func appendConjuncts(a []cueast.Expr, x cueast.Expr) []cueast.Expr {
+	if b, ok := x.(*cueast.BinaryExpr); ok && b.Op == cuetoken.AND {
+		a = appendConjuncts(a, b.X)
+		return appendConjuncts(a, b.Y)
+	}
+	return append(a, x)
+}
+
+// parens wraps x in parentheses if it is a disjunction, allowing it to be used
+// as an operand of a conjunction.
+func parens(x cueast.Expr) cueast.Expr {
+	if b, ok := x.(*cueast.BinaryExpr); ok && b.Op == cuetoken.OR {
+		return &cueast.ParenExpr{X: x}
+	}
+	return x
+}
+
+// validateConstraint translates a single rule of a validate tag, as used by
+// github.com/go-playground/validator, for a field of type typ. It returns
+// nil if the rule cannot be translated.
+func (e *extractor) validateConstraint(typ types.Type, rule string) cueast.Expr {
+	name, arg := rule, ""
+	if p := strings.Index(rule, "="); p >= 0 {
+		name, arg = rule[:p], rule[p+1:]
+	}
+
+	if p, ok := typ.(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	kind := validateKind(typ)
+
+	switch name {
+	case "email":
+		if kind == "string" {
+			return e.regexp(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+		}
+
+	case "url":
+		if kind == "string" {
+			return e.regexp(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^\s]+$`)
+		}
+
+	case "oneof":
+		var values []cueast.Expr
+		for _, v := range strings.Fields(arg) {
+			switch kind {
+			case "string":
+				values = append(values, cueast.NewString(v))
+			case "number":
+				x := e.number(v)
+				if x == nil {
+					return nil
+				}
+				values = append(values, x)
+			default:
+				return nil
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		x := values[0]
+		for _, y := range values[1:] {
+			x = cueast.NewBinExpr(cuetoken.OR, x, y)
+		}
+		return x
+
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		if kind == "number" {
+			x := e.number(arg)
+			if x == nil {
+				return nil
+			}
+			switch name {
+			case "min", "gte":
+				return &cueast.UnaryExpr{Op: cuetoken.GEQ, X: x}
+			case "max", "lte":
+				return &cueast.UnaryExpr{Op: cuetoken.LEQ, X: x}
+			case "gt":
+				return &cueast.UnaryExpr{Op: cuetoken.GTR, X: x}
+			case "lt":
+				return &cueast.UnaryExpr{Op: cuetoken.LSS, X: x}
+			case "len":
+				return x
+			}
+		}
+
+		// Otherwise the argument is a length: the number of characters of a
+		// string, elements of a list, or entries of a map.
+		var pkg, min, max string
+		switch kind {
+		case "string":
+			pkg, min, max = "strings", "MinRunes", "MaxRunes"
+		case "list":
+			pkg, min, max = "list", "MinItems", "MaxItems"
+		case "map":
+			pkg, min, max = "struct", "MinFields", "MaxFields"
+		default:
+			return nil
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return nil
+		}
+		call := func(fn string, n int) cueast.Expr {
+			e.usedBuiltins[pkg] = true
+			return cueast.NewCall(cueast.NewSel(e.ident(pkg), fn),
+				cueast.NewLit(cuetoken.INT, strconv.Itoa(n)))
+		}
+		switch name {
+		case "min", "gte":
+			return call(min, n)
+		case "max", "lte":
+			return call(max, n)
+		case "gt":
+			return call(min, n+1)
+		case "lt":
+			if n == 0 {
+				return nil
+			}
+			return call(max, n-1)
+		case "len":
+			return cueast.NewBinExpr(cuetoken.AND, call(min, n), call(max, n))
+		}
+	}
+	return nil
+}
+
+// validateKind reports the kind of values of type typ to which the rules of
+// a validate tag apply: "string", "number", "list", or "map". It returns the
+// empty string for other types, including byte slices, which translate to
+// CUE bytes.
+func validateKind(typ types.Type) string {
+	switch x := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case x.Info()&types.IsString != 0:
+			return "string"
+		case x.Info()&types.IsNumeric != 0:
+			return "number"
+		}
+	case *types.Slice:
+		if b, ok := x.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			return ""
+		}
+		return "list"
+	case *types.Array:
+		return "list"
+	case *types.Map:
+		return "map"
+	}
+	return ""
+}
+
+// zeroValue returns the CUE value corresponding to the zero value of a field
+// of type typ, to which validate rules do not apply if the field is marked
+// omitempty. It returns nil if there is no such value. In particular, the zero
+// value of a pointer is nil, which translates to null and is already allowed.
+func (e *extractor) zeroValue(typ types.Type) cueast.Expr {
+	switch validateKind(typ) {
+	case "string":
+		return cueast.NewString("")
+	case "number":
+		return cueast.NewLit(cuetoken.INT, "0")
+	case "list":
+		return cueast.NewList()
+	case "map":
+		return cueast.NewCall(e.ident("close"), cueast.NewStruct())
+	}
+	return nil
+}
+
+// number parses a number argument of a validate tag.
+func (e *extractor) number(s string) cueast.Expr {
+	x, err := parser.ParseExpr("", s)
+	if err != nil {
+		return nil
+	}
+	switch x := x.(type) {
+	case *cueast.BasicLit:
+		if x.Kind == cuetoken.INT || x.Kind == cuetoken.FLOAT {
+			return x
+		}
+	case *cueast.UnaryExpr:
+		if b, ok := x.X.(*cueast.BasicLit); ok && x.Op == cuetoken.SUB &&
+			(b.Kind == cuetoken.INT || b.Kind == cuetoken.FLOAT) {
+			return x
+		}
+	}
+	return nil
+}
+
+func (e *extractor) regexp(re string) cueast.Expr {
+	return &cueast.UnaryExpr{
+		Op: cuetoken.MAT,
+		X:  cueast.NewLit(cuetoken.STRING, "#\""+re+"\"#"),
+	}
+}
+
+// warnf reports a problem with the Go source at the given position. Warnings
+// do not cause the command to fail.
+func (e *extractor) warnf(pos token.Pos, format string, args ...interface{}) {
+	p := e.pkg.Fset.Position(pos)
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, p.Filename); err == nil {
+			p.Filename = rel
+		}
+	}
+	fmt.Fprintf(e.cmd.OutOrStderr(), "%s: %s\n", p, fmt.Sprintf(format, args...))
+}
+
 func hasFlag(tag, key, flag string, offset int) bool {
 	if t := reflect.StructTag(tag).Get(key); t != "" {
 		split := strings.Split(t, ",")
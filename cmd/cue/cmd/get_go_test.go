@@ -15,12 +15,15 @@
 package cmd
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal/copy"
 )
 
@@ -40,6 +43,26 @@ func TestGetGo(t *testing.T) {
 	// We don't use runCommand here, as we are interested in generated packages.
 	cmd := newGoCmd(newRootCmd())
 	cmd.SetArgs([]string{"./testdata/code/go/..."})
+	stderr := &bytes.Buffer{}
+	cmd.SetOutput(stderr)
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rules of validate tags that cannot be translated are reported.
+	for _, want := range []string{
+		`pkg1/tags.go:41:2: cannot translate validate tag "uuid" for field ID`,
+		`pkg1/tags.go:42:2: cannot translate validate tag "dive" for field Elements`,
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("missing warning %q in output:\n%s", want, stderr)
+		}
+	}
+
+	// Regenerate pkg3, which tests enum detection.
+	cmd = newGoCmd(newRootCmd())
+	cmd.SetArgs([]string{"--enums=auto", "./testdata/code/go/pkg3"})
 	err = cmd.Execute()
 	if err != nil {
 		t.Fatal(err)
@@ -60,6 +83,40 @@ func TestGetGo(t *testing.T) {
 		return nil
 	})
 
+	// The generated enums must compile and only allow their constants.
+	b, err := ioutil.ReadFile(filepath.Join(root,
+		"cuelang.org/go/cmd/cue/cmd/testdata/code/go/pkg3/enum_go_gen.cue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	inst, err := r.Compile("enum_go_gen.cue", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.Value().Validate(); err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	for _, tc := range []struct {
+		typ   string
+		value interface{}
+		ok    bool
+	}{
+		{"Switch", 1, true},
+		{"Switch", 2, false},
+		{"Weekday", 0, true},
+		{"Weekday", 7, false},
+		{"Flags", 3, true},
+		{"Mode", "b", true},
+		{"Size", 3, true},
+	} {
+		v := inst.LookupDef(tc.typ).Fill(tc.value)
+		if err := v.Validate(cue.Concrete(true)); (err == nil) != tc.ok {
+			t.Errorf("%s & %v: got error %v; want ok %v",
+				tc.typ, tc.value, err, tc.ok)
+		}
+	}
+
 	const dst = "testdata/pkg"
 
 	if *update {
@@ -87,6 +87,17 @@ specifies a schema defined in "definitions". In all other cases,
 the -d flag is a CUE expression that is evaluated within the
 package.
 
+Imported packages can be loaded from archives by setting the
+CUECACHE environment variable to a directory in which to store
+these archives, or to "on" to use the default user cache
+directory. An archive is used only if
+the sources of the package and its imports and the CUE version
+did not change. Sources are only read again to detect changes if
+their size or modification time changed. The archive holds the
+merged declarations of a package in a single file, which is parsed
+and compiled instead of the sources. Positions in errors for
+archived packages refer to the archive instead of the sources.
+
 Examples (also see also "flags" and "filetypes" help topics):
 
 # Show the definition of each package named foo for each
@@ -178,18 +189,30 @@ var filetypeHelp = &cobra.Command{
     cue         .cue            CUE source files.
     json        .json           JSON files.
     yaml        .yaml/.yml      YAML files.
+    hcl         .hcl            HCL files.
+    xml         .xml            XML files.
+    csv         .csv            Comma-separated values.
+    tsv         .tsv            Tab-separated values.
+    env         .env            Dotenv files.
+    properties  .properties     Java properties files.
     jsonl       .jsonl/.ldjson  Line-separated JSON values.
+    jsonc       .jsonc/.json5   JSON with comments and trailing
+                                commas (input only).
     jsonschema                  JSON Schema.
     openapi                     OpenAPI schema.
     proto        .proto         Protocol Buffer definitions.
     go          .go             Go source files.
+    ts          .ts             TypeScript declarations (output only).
     text        .txt            Raw text file; the evaluated
                                 value must be of type string.
 
 OpenAPI, JSON Schema and Protocol Buffer definitions are
-always interpreted as schema. YAML and JSON are always
-interpreted as data. CUE and Go are interpreted as schema by
-default, but may be selected to operate in data mode.
+always interpreted as schema. YAML, JSON, JSONC, HCL, XML,
+CSV, TSV, dotenv and properties files are always interpreted
+as data. Each row of a CSV or TSV file is a separate value,
+with fields named after the header row. CUE and Go are
+interpreted as schema by default, but may be selected to
+operate in data mode.
 
 The cue tool will infer a file's type from its extension by
 default. The user my override this behavior by using qualifiers.
@@ -227,6 +250,13 @@ defaults as requested.
                 not require any evaluation.
     graph       Like data, but allow references.
     schema      Export data and definitions.
+    docs        Include doc comments in YAML output.
+    refs        Import YAML anchors and aliases as references.
+    1.2         Interpret YAML input using the YAML 1.2 core
+                schema, so that values like yes, no, on and off
+                are strings instead of booleans.
+    canonical   Output canonical JSON as defined by RFC 8785,
+                without a trailing newline.
 
 Many commands also support the --out and --outfile/-o flags.
 The --out flag specifies the output type using a qualifier
@@ -245,6 +275,15 @@ $ cue def -o openapi+yaml:foo.openapi
 # Print the data for the current package as YAML.
 $ cue export --out=yaml
 
+# Print the data as YAML, including doc comments.
+$ cue export --out=yaml+docs
+
+# Validate YAML 1.2 data against a schema.
+$ cue vet schema.cue yaml+1.2: data.yaml
+
+# Print the data as canonical JSON and compute its hash.
+$ cue export --out=json+canonical | sha256sum
+
 # Print the string value of the "name" field as a string.
 $ cue export -e name --out=text
 
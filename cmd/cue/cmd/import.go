@@ -52,8 +52,14 @@ more info). This behavior can be overridden by specifying one of
 the following modes:
 
    Mode       Extensions
-   json       Look for JSON files (.json, .jsonl, .ldjson).
+   json       Look for JSON files (.json, .jsonl, .ldjson, .jsonc,
+              .json5).
    yaml       Look for YAML files (.yaml .yml).
+   hcl        Look for HCL files (.hcl).
+   xml        Look for XML files (.xml).
+   csv        Look for CSV and TSV files (.csv, .tsv).
+   env        Look for dotenv files (.env).
+   properties Look for Java properties files (.properties).
    text       Look for text files (.txt).
    jsonschema Interpret JSON, YAML or CUE files as JSON Schema.
    openapi    Interpret JSON, YAML or CUE files as OpenAPI.
@@ -116,6 +122,13 @@ matched package directories.
 The -I flag is used to specify import paths for proto mode.
 The module root is implicitly added as an import if it exists.
 
+YAML anchors and aliases are expanded in place by default. With
+the refs tag, as in "yaml+refs:", each referenced anchor is
+converted to a CUE alias and each YAML alias to a reference to it.
+Merge keys are converted to embedded references, unless merged
+keys overlap, in which case they are expanded. The result
+evaluates to the same value as the expanded form.
+
 Examples:
 
   # Convert individual files:
@@ -124,6 +137,9 @@ Examples:
   # Convert all json files in the indicated directories:
   $ cue import json ./...
 
+  # Convert a YAML file, keeping anchors and aliases as references:
+  $ cue import yaml+refs: foo.yaml
+
 The "flags" help topic describes how to assign values to a
 specific path within a CUE namespace. Some examples of that
 
@@ -270,9 +286,19 @@ func runImport(cmd *Command, args []string) (err error) {
 		case "proto":
 			c.fileFilter = `\.proto$`
 		case "json":
-			c.fileFilter = `\.(json|jsonl|ldjson)$`
+			c.fileFilter = `\.(json|jsonl|ldjson|jsonc|json5)$`
 		case "yaml":
 			c.fileFilter = `\.(yaml|yml)$`
+		case "hcl":
+			c.fileFilter = `\.hcl$`
+		case "xml":
+			c.fileFilter = `\.xml$`
+		case "csv":
+			c.fileFilter = `\.(csv|tsv)$`
+		case "env":
+			c.fileFilter = `\.env$`
+		case "properties":
+			c.fileFilter = `\.properties$`
 		case "text":
 			c.fileFilter = `\.txt$`
 		case "auto", "openapi", "jsonschema":
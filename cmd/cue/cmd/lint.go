@@ -0,0 +1,161 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/tools/lint"
+)
+
+func newLintCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [packages]",
+		Short: "report suspicious constructs",
+		Long: `lint reports suspicious constructs in CUE packages
+
+Lint runs a set of rules on each of the given packages and reports the
+problems found, one per line, followed by the name of the rule that
+reported it. The command fails if any problems are reported.
+
+The following rules are available:
+
+  bottom                 fields, including definitions, that always evaluate
+                         to an error
+  redundant-constraint   constraints implied by another constraint of the
+                         same conjunction, such as >=0 in int & >=0 & >=1
+  shadow                 aliases and comprehension variables that shadow an
+                         identifier of an enclosing scope
+  unused-alias           aliases that are not used
+  unused-definition      unexported top-level definitions that are not used
+  unused-import          imports that are not used
+
+The bottom rule relies on evaluation. It is skipped for packages that
+cannot be built, for instance because of an unused import, and reports that
+it was skipped.
+
+
+Configuration
+
+All rules are enabled by default. Rules can be disabled, or enabled, with
+a CUE configuration file, which is read from cue.mod/lint.cue in the module
+root or from the file given with --config:
+
+	rules: {
+		shadow:              false
+		"unused-definition": false
+	}
+
+
+Fixes
+
+With --fix, lint modifies the files in place to fix the problems for which
+this can be done safely and only reports the remaining problems. Fixes are
+available for the redundant-constraint, unused-alias, and unused-import
+rules.
+`,
+		RunE: mkRunE(c, runLint),
+	}
+
+	cmd.Flags().Bool(string(flagFix), false,
+		"fix problems in place where possible")
+	cmd.Flags().String(string(flagConfig), "",
+		"lint configuration file")
+
+	return cmd
+}
+
+const (
+	flagFix    flagName = "fix"
+	flagConfig flagName = "config"
+)
+
+func runLint(cmd *Command, args []string) error {
+	binst := loadFromArgs(cmd, args, nil)
+	if binst == nil {
+		return nil
+	}
+	for _, inst := range binst {
+		exitOnErr(cmd, inst.Err, true)
+	}
+
+	cfg, err := lintConfig(cmd, binst[0].Root)
+	if err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	for i, inst := range cue.Build(binst) {
+		if inst.Err != nil {
+			inst = nil
+		}
+		files := binst[i].Files
+		diags := lint.Run(files, inst, cfg)
+
+		if flagFix.Bool(cmd) {
+			opts := []format.Option{}
+			if flagSimplify.Bool(cmd) {
+				opts = append(opts, format.Simplify())
+			}
+			for _, f := range lint.ApplyFixes(diags) {
+				b, err := format.Node(f, opts...)
+				if err != nil {
+					return fmt.Errorf("error formatting file: %v", err)
+				}
+				if err := ioutil.WriteFile(f.Filename, b, 0644); err != nil {
+					return err
+				}
+			}
+		}
+
+		w := cmd.Stderr()
+		for _, d := range diags {
+			if d.Fix != nil && flagFix.Bool(cmd) {
+				continue
+			}
+			pos := d.Pos.Position()
+			if cwd != "" {
+				if rel, err := filepath.Rel(cwd, pos.Filename); err == nil {
+					pos.Filename = rel
+				}
+			}
+			fmt.Fprintf(w, "%v: %s (%s)\n", pos, d.Message, d.Rule)
+		}
+	}
+	return nil
+}
+
+// lintConfig reads the configuration given with --config or, if not given,
+// from cue.mod/lint.cue in the module root, if it exists.
+func lintConfig(cmd *Command, root string) (*lint.Config, error) {
+	file := flagConfig.String(cmd)
+	if file == "" {
+		if root == "" {
+			return nil, nil
+		}
+		file = filepath.Join(root, "cue.mod", "lint.cue")
+		if _, err := os.Stat(file); err != nil {
+			return nil, nil
+		}
+	}
+	return lint.ParseConfig(file, nil)
+}
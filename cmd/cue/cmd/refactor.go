@@ -0,0 +1,129 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/tools/refactor"
+)
+
+func newRefactorCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refactor <cmd> [arguments]",
+		Short: "automated refactorings of CUE packages",
+		Long: `Refactor applies automated changes to the packages of a module.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "refactor must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "refactor must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help refactor' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+	cmd.AddCommand(newRenameCmd(c))
+	return cmd
+}
+
+func newRenameCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <package>.<identifier> <new identifier>",
+		Short: "rename a top-level identifier of a package",
+		Long: `Rename renames a top-level field or definition of a package and updates
+all references to it.
+
+The package is given as an import path or a directory, followed by a dot
+and the identifier to rename. References are updated within the package
+itself and in all packages of the module that import it. The modified files
+are written back in place.
+
+Rename refuses to make any changes if the new name is already declared in
+the package, if a reference would be shadowed by another field or alias with
+the new name, or if the identifier is used by other packages and the new
+name would make it invisible to them.
+
+Examples:
+
+	$ cue refactor rename example.com/foo.Config Settings
+	$ cue refactor rename ./foo.Config Settings
+`,
+		RunE: mkRunE(c, runRename),
+	}
+	return cmd
+}
+
+func runRename(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("rename requires two arguments: <package>.<identifier> <new identifier>")
+	}
+	i := strings.LastIndexByte(args[0], '.')
+	if i < 0 || !ast.IsValidIdent(args[0][i+1:]) {
+		return fmt.Errorf("invalid argument %q: must be of the form <package>.<identifier>", args[0])
+	}
+	pkgArg, name, newName := args[0][:i], args[0][i+1:], args[1]
+
+	cfg := &load.Config{Tools: true, Tests: true}
+	binst := loadFromArgs(cmd, []string{pkgArg}, cfg)
+	if len(binst) != 1 {
+		return fmt.Errorf("%q must refer to a single package", pkgArg)
+	}
+	pkg := binst[0]
+	exitOnErr(cmd, pkg.Err, true)
+
+	// Load all packages of the module to find references.
+	insts := []*build.Instance{pkg}
+	if pkg.Root != "" {
+		cfg := &load.Config{Dir: pkg.Root, Tools: true, Tests: true}
+		insts = loadFromArgs(cmd, []string{"./..."}, cfg)
+	}
+	for _, inst := range insts {
+		exitOnErr(cmd, inst.Err, true)
+		for _, file := range append(inst.ToolCUEFiles, inst.TestCUEFiles...) {
+			exitOnErr(cmd, inst.AddFile(file, nil), true)
+		}
+	}
+
+	files, err := refactor.Rename(insts, pkg.ImportPath, name, newName)
+	exitOnErr(cmd, err, true)
+
+	opts := []format.Option{}
+	if flagSimplify.Bool(cmd) {
+		opts = append(opts, format.Simplify())
+	}
+	for _, f := range files {
+		b, err := format.Node(f, opts...)
+		if err != nil {
+			return fmt.Errorf("error formatting file: %v", err)
+		}
+		if err := ioutil.WriteFile(f.Filename, b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
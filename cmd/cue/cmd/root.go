@@ -44,6 +44,9 @@ type runFunction func(cmd *Command, args []string) error
 func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		c.Command = cmd
+		if _, err := errorsFormat(c); err != nil {
+			return err
+		}
 		err := f(c, args)
 		if err != nil {
 			exitOnErr(c, err, true)
@@ -95,7 +98,9 @@ For more information on writing CUE configuration files see cuelang.org.`,
 		newFmtCmd(c),
 		newGetCmd(c),
 		newImportCmd(c),
+		newLintCmd(c),
 		newModCmd(c),
+		newRefactorCmd(c),
 		newReplCmd(c),
 		newTrimCmd(c),
 		newVersionCmd(c),
@@ -151,6 +156,10 @@ type Command struct {
 	cmd *cobra.Command
 
 	hasErr bool
+
+	// errs holds the errors to be reported at the end of the command if the
+	// --errors flag selects a structured format.
+	errs []diagnostic
 }
 
 type errWriter Command
@@ -198,6 +207,7 @@ func (c *Command) Run(ctx context.Context) (err error) {
 	// - help
 	// For the latter two, we need to use the default loading.
 	defer recoverError(&err)
+	defer c.printErrors()
 
 	if err := c.root.Execute(); err != nil {
 		return err
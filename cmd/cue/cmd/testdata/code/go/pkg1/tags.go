@@ -0,0 +1,45 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg1
+
+// Sum uses cue tags as interpreted by cuego.
+type Sum struct {
+	A int  `cue:"C-B" json:",omitempty"`
+	B int  `cue:"C-A" json:",omitempty"`
+	C int  `cue:"A+B & >=5" json:",omitempty"`
+	D *int `cue:"<10"`
+	E int  `cue:",opt"`
+
+	Kind string `cue:"\"a\" | \"b\""`
+}
+
+// Validated uses validate tags as interpreted by
+// github.com/go-playground/validator.
+type Validated struct {
+	Name     string            `json:"name" validate:"required,min=1,max=64"`
+	Email    string            `json:"email,omitempty" validate:"omitempty,email"`
+	URL      *string           `json:"url" validate:"required,url"`
+	Port     int               `json:"port" validate:"gte=1,lte=65535"`
+	Ratio    float64           `json:"ratio" validate:"gt=0,lt=1.5"`
+	Level    string            `json:"level" validate:"oneof=debug info warn"`
+	Mode     int               `json:"mode" validate:"oneof=1 2 4"`
+	Tags     []string          `json:"tags" validate:"max=3"`
+	Code     string            `json:"code" validate:"len=4"`
+	Labels   map[string]string `json:"labels" validate:"min=1"`
+	ID       string            `json:"id" validate:"uuid"`
+	Elements []string          `json:"elements" validate:"dive,required"`
+	Nick     string            `json:"nick,omitempty" validate:"omitempty,min=3"`
+	Aliases  []string          `json:"aliases,omitempty" validate:"omitempty,min=1,max=4"`
+}
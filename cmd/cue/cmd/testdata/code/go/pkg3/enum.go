@@ -0,0 +1,54 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkg3 declares enum-like types.
+package pkg3
+
+// Switch is an enum with constants declared using iota.
+type Switch int
+
+const (
+	Off Switch = iota
+	On
+)
+
+// Weekday is an enum with a String method generated by stringer.
+type Weekday int
+
+const Sunday Weekday = 0
+
+const Monday Weekday = 1
+
+// Flags is a bit set and not an enum.
+//
+//cue:noenum
+type Flags int
+
+const (
+	FlagA Flags = 1 << iota
+	FlagB
+)
+
+// Mode has unexported constants.
+type Mode string
+
+const (
+	ModeA Mode = "a"
+	modeB Mode = "b"
+)
+
+// Size has only one constant.
+type Size int
+
+const Large Size = 2
@@ -35,6 +35,7 @@ currently supported:
 
   Format       Extensions
 	JSON       .json .jsonl .ndjson
+	JSONC      .jsonc .json5
 	YAML       .yaml .yml
 	TEXT       .txt  (validate a single string value)
 
@@ -70,6 +71,7 @@ func newVetCmd(c *Command) *cobra.Command {
 	}
 
 	addOrphanFlags(cmd.Flags())
+	addErrorsFlag(cmd.Flags())
 
 	cmd.Flags().BoolP(string(flagConcrete), "c", false,
 		"require the evaluation to be concrete")
@@ -125,6 +127,10 @@ func doVet(cmd *Command, args []string) error {
 		err := inst.Value().Validate(append(opt, cue.Concrete(concrete))...)
 		if err != nil && !hasFlag {
 			err = inst.Value().Validate(append(opt, cue.Concrete(false))...)
+			if format, _ := errorsFormat(cmd); format != errorsText {
+				// Do not interfere with machine-readable output.
+				shown = true
+			}
 			if !shown && err == nil {
 				shown = true
 				p := message.NewPrinter(getLang())
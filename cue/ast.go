@@ -22,6 +22,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/literal"
@@ -78,6 +79,7 @@ type astState struct {
 	ctx *context
 	*index
 	inst *build.Instance
+	file *ast.File // file being processed, if any
 
 	litParser   *litParser
 	resolveRoot *structLit
@@ -128,13 +130,54 @@ func newVisitorCtx(ctx *context, inst *build.Instance, obj, resolveRoot *structL
 }
 
 func (v *astVisitor) errf(n ast.Node, format string, args ...interface{}) evaluated {
+	return v.suggestErrf(n, nil, format, args...)
+}
+
+// suggestErrf is like errf, but also reports the given suggestions for
+// correcting the error.
+func (v *astVisitor) suggestErrf(n ast.Node, suggestions []string, format string, args ...interface{}) *bottom {
 	v.astState.errors = errors.Append(v.astState.errors, &nodeError{
-		path:    v.appendPath(nil),
-		n:       n,
-		Message: errors.NewMessage(format, args),
+		path:        v.appendPath(nil),
+		n:           n,
+		Message:     errors.NewMessage(format, args),
+		suggestions: suggestions,
 	})
 	arguments := append([]interface{}{format}, args...)
-	return v.mkErr(newNode(n), arguments...)
+	err := v.mkErr(newNode(n), arguments...)
+	err.suggestions = suggestions
+	return err
+}
+
+// referenceNotFound reports an error for an identifier that could not be
+// resolved, suggesting similarly named identifiers that are in scope.
+func (v *astVisitor) referenceNotFound(n *ast.Ident, name string) evaluated {
+	var candidates []string
+	if r := v.resolveRoot; r != nil {
+		for _, a := range r.arcs {
+			candidates = append(candidates, v.labelStr(a.feature))
+		}
+	}
+	candidates = append(candidates, predeclared...)
+	for r := range predefinedRanges {
+		candidates = append(candidates, r)
+	}
+	var root ast.Node = n
+	if v.file != nil {
+		root = v.file
+	}
+	suggestions := astutil.Suggestions(root, n, candidates...)
+	if len(suggestions) == 0 {
+		return v.errf(n, "reference %q not found", name)
+	}
+	return v.suggestErrf(n, suggestions,
+		"reference %q not found; did you mean %s?", name, quoteList(suggestions))
+}
+
+// predeclared lists the predeclared identifiers, other than the predefined
+// ranges.
+var predeclared = []string{
+	"string", "bytes", "bool", "int", "float", "number",
+	"len", "close", "and", "or",
 }
 
 func (v *astVisitor) appendPath(a []string) []string {
@@ -199,6 +242,7 @@ func (v *astVisitor) ident(n *ast.Ident) string {
 func (v *astVisitor) walk(astNode ast.Node) (ret value) {
 	switch n := astNode.(type) {
 	case *ast.File:
+		v.file = n
 		obj := v.object
 		v1 := &astVisitor{
 			astState: v.astState,
@@ -546,7 +590,7 @@ func (v *astVisitor) walk(astNode ast.Node) (ret value) {
 				return r
 			}
 
-			ret = v.errf(n, "reference %q not found", name)
+			ret = v.referenceNotFound(n, name)
 			break
 		}
 
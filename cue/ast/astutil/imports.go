@@ -0,0 +1,325 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astutil
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// ImportName reports the name by which the package imported with spec is
+// referred to, assuming the package name matches the last element of the
+// import path or the package qualifier of the path, if any.
+func ImportName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	p, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	if i := strings.LastIndexByte(p, ':'); i >= 0 {
+		return p[i+1:]
+	}
+	return path.Base(p)
+}
+
+// IsStdPath reports whether importPath refers to a package of the standard
+// library. Paths of packages outside the standard library start with a
+// domain name and thus have a dot in the first path element.
+func IsStdPath(importPath string) bool {
+	elem := importPath
+	if i := strings.IndexByte(elem, '/'); i >= 0 {
+		elem = elem[:i]
+	}
+	return !strings.Contains(elem, ".")
+}
+
+// UsesImport reports whether the package imported with spec is referenced
+// in f. Identifiers that were not resolved are assumed to refer to the
+// import with the same name.
+func UsesImport(f *ast.File, spec *ast.ImportSpec) bool {
+	name := ImportName(spec)
+	used := false
+	WalkRefs(f, func(x *ast.Ident) {
+		switch {
+		case x.Node == spec:
+			used = true
+		case x.Node == nil && x.Name == name:
+			used = true
+		}
+	})
+	return used
+}
+
+// AddImport adds an import of the package with the given import path to f
+// and returns its spec. If f already imports the package without a name,
+// it returns the existing spec. The import is added to the first import
+// declaration of f, or to a new one if f has none.
+func AddImport(f *ast.File, importPath string) *ast.ImportSpec {
+	quoted := strconv.Quote(importPath)
+	for _, spec := range f.Imports {
+		if spec.Name == nil && spec.Path.Value == quoted {
+			return spec
+		}
+	}
+
+	spec := &ast.ImportSpec{Path: ast.NewLit(token.STRING, quoted)}
+	f.Imports = append(f.Imports, spec)
+
+	i := 0
+	for ; i < len(f.Decls); i++ {
+		switch x := f.Decls[i].(type) {
+		case *ast.Package, *ast.CommentGroup, *ast.Attribute:
+			continue
+
+		case *ast.ImportDecl:
+			if len(x.Specs) > 0 {
+				ast.SetRelPos(spec, token.Newline)
+			}
+			x.Specs = append(x.Specs, spec)
+			return spec
+		}
+		break
+	}
+
+	decl := &ast.ImportDecl{Specs: []*ast.ImportSpec{spec}}
+	ast.SetRelPos(decl, token.NewSection)
+	if i < len(f.Decls) {
+		ast.SetRelPos(f.Decls[i], token.NewSection)
+	}
+	f.Decls = append(f.Decls[:i], append([]ast.Decl{decl}, f.Decls[i:]...)...)
+	return spec
+}
+
+// DeleteImport removes spec from f. The import declaration containing spec
+// is removed altogether if it becomes empty. Comments attached to spec are
+// removed along with it.
+func DeleteImport(f *ast.File, spec *ast.ImportSpec) {
+	for i, s := range f.Imports {
+		if s == spec {
+			f.Imports = append(f.Imports[:i], f.Imports[i+1:]...)
+			break
+		}
+	}
+	k := 0
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.ImportDecl); ok {
+			n := len(x.Specs)
+			specs := x.Specs[:0]
+			for _, s := range x.Specs {
+				if s != spec {
+					specs = append(specs, s)
+				}
+			}
+			x.Specs = specs
+			if len(specs) == 0 && n > 0 {
+				continue
+			}
+			if len(specs) > 0 && len(specs) < n {
+				ast.SetRelPos(specs[0], token.NoRelPos)
+			}
+		}
+		f.Decls[k] = d
+		k++
+	}
+	f.Decls = f.Decls[:k]
+}
+
+// SortImports sorts the import specs within each import declaration of f by
+// import path. Imports of the standard library are grouped before all other
+// imports, with the groups separated by a blank line. Duplicate imports are
+// removed.
+func SortImports(f *ast.File) {
+	for _, d := range f.Decls {
+		x, ok := d.(*ast.ImportDecl)
+		if !ok || len(x.Specs) == 0 {
+			continue
+		}
+		specs := x.Specs
+		sort.SliceStable(specs, func(i, j int) bool {
+			a, b := importPath(specs[i]), importPath(specs[j])
+			if sa, sb := IsStdPath(a), IsStdPath(b); sa != sb {
+				return sa
+			}
+			if a != b {
+				return a < b
+			}
+			return specName(specs[i]) < specName(specs[j])
+		})
+
+		k := 0
+		for i, s := range specs {
+			if i > 0 && isDuplicate(specs[k-1], s) {
+				for j, t := range f.Imports {
+					if t == s {
+						f.Imports = append(f.Imports[:j], f.Imports[j+1:]...)
+						break
+					}
+				}
+				continue
+			}
+			specs[k] = s
+			k++
+		}
+		x.Specs = specs[:k]
+
+		for i, s := range x.Specs {
+			switch {
+			case i == 0:
+				setSpecRelPos(s, token.NoRelPos)
+			case IsStdPath(importPath(x.Specs[i-1])) != IsStdPath(importPath(s)):
+				setSpecRelPos(s, token.NewSection)
+			default:
+				setSpecRelPos(s, token.Newline)
+			}
+		}
+	}
+}
+
+// FixImports removes unused imports from f, adds missing imports, and sorts
+// the imports with SortImports.
+//
+// An import is added for each identifier that is not resolved, not the name
+// of an existing import, and used as the operand of a selector, as in
+// strings.ToUpper, if lookup returns a non-empty import path for its name.
+func FixImports(f *ast.File, lookup func(name string) (importPath string)) {
+	for _, spec := range append([]*ast.ImportSpec(nil), f.Imports...) {
+		if !UsesImport(f, spec) {
+			DeleteImport(f, spec)
+		}
+	}
+
+	if lookup != nil {
+		imported := map[string]bool{}
+		for _, spec := range f.Imports {
+			imported[ImportName(spec)] = true
+		}
+		added := map[string]*ast.ImportSpec{}
+		ast.Walk(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok || x.Node != nil || imported[x.Name] {
+				return true
+			}
+			spec, ok := added[x.Name]
+			if !ok {
+				if p := lookup(x.Name); p != "" {
+					spec = AddImport(f, p)
+					if ImportName(spec) != x.Name {
+						spec.Name = ast.NewIdent(x.Name)
+					}
+				}
+				added[x.Name] = spec
+			}
+			if spec != nil {
+				x.Node = spec
+			}
+			return true
+		}, nil)
+	}
+
+	SortImports(f)
+}
+
+// setSpecRelPos sets the relative position of spec, or of its doc comment
+// if it has one.
+func setSpecRelPos(spec *ast.ImportSpec, rel token.RelPos) {
+	for _, cg := range spec.Comments() {
+		if cg.Position == 0 {
+			ast.SetRelPos(cg, rel)
+			ast.SetRelPos(spec, token.Newline)
+			return
+		}
+	}
+	ast.SetRelPos(spec, rel)
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	p, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return spec.Path.Value
+	}
+	return p
+}
+
+func specName(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return ""
+	}
+	return spec.Name.Name
+}
+
+func isDuplicate(a, b *ast.ImportSpec) bool {
+	return importPath(a) == importPath(b) && specName(a) == specName(b) &&
+		b.Comments() == nil
+}
+
+// WalkRefs calls fn for each identifier in n that is used as a reference,
+// as opposed to identifiers used as labels, selectors, or to declare names.
+func WalkRefs(n ast.Node, fn func(x *ast.Ident)) {
+	ast.Walk(n, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			fn(x)
+
+		case *ast.Field:
+			label := x.Label
+			if a, ok := label.(*ast.Alias); ok {
+				label, _ = a.Expr.(ast.Label)
+			}
+			switch l := label.(type) {
+			case *ast.Interpolation:
+				WalkRefs(l, fn)
+			case *ast.ListLit:
+				for _, e := range l.Elts {
+					if a, ok := e.(*ast.Alias); ok {
+						e = a.Expr
+					}
+					WalkRefs(e, fn)
+				}
+			}
+			if x.Value != nil {
+				WalkRefs(x.Value, fn)
+			}
+			return false
+
+		case *ast.Alias:
+			WalkRefs(x.Expr, fn)
+			return false
+
+		case *ast.SelectorExpr:
+			WalkRefs(x.X, fn)
+			return false
+
+		case *ast.ForClause:
+			WalkRefs(x.Source, fn)
+			return false
+
+		case *ast.Package, *ast.ImportDecl, *ast.TemplateLabel,
+			*ast.Attribute, *ast.CommentGroup:
+			return false
+		}
+		return true
+	}, nil)
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestFixImports(t *testing.T) {
+	packages := map[string]string{
+		"strings": "strings",
+		"list":    "list",
+		"yaml":    "encoding/yaml",
+		"foo":     "example.com/foo",
+	}
+	lookup := func(name string) string { return packages[name] }
+
+	testCases := []struct {
+		name string
+		in   string
+		out  string
+	}{{
+		name: "unused",
+		in: `package a
+
+import (
+	"strings"
+	"list" // for Sum
+)
+
+a: strings.ToUpper("a")
+`,
+		out: `package a
+
+import (
+	"strings"
+)
+
+a: strings.ToUpper("a")
+`,
+	}, {
+		name: "remove declaration",
+		in: `package a
+
+import "strings"
+
+a: 1
+`,
+		out: `package a
+
+a: 1
+`,
+	}, {
+		name: "missing",
+		in: `package a
+
+a: strings.ToUpper("a")
+b: yaml.Marshal(a)
+c: foo.Bar
+`,
+		out: `package a
+
+import (
+	"encoding/yaml"
+	"strings"
+
+	"example.com/foo"
+)
+
+a: strings.ToUpper("a")
+b: yaml.Marshal(a)
+c: foo.Bar
+`,
+	}, {
+		name: "missing with existing",
+		in: `package a
+
+import "list"
+
+a: strings.ToUpper("a")
+b: list.Sum([1])
+`,
+		out: `package a
+
+import (
+	"list"
+	"strings"
+)
+
+a: strings.ToUpper("a")
+b: list.Sum([1])
+`,
+	}, {
+		name: "not resolved",
+		in: `package a
+
+strings: {ToUpper: 1}
+a: strings.ToUpper
+b: {
+	list: {x: 1}
+	c: list.x
+}
+d: unknown.x
+`,
+		out: `package a
+
+strings: {ToUpper: 1}
+a: strings.ToUpper
+b: {
+	list: {x: 1}
+	c: list.x
+}
+d: unknown.x
+`,
+	}, {
+		name: "sort",
+		in: `// Doc comment.
+package a
+
+import (
+	// Foo does things.
+	"example.com/foo"
+	"strings"
+	"list" // for Sum
+	"strings"
+	y "encoding/yaml"
+)
+
+a: strings.ToUpper("a")
+b: list.Sum([1])
+c: foo.Bar
+d: y.Marshal(a)
+`,
+		out: `// Doc comment.
+package a
+
+import (
+	y "encoding/yaml"
+	"list" // for Sum
+	"strings"
+
+	// Foo does things.
+	"example.com/foo"
+)
+
+a: strings.ToUpper("a")
+b: list.Sum([1])
+c: foo.Bar
+d: y.Marshal(a)
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(tc.name, tc.in, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			astutil.FixImports(f, lookup)
+
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.out {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestAddImport(t *testing.T) {
+	f, err := parser.ParseFile("test", `package a
+
+a: 1
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := astutil.AddImport(f, "strings")
+	if got := astutil.AddImport(f, "strings"); got != spec {
+		t.Errorf("import added twice")
+	}
+	astutil.AddImport(f, "list")
+	if len(f.Imports) != 2 {
+		t.Errorf("got %d imports; want 2", len(f.Imports))
+	}
+
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package a
+
+import (
+	"strings"
+	"list"
+)
+
+a: 1
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	astutil.DeleteImport(f, spec)
+	b, _ = format.Node(f)
+	if !strings.Contains(string(b), `import "list"`) && !strings.Contains(string(b), "\t\"list\"") {
+		t.Errorf("unexpected output:\n%s", b)
+	}
+	if astutil.UsesImport(f, f.Imports[0]) {
+		t.Errorf("import list reported as used")
+	}
+}
+
+func TestWalkRefs(t *testing.T) {
+	f, err := parser.ParseFile("test", `package a
+
+import "strings"
+
+X=a: strings.ToUpper(b)
+"\(c)": d.e
+[f]: g
+for h in i { j: h }
+k: X
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refs []string
+	astutil.WalkRefs(f, func(x *ast.Ident) {
+		refs = append(refs, x.Name)
+	})
+	got := strings.Join(refs, " ")
+	const want = "strings b c d f g i h X"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
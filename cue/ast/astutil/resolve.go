@@ -52,6 +52,10 @@ type scope struct {
 	inField bool
 
 	errFn func(p token.Pos, msg string, args ...interface{})
+
+	// found, if not nil, is called for each identifier instead of resolving
+	// it.
+	found func(s *scope, x *ast.Ident)
 }
 
 func newScope(f *ast.File, outer *scope, node ast.Node, decls []ast.Decl) *scope {
@@ -62,6 +66,7 @@ func newScope(f *ast.File, outer *scope, node ast.Node, decls []ast.Decl) *scope
 		node:  node,
 		index: make(map[string]ast.Node, n),
 		errFn: outer.errFn,
+		found: outer.found,
 	}
 	for _, d := range decls {
 		switch x := d.(type) {
@@ -285,6 +290,10 @@ func (s *scope) Before(n ast.Node) (w visitor) {
 		return nil
 
 	case *ast.Ident:
+		if s.found != nil {
+			s.found(s, x)
+			return nil
+		}
 		name, ok, _ := ast.LabelName(x)
 		if !ok {
 			// TODO: generate error
@@ -0,0 +1,151 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astutil
+
+import (
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// maxSuggestions is the maximum number of suggestions returned by Suggest.
+const maxSuggestions = 3
+
+// Suggest returns the names in candidates that are most similar to name,
+// closest first. It is used to suggest corrections for misspelled
+// identifiers and field names. Names that are too different from name to be
+// a plausible misspelling are not returned.
+func Suggest(name string, candidates []string) []string {
+	if name == "" {
+		return nil
+	}
+	type suggestion struct {
+		name string
+		dist int
+	}
+	var a []suggestion
+	seen := map[string]bool{name: true}
+	lower := strings.ToLower(name)
+	// Allow roughly one edit per three characters, and none for names of one
+	// or two characters other than changes in case.
+	max := len(name) / 3
+	if len(name) >= 3 && max < 1 {
+		max = 1
+	}
+	for _, c := range candidates {
+		if seen[c] || c == "" {
+			continue
+		}
+		seen[c] = true
+		d := editDistance(lower, strings.ToLower(c))
+		if d <= max {
+			a = append(a, suggestion{c, d})
+		}
+	}
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].dist != a[j].dist {
+			return a[i].dist < a[j].dist
+		}
+		return a[i].name < a[j].name
+	})
+	if len(a) > maxSuggestions {
+		a = a[:maxSuggestions]
+	}
+	var names []string
+	for _, s := range a {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// Suggestions returns the names of identifiers that are in scope at ident
+// within root, which is typically a file, that are most similar to the name
+// of ident. The predeclared names are considered in addition to the names
+// declared in root and, if root is a file, the names of its imports.
+//
+// Suggestions is typically used to report a more helpful error for an
+// identifier that could not be resolved by Resolve.
+func Suggestions(root ast.Node, ident *ast.Ident, predeclared ...string) []string {
+	candidates := append([]string(nil), predeclared...)
+
+	f, ok := root.(*ast.File)
+	if ok {
+		for _, spec := range f.Imports {
+			candidates = append(candidates, ImportName(spec))
+		}
+	} else {
+		f = &ast.File{}
+	}
+
+	s := &scope{
+		file:  f,
+		errFn: func(token.Pos, string, ...interface{}) {},
+		found: func(s *scope, x *ast.Ident) {
+			if x != ident {
+				return
+			}
+			for ; s != nil; s = s.outer {
+				for name := range s.index {
+					candidates = append(candidates, name)
+				}
+			}
+		},
+	}
+	walk(s, root)
+
+	return Suggest(ident.Name, candidates)
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b,
+// where a transposition of two adjacent characters counts as a single edit.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] is the distance between s[:i] and t[:j].
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				if x := d[i-2][j-2] + 1; x < d[i][j] {
+					d[i][j] = x
+				}
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
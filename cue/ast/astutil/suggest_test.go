@@ -0,0 +1,93 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astutil_test
+
+import (
+	"reflect"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestSuggest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		candidates []string
+		want       []string
+	}{{
+		name:       "nmae",
+		candidates: []string{"name", "port", "namespace"},
+		want:       []string{"name"},
+	}, {
+		name:       "replicas",
+		candidates: []string{"replica", "replicas", "Replicas"},
+		want:       []string{"Replicas", "replica"},
+	}, {
+		name:       "contianer",
+		candidates: []string{"container", "containers", "content"},
+		want:       []string{"container", "containers"},
+	}, {
+		name:       "b",
+		candidates: []string{"a", "B", "bb"},
+		want:       []string{"B"},
+	}, {
+		name:       "image",
+		candidates: []string{"command", "args"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := astutil.Suggest(tc.name, tc.candidates)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	const src = `
+import "strings"
+
+name: "x"
+a: {
+	local: 1
+	b: locl + nmae + strigs.ToUpper("x")
+}
+c: lcoal
+`
+	f, err := parser.ParseFile("test", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]string{}
+	ast.Walk(f, nil, func(n ast.Node) {
+		if x, ok := n.(*ast.Ident); ok && x.Node == nil {
+			got[x.Name] = astutil.Suggestions(f, x, "string")
+		}
+	})
+	want := map[string][]string{
+		"locl":   {"local"},
+		"nmae":   {"name"},
+		"strigs": {"strings", "string"},
+		"lcoal":  nil,
+	}
+	for name, w := range want {
+		if g := got[name]; !reflect.DeepEqual(g, w) {
+			t.Errorf("%s: got %q; want %q", name, g, w)
+		}
+	}
+}
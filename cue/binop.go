@@ -146,6 +146,9 @@ type mVal struct {
 func distribute(ctx *context, src source, op op, x, y evaluated) evaluated {
 	dn := &disjunction{baseValue: src.base()}
 	dist(ctx, dn, false, op, mVal{x, true}, mVal{y, true})
+	if err := ctx.limitErr(); err != nil {
+		return ctx.mkErr(src, codeFatal, err)
+	}
 	return dn.normalize(ctx, src).val
 }
 
@@ -172,6 +175,9 @@ func dist(ctx *context, d *disjunction, mark bool, op op, x, y mVal) {
 		}
 		return
 	}
+	if err := ctx.addDisjunct(); err != nil {
+		return
+	}
 	src := binSrc(token.NoPos, op, x.val, y.val)
 	d.add(ctx, binOp(ctx, src, op, x.val, y.val), mark && x.mark && y.mark)
 }
@@ -543,7 +549,29 @@ func (x *customValidator) binOp(ctx *context, src source, op op, other evaluated
 			}
 			return other
 
-		case *nullLit, *boolLit, *durationLit, *list, *structLit, *stringLit, *bytesLit:
+		case *list:
+			// The length of an open list is not known until it is unified
+			// with a concrete list.
+			if y.isOpen() {
+				return &unification{newSrc, []evaluated{x, y}}
+			}
+			if err := x.check(ctx, y); err != nil {
+				return err
+			}
+			return y
+
+		case *structLit:
+			// Likewise, a struct with pattern constraints describes structs
+			// with any number of fields.
+			if !y.optionals.isEmpty() {
+				return &unification{newSrc, []evaluated{x, y}}
+			}
+			if err := x.check(ctx, y); err != nil {
+				return err
+			}
+			return y
+
+		case *nullLit, *boolLit, *durationLit, *stringLit, *bytesLit:
 			// All remaining concrete types. This includes non-comparable types
 			// for comparison to null.
 			if err := x.check(ctx, y); err != nil {
@@ -671,8 +699,7 @@ func (x *structLit) binOp(ctx *context, src source, op op, other evaluated) eval
 			}
 			// TODO: pass position of key, not value. Currently does not have
 			// a position.
-			return ctx.mkErr(a.v, a.v, "field %q not allowed in closed struct",
-				ctx.labelStr(a.feature))
+			return ctx.fieldNotAllowed(a.v, a.v, a.feature, y)
 		}
 		cp := ctx.copy(a.v)
 		obj.arcs = append(obj.arcs,
@@ -716,8 +743,7 @@ outer:
 			}
 			// TODO: pass position of key, not value. Currently does not have a
 			// position.
-			return ctx.mkErr(a.v, x, "field %q not allowed in closed struct",
-				ctx.labelStr(a.feature))
+			return ctx.fieldNotAllowed(a.v, x, a.feature, x)
 		}
 		a.setValue(v)
 		obj.arcs = append(obj.arcs, a)
@@ -31,6 +31,10 @@ import (
 // Any operation that involves two Values or Instances should originate from
 // the same Runtime.
 //
+// Values and Instances are evaluated lazily and may therefore not be used
+// concurrently. Use Value.Fork to obtain values that can be evaluated
+// concurrently. Different Runtimes may be used concurrently.
+//
 // The zero value of a Runtime is ready to use.
 type Runtime struct {
 	ctx *build.Context // TODO: remove
@@ -206,8 +210,11 @@ func (r *Runtime) FromExpr(expr ast.Expr) (*Instance, error) {
 //
 // All instances belonging to the same package should share this index.
 type index struct {
-	labelMap map[string]label
-	labels   []string
+	// labelMap and labels are nil for forked indexes, which share the labels
+	// of their parent. Both are guarded by labelMutex.
+	labelMutex sync.RWMutex
+	labelMap   map[string]label
+	labels     []string
 
 	loaded        map[*build.Instance]*Instance
 	imports       map[value]*Instance // key is always a *structLit
@@ -216,6 +223,11 @@ type index struct {
 	offset label
 	parent *index
 
+	// limitConfig holds the evaluation limits set with Runtime.SetLimits.
+	// It is guarded by limitsMutex.
+	limitsMutex sync.RWMutex
+	limitConfig *Limits
+
 	mutex     sync.Mutex
 	typeCache sync.Map // map[reflect.Type]evaluated
 }
@@ -251,17 +263,35 @@ func newSharedIndex() *index {
 
 // newIndex creates a new index.
 func newIndex(parent *index) *index {
+	owner := parent.labelOwner()
+	owner.labelMutex.RLock()
+	offset := label(len(owner.labels)) + owner.offset
+	owner.labelMutex.RUnlock()
+
 	i := &index{
 		labelMap:      map[string]label{},
 		loaded:        map[*build.Instance]*Instance{},
 		imports:       map[value]*Instance{},
 		importsByPath: map[string]*Instance{},
-		offset:        label(len(parent.labels)) + parent.offset,
+		offset:        offset,
 		parent:        parent,
+		limitConfig:   parent.getLimits(),
 	}
 	return i
 }
 
+// newForkIndex creates an index that shares the labels of parent, but not its
+// instances.
+func newForkIndex(parent *index) *index {
+	return &index{
+		loaded:        map[*build.Instance]*Instance{},
+		imports:       map[value]*Instance{},
+		importsByPath: map[string]*Instance{},
+		parent:        parent,
+		limitConfig:   parent.getLimits(),
+	}
+}
+
 func (idx *index) strLabel(str string) label {
 	return idx.label(str, false)
 }
@@ -280,7 +310,9 @@ func (idx *index) nodeLabel(n ast.Node) (f label, ok bool) {
 
 func (idx *index) findLabel(s string) (f label, ok bool) {
 	for x := idx; x != nil; x = x.parent {
+		x.labelMutex.RLock()
 		f, ok = x.labelMap[s]
+		x.labelMutex.RUnlock()
 		if ok {
 			break
 		}
@@ -288,12 +320,26 @@ func (idx *index) findLabel(s string) (f label, ok bool) {
 	return f, ok
 }
 
+// labelOwner returns the index that holds the labels of idx.
+func (idx *index) labelOwner() *index {
+	for idx.labelMap == nil {
+		idx = idx.parent
+	}
+	return idx
+}
+
 func (idx *index) label(s string, isIdent bool) label {
 	f, ok := idx.findLabel(s)
 	if !ok {
-		f = label(len(idx.labelMap)) + idx.offset
-		idx.labelMap[s] = f
-		idx.labels = append(idx.labels, s)
+		x := idx.labelOwner()
+		x.labelMutex.Lock()
+		// Another goroutine may have added the label in the meantime.
+		if f, ok = x.labelMap[s]; !ok {
+			f = label(len(x.labelMap)) + x.offset
+			x.labelMap[s] = f
+			x.labels = append(x.labels, s)
+		}
+		x.labelMutex.Unlock()
 	}
 	f <<= 1
 	if isIdent && s != "" && s[0] == '_' {
@@ -304,8 +350,10 @@ func (idx *index) label(s string, isIdent bool) label {
 
 func (idx *index) labelStr(l label) string {
 	l >>= 1
-	for ; l < idx.offset; idx = idx.parent {
+	for idx = idx.labelOwner(); l < idx.offset; idx = idx.parent.labelOwner() {
 	}
+	idx.labelMutex.RLock()
+	defer idx.labelMutex.RUnlock()
 	return idx.labels[l-idx.offset]
 }
 
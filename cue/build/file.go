@@ -30,12 +30,19 @@ type File struct {
 type Encoding string
 
 const (
-	CUE      Encoding = "cue"
-	JSON     Encoding = "json"
-	YAML     Encoding = "yaml"
-	JSONL    Encoding = "jsonl"
-	Text     Encoding = "text"
-	Protobuf Encoding = "proto"
+	CUE        Encoding = "cue"
+	JSON       Encoding = "json"
+	YAML       Encoding = "yaml"
+	JSONL      Encoding = "jsonl"
+	JSONC      Encoding = "jsonc"
+	Text       Encoding = "text"
+	Protobuf   Encoding = "proto"
+	HCL        Encoding = "hcl"
+	XML        Encoding = "xml"
+	CSV        Encoding = "csv"
+	TSV        Encoding = "tsv"
+	Env        Encoding = "env"
+	Properties Encoding = "properties"
 
 	// TODO:
 	// TOML
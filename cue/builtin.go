@@ -348,6 +348,9 @@ func initBuiltins(pkgs map[string]*builtinPkg) {
 	for _, k := range keys {
 		b := pkgs[k]
 		e := mustCompileBuiltins(ctx, b, k)
+		// Builtin packages are shared by all Runtimes. Evaluate them fully
+		// so that they are not modified during concurrent evaluation.
+		evalAll(ctx, e, 0)
 
 		i := sharedIndex.addInst(&Instance{
 			ImportPath: k,
@@ -361,6 +364,29 @@ func initBuiltins(pkgs map[string]*builtinPkg) {
 	}
 }
 
+// evalAll recursively evaluates all fields of v, including optional ones.
+func evalAll(ctx *context, v evaluated, depth int) {
+	if depth > internal.MaxDepth {
+		return
+	}
+	switch x := v.(type) {
+	case *structLit:
+		x, err := x.expandFields(ctx)
+		if err != nil {
+			return
+		}
+		for i := range x.arcs {
+			evalAll(ctx, x.at(ctx, i), depth+1)
+		}
+	case *list:
+		evalAll(ctx, x.elem, depth+1)
+	case *disjunction:
+		for _, d := range x.values {
+			evalAll(ctx, d.val.evalPartial(ctx), depth+1)
+		}
+	}
+}
+
 func getBuiltinShorthandPkg(ctx *context, shorthand string) *structLit {
 	return getBuiltinPkg(ctx, "-/"+shorthand)
 }
@@ -374,6 +400,15 @@ func getBuiltinPkg(ctx *context, path string) *structLit {
 }
 
 func init() {
+	internal.BuiltinPackages = func() []string {
+		paths := []string{}
+		for k := range builtinPackages {
+			paths = append(paths, k)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
 	internal.UnifyBuiltin = func(val interface{}, kind string) interface{} {
 		v := val.(Value)
 		ctx := v.ctx()
@@ -559,6 +559,18 @@ func TestBuiltins(t *testing.T) {
 	}, {
 		test("struct", `struct.MaxFields(2) & {a: 1}`),
 		`{a: 1}`,
+	}, {
+		test("struct", `struct.MinFields(1) & {[string]: int} & {a: 1}`),
+		`{[]: (_: string)->int, a: 1}`,
+	}, {
+		test("struct", `struct.MinFields(1) & {[string]: int} & {}`),
+		`_|_(invalid value {} (does not satisfy struct.MinFields(1)))`,
+	}, {
+		test("list", `list.MaxItems(2) & [...int] & [1, 2]`),
+		`[(int & 1),(int & 2)]`,
+	}, {
+		test("list", `list.MaxItems(2) & [...int] & [1, 2, 3]`),
+		`_|_(invalid value [1,2,3] (does not satisfy list.MaxItems(2)))`,
 	}, {
 		test("math", `math.Pow(8, 4)`), `4096`,
 	}, {
@@ -45,6 +45,9 @@ type context struct {
 	// TODO: replace with proper structural cycle detection/ occurs check.
 	// See Issue #29.
 	maxDepth int
+
+	// limits tracks the resources used by this evaluation, if limits are set.
+	limits *evalLimits
 }
 
 func (c *context) incEvalDepth() {
@@ -71,6 +74,7 @@ func (idx *index) newContext() *context {
 	c := &context{
 		Context: &baseContext,
 		index:   idx,
+		limits:  idx.newEvalLimits(),
 	}
 	return c
 }
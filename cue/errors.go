@@ -17,8 +17,11 @@ package cue
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 )
@@ -27,8 +30,9 @@ var _ errors.Error = &nodeError{}
 
 // A nodeError is an error associated with processing an AST node.
 type nodeError struct {
-	path []string // optional
-	n    ast.Node
+	path        []string // optional
+	n           ast.Node
+	suggestions []string // optional
 
 	errors.Message
 }
@@ -50,6 +54,10 @@ func (e *nodeError) Position() token.Pos {
 
 func (e *nodeError) InputPositions() []token.Pos { return nil }
 
+func (e *nodeError) Code() errors.Code { return errors.CompileError }
+
+func (e *nodeError) Suggestions() []string { return e.suggestions }
+
 func (e *nodeError) Path() []string {
 	return e.path
 }
@@ -96,10 +104,51 @@ func (e *valueError) InputPositions() []token.Pos {
 	return e.err.Positions(e.v.ctx())
 }
 
+// ValuePositions reports the positions of the error that refer to concrete
+// values, as opposed to constraints.
+func (e *valueError) ValuePositions() []token.Pos {
+	return e.err.valuePositions(e.v.ctx())
+}
+
 func (e *valueError) Msg() (string, []interface{}) {
 	return e.err.Msg()
 }
 
+// Code reports the code of an error wrapped by the evaluation error, such as
+// an error resulting from exceeding an evaluation limit, or otherwise the code
+// corresponding to the kind of evaluation error.
+func (e *valueError) Code() errors.Code {
+	for b := e.err; b != nil; b = b.wrapped {
+		if b.err != nil {
+			if c := errors.CodeOf(b.err); c != "" {
+				return c
+			}
+		}
+	}
+	switch e.err.code {
+	case codeNotExist:
+		return errors.NotFound
+	case codeIncomplete:
+		return errors.Incomplete
+	case codeCycle:
+		return errors.Cycle
+	case codeUser:
+		return errors.UserError
+	}
+	return errors.EvalError
+}
+
+// Suggestions reports possible corrections for the error, such as the names
+// of allowed fields that are similar to a field that is not allowed.
+func (e *valueError) Suggestions() []string {
+	for b := e.err; b != nil; b = b.wrapped {
+		if len(b.suggestions) > 0 {
+			return b.suggestions
+		}
+	}
+	return nil
+}
+
 func (e *valueError) Path() (a []string) {
 	if e.v.path == nil {
 		return nil
@@ -158,6 +207,8 @@ type bottom struct {
 	sub     []*bottom    // sub errors
 	value   value
 	wrapped *bottom
+
+	suggestions []string // possible corrections
 }
 
 func (x *bottom) kind() kind { return bottomKind }
@@ -197,6 +248,49 @@ func appendPositions(ctx *context, pos []token.Pos, src source) []token.Pos {
 	return pos
 }
 
+// valuePositions returns the positions of x that refer to concrete values.
+func (x *bottom) valuePositions(ctx *context) []token.Pos {
+	var a []token.Pos
+	if x.index != nil {
+		a = appendValuePositions(ctx, nil, x.pos)
+	}
+	if w := x.wrapped; w != nil {
+		a = append(a, w.valuePositions(ctx)...)
+	}
+	for _, sub := range x.sub {
+		a = append(a, sub.valuePositions(ctx)...)
+	}
+	return a
+}
+
+// appendValuePositions is like appendPositions, but only appends the
+// positions of sources that evaluate to concrete values.
+func appendValuePositions(ctx *context, pos []token.Pos, src source) []token.Pos {
+	if len(pos) > 15 || src == nil {
+		return pos
+	}
+	if c := src.computed(); c != nil {
+		pos = appendValuePositions(ctx, pos, c.x)
+		pos = appendValuePositions(ctx, pos, c.y)
+	}
+	var v evaluated
+	switch x := src.(type) {
+	case evaluated:
+		v = x
+	case value:
+		v = x.evalPartial(ctx)
+		pos = appendValuePositions(ctx, pos, v)
+	default:
+		return pos
+	}
+	if k := v.kind(); k != bottomKind && k.isGround() {
+		if p := src.Pos(); p != token.NoPos {
+			pos = append(pos, p)
+		}
+	}
+	return pos
+}
+
 func (x *bottom) Msg() (format string, args []interface{}) {
 	ctx := x.index.newContext()
 	// We need to copy to avoid races.
@@ -267,6 +361,42 @@ outer:
 	return e
 }
 
+// fieldNotAllowed reports an error for a field f that is not allowed by the
+// closed struct s, suggesting the fields of s with similar names.
+func (c *context) fieldNotAllowed(src source, v value, f label, s *structLit) *bottom {
+	name := c.labelStr(f)
+	var candidates []string
+	for _, a := range s.arcs {
+		if !a.definition && a.feature&hidden == 0 {
+			candidates = append(candidates, c.labelStr(a.feature))
+		}
+	}
+	suggestions := astutil.Suggest(name, candidates)
+	if len(suggestions) == 0 {
+		return c.mkErr(src, v, "field %q not allowed in closed struct", name)
+	}
+	err := c.mkErr(src, v, "field %q not allowed in closed struct; did you mean %s?",
+		name, quoteList(suggestions))
+	err.suggestions = suggestions
+	return err
+}
+
+// quoteList formats a list of names as a quoted enumeration, such as
+// "a", "b", or "c".
+func quoteList(a []string) string {
+	q := make([]string, len(a))
+	for i, s := range a {
+		q[i] = strconv.Quote(s)
+	}
+	switch len(q) {
+	case 1:
+		return q[0]
+	case 2:
+		return q[0] + " or " + q[1]
+	}
+	return strings.Join(q[:len(q)-1], ", ") + ", or " + q[len(q)-1]
+}
+
 func fixArg(idx *index, x interface{}) interface{} {
 	switch x.(type) {
 	case uint, int, string:
@@ -160,6 +160,100 @@ func Promote(err error, msg string) Error {
 	}
 }
 
+// A Code identifies a class of errors. Codes are stable across releases and
+// allow callers to distinguish errors without inspecting their messages.
+type Code string
+
+const (
+	// Cancelled indicates that evaluation was cancelled through its
+	// context.Context.
+	Cancelled Code = "cancelled"
+
+	// MaxStepsExceeded indicates that evaluation exceeded the maximum number
+	// of evaluation steps.
+	MaxStepsExceeded Code = "max_steps_exceeded"
+
+	// MaxDisjunctsExceeded indicates that evaluation exceeded the maximum
+	// number of disjuncts resulting from expanding disjunctions.
+	MaxDisjunctsExceeded Code = "max_disjuncts_exceeded"
+
+	// MaxDepthExceeded indicates that evaluation exceeded the maximum
+	// structure depth.
+	MaxDepthExceeded Code = "max_depth_exceeded"
+
+	// SyntaxError indicates an error in the syntax of a CUE file.
+	SyntaxError Code = "syntax"
+
+	// CompileError indicates an error in a CUE file detected while compiling
+	// it, such as a reference to an undefined identifier.
+	CompileError Code = "compile"
+
+	// EvalError indicates a generic evaluation error, such as a conflict
+	// between two values.
+	EvalError Code = "eval"
+
+	// NotFound indicates a reference to a field or value that does not exist.
+	NotFound Code = "not_found"
+
+	// Incomplete indicates that a value could not be evaluated, as it is
+	// not yet sufficiently specific.
+	Incomplete Code = "incomplete"
+
+	// Cycle indicates a reference cycle.
+	Cycle Code = "cycle"
+
+	// UserError indicates an error explicitly specified in a configuration.
+	UserError Code = "user"
+)
+
+// CodeOf reports the code of err, or of the first of its individual errors
+// that has one. It returns the empty string if there is no such code.
+func CodeOf(err error) Code {
+	type coder interface{ Code() Code }
+	for _, e := range Errors(err) {
+		if c := coder(nil); xerrors.As(e, &c) {
+			if code := c.Code(); code != "" {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// WithCode returns an Error that is identical to err, but reports the given
+// code from CodeOf.
+func WithCode(err Error, code Code) Error {
+	return &codeError{err: err, code: code}
+}
+
+type codeError struct {
+	err  Error
+	code Code
+}
+
+func (e *codeError) Code() Code                               { return e.code }
+func (e *codeError) Unwrap() error                            { return e.err }
+func (e *codeError) Error() string                            { return e.err.Error() }
+func (e *codeError) Position() token.Pos                      { return e.err.Position() }
+func (e *codeError) InputPositions() []token.Pos              { return e.err.InputPositions() }
+func (e *codeError) Path() []string                           { return e.err.Path() }
+func (e *codeError) Msg() (format string, args []interface{}) { return e.err.Msg() }
+
+// Suggestions reports possible corrections, such as the names of similarly
+// named fields, for err or for the first of its individual errors that has
+// any.
+func Suggestions(err error) []string {
+	type suggester interface{ Suggestions() []string }
+	for _, e := range Errors(err) {
+		if s := suggester(nil); xerrors.As(e, &s) {
+			if a := s.Suggestions(); len(a) > 0 {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
 var _ Error = &posError{}
 
 // In an List, an error is represented by an *posError.
@@ -351,7 +445,6 @@ func Sanitize(err Error) Error {
 // Sort sorts an List. *posError entries are sorted by position,
 // other errors are sorted by error message, and before any *posError
 // entry.
-//
 func (p list) Sort() {
 	sort.Sort(p)
 }
@@ -437,12 +530,47 @@ type Config struct {
 
 	// ToSlash sets whether to use Unix paths. Mostly used for testing.
 	ToSlash bool
+
+	// Rich enables printing the source line of each position of an error,
+	// with the offending code underlined. The primary position of the error
+	// is printed first. If an error has multiple positions and reports which
+	// of them refer to concrete values, each is labeled as either a
+	// conflicting value or a constraint. Duplicate positions are only printed
+	// once.
+	Rich bool
+
+	// Color sets whether to use ANSI escape sequences to color the output
+	// in rich mode.
+	Color bool
+
+	// ReadFile is used to read source files in rich mode. If ReadFile is nil,
+	// ioutil.ReadFile is used. Positions of files that cannot be read are
+	// printed as in the default mode.
+	ReadFile func(filename string) ([]byte, error)
+}
+
+// filename returns the name of the given file for printing.
+func (cfg *Config) filename(s string) string {
+	if cfg.Cwd != "" {
+		if p, err := filepath.Rel(cfg.Cwd, s); err == nil {
+			s = p
+			// Some IDEs (e.g. VSCode) only recognize a path if it start
+			// with a dot. This also helps to distinguish between local
+			// files and builtin packages.
+			if !strings.HasPrefix(s, ".") {
+				s = fmt.Sprintf(".%s%s", string(filepath.Separator), s)
+			}
+		}
+	}
+	if cfg.ToSlash {
+		s = filepath.ToSlash(s)
+	}
+	return s
 }
 
 // Print is a utility function that prints a list of errors to w,
 // one error per line, if the err parameter is an List. Otherwise
 // it prints the err string.
-//
 func Print(w io.Writer, err error, cfg *Config) {
 	if cfg == nil {
 		cfg = &Config{}
@@ -477,6 +605,11 @@ func writeErr(w io.Writer, err Error) {
 	}
 
 	for {
+		if c, ok := err.(*codeError); ok {
+			// A code does not add to the message of the error it wraps.
+			err = c.err
+			continue
+		}
 		u := xerrors.Unwrap(err)
 
 		printed := false
@@ -514,24 +647,21 @@ func printError(w io.Writer, err error, cfg *Config) {
 		fprintf = defaultFprintf
 	}
 
+	if cfg.Rich {
+		p := &richPrinter{
+			w:       w,
+			cfg:     cfg,
+			fprintf: fprintf,
+			files:   map[string][][]byte{},
+		}
+		p.print(err)
+		return
+	}
+
 	positions := []string{}
 	for _, p := range Positions(err) {
 		pos := p.Position()
-		s := pos.Filename
-		if cfg.Cwd != "" {
-			if p, err := filepath.Rel(cfg.Cwd, s); err == nil {
-				s = p
-				// Some IDEs (e.g. VSCode) only recognize a path if it start
-				// with a dot. This also helps to distinguish between local
-				// files and builtin packages.
-				if !strings.HasPrefix(s, ".") {
-					s = fmt.Sprintf(".%s%s", string(filepath.Separator), s)
-				}
-			}
-		}
-		if cfg.ToSlash {
-			s = filepath.ToSlash(s)
-		}
+		s := cfg.filename(pos.Filename)
 		if pos.IsValid() {
 			if s != "" {
 				s += ":"
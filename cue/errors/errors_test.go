@@ -16,9 +16,12 @@ package errors
 
 import (
 	"bytes"
+	"context"
+	"os"
 	"testing"
 
 	"cuelang.org/go/cue/token"
+	"golang.org/x/xerrors"
 )
 
 func TestError_Error(t *testing.T) {
@@ -190,3 +193,170 @@ func TestPrintError(t *testing.T) {
 		}
 	}
 }
+
+type testError struct {
+	Message
+	pos    token.Pos
+	inputs []token.Pos
+	path   []string
+}
+
+func (e *testError) Position() token.Pos         { return e.pos }
+func (e *testError) InputPositions() []token.Pos { return e.inputs }
+func (e *testError) Path() []string              { return e.path }
+
+// valueTestError is a testError that reports which of its positions refer to
+// values.
+type valueTestError struct {
+	testError
+	values []token.Pos
+}
+
+func (e *valueTestError) ValuePositions() []token.Pos { return e.values }
+
+func TestPrintRich(t *testing.T) {
+	const src = "a: 1\na: >=2\n\tb: \"foo\"\nc: int\n"
+	f := token.NewFile("/dir/x.cue", -1, len(src))
+	f.SetLinesForContent([]byte(src))
+	at := func(offset int) token.Pos { return f.Pos(offset, 0) }
+
+	readFile := func(filename string) ([]byte, error) {
+		if filename != "/dir/x.cue" {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+
+	other := token.NewFile("/other.cue", -1, 10)
+
+	testCases := []struct {
+		name  string
+		err   Error
+		color bool
+		want  string
+	}{{
+		name: "single",
+		err: &testError{
+			Message: NewMessage("incomplete value %v", []interface{}{"int"}),
+			pos:     at(25),
+			path:    []string{"c"},
+		},
+		want: `c: incomplete value int:
+ --> ./x.cue
+  |
+4 | c: int
+  |    ^^^
+`,
+	}, {
+		name: "conflict",
+		err: &valueTestError{
+			testError: testError{
+				Message: NewMessage("invalid value 1 (out of bound >=2)", nil),
+				pos:     at(3),
+				inputs:  []token.Pos{at(8), at(3), at(8)},
+				path:    []string{"a"},
+			},
+			values: []token.Pos{at(3)},
+		},
+		want: `a: invalid value 1 (out of bound >=2):
+ --> ./x.cue
+  |
+1 | a: 1
+  |    ^ conflicting value
+2 | a: >=2
+  |    ^^^ constraint
+`,
+	}, {
+		name: "primary first",
+		err: &valueTestError{
+			testError: testError{
+				Message: NewMessage("invalid value 1 (out of bound >=2)", nil),
+				pos:     at(8),
+				inputs:  []token.Pos{at(3)},
+				path:    []string{"a"},
+			},
+			values: []token.Pos{at(3)},
+		},
+		want: `a: invalid value 1 (out of bound >=2):
+ --> ./x.cue
+  |
+2 | a: >=2
+  |    ^^^ constraint
+1 | a: 1
+  |    ^ conflicting value
+`,
+	}, {
+		name: "no labels",
+		err: &testError{
+			Message: NewMessage("invalid value 1 (out of bound >=2)", nil),
+			pos:     at(3),
+			inputs:  []token.Pos{at(8)},
+		},
+		want: `invalid value 1 (out of bound >=2):
+ --> ./x.cue
+  |
+1 | a: 1
+  |    ^
+2 | a: >=2
+  |    ^^^
+`,
+	}, {
+		name: "tabs and unreadable files",
+		err: &testError{
+			Message: NewMessage("conflicting values", nil),
+			pos:     at(16),
+			inputs:  []token.Pos{other.Pos(0, 0)},
+		},
+		want: `conflicting values:
+ --> ./x.cue
+  |
+3 | 	b: "foo"
+  | 	   ^^^^^
+    ../other.cue:1:1
+`,
+	}, {
+		name: "color",
+		err: &testError{
+			Message: NewMessage("incomplete value", nil),
+			pos:     at(25),
+		},
+		color: true,
+		want: "\x1b[1mincomplete value\x1b[0m:\n" +
+			" \x1b[34m-->\x1b[0m ./x.cue\n" +
+			"\x1b[34m  |\x1b[0m\n" +
+			"\x1b[34m4 |\x1b[0m c: int\n" +
+			"\x1b[34m  |\x1b[0m    \x1b[1m\x1b[31m^^^\x1b[0m\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Details(tc.err, &Config{
+				Cwd:      "/dir",
+				ToSlash:  true,
+				Rich:     true,
+				Color:    tc.color,
+				ReadFile: readFile,
+			})
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+
+	// Errors without positions are printed on a single line.
+	got := Details(Newf(token.NoPos, "some error"), &Config{Rich: true})
+	if want := "some error\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestWithCodeUnwrap(t *testing.T) {
+	for _, target := range []error{context.Canceled, context.DeadlineExceeded} {
+		err := WithCode(Wrapf(target, token.NoPos, "evaluation cancelled"), Cancelled)
+		if !xerrors.Is(err, target) {
+			t.Errorf("xerrors.Is(%v, %v) = false; want true", err, target)
+		}
+		if got := CodeOf(err); got != Cancelled {
+			t.Errorf("got code %q; want %q", got, Cancelled)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"cuelang.org/go/cue/token"
+)
+
+// ANSI escape sequences used for printing in color.
+const (
+	colorReset   = "\x1b[0m"
+	colorBold    = "\x1b[1m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorPrimary = colorBold + colorRed
+)
+
+// Labels for positions in rich mode.
+const (
+	labelValue      = "conflicting value"
+	labelConstraint = "constraint"
+)
+
+type richPrinter struct {
+	w       io.Writer
+	cfg     *Config
+	fprintf func(w io.Writer, format string, args ...interface{})
+	files   map[string][][]byte // lines of each source file; nil if unreadable
+}
+
+// color returns s wrapped in the given color if colors are enabled.
+func (p *richPrinter) color(color, s string) string {
+	if !p.cfg.Color || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// lines returns the lines of the given source file, or nil if the file could
+// not be read.
+func (p *richPrinter) lines(filename string) [][]byte {
+	if a, ok := p.files[filename]; ok {
+		return a
+	}
+	readFile := p.cfg.ReadFile
+	if readFile == nil {
+		readFile = ioutil.ReadFile
+	}
+	var a [][]byte
+	if b, err := readFile(filename); err == nil {
+		a = bytes.Split(b, []byte("\n"))
+	}
+	p.files[filename] = a
+	return a
+}
+
+type richPos struct {
+	pos     token.Position
+	primary bool
+	label   string
+}
+
+// A valuePositioner is implemented by errors that can tell which of their
+// positions refer to concrete values, as opposed to constraints.
+type valuePositioner interface {
+	ValuePositions() []token.Pos
+}
+
+// print prints err with the source line of each of its positions, marking
+// the offending code. For example:
+//
+//	a: conflicting values 1 and 2:
+//	 --> ./x.cue
+//	  |
+//	3 | a: 1
+//	  |    ^ conflicting value
+//	4 | a: 2
+//	  |    ^ conflicting value
+func (p *richPrinter) print(err error) {
+	var msg string
+	if e, ok := err.(Error); ok {
+		msg = String(e)
+	} else {
+		msg = fmt.Sprint(err)
+	}
+
+	var positions []richPos
+	primary := token.NoPos
+	if e, ok := err.(Error); ok {
+		primary = e.Position()
+	}
+	// Positions are only labeled if the error can tell the values apart from
+	// the constraints.
+	var values map[token.Position]bool
+	if vp := valuePositioner(nil); xerrors.As(err, &vp) {
+		values = map[token.Position]bool{}
+		for _, pos := range vp.ValuePositions() {
+			values[normPosition(pos)] = true
+		}
+	}
+	seen := map[token.Position]bool{}
+	for _, pos := range Positions(err) {
+		if !pos.IsValid() {
+			continue
+		}
+		// Different positions may refer to the same location, for instance
+		// if a file is loaded more than once.
+		pp := normPosition(pos)
+		if seen[pp] {
+			continue
+		}
+		seen[pp] = true
+		rp := richPos{pos: pp, primary: pos == primary}
+		switch {
+		case values == nil:
+		case values[pp]:
+			rp.label = labelValue
+		default:
+			rp.label = labelConstraint
+		}
+		positions = append(positions, rp)
+	}
+	if len(positions) == 0 {
+		p.fprintf(p.w, "%s\n", p.color(colorBold, msg))
+		return
+	}
+	p.fprintf(p.w, "%s:\n", p.color(colorBold, msg))
+
+	// Positions are sorted by file, line and column, except for the primary
+	// position, which comes first. Files are printed in order of appearance.
+	var files []string
+	byFile := map[string][]richPos{}
+	for _, rp := range positions {
+		name := rp.pos.Filename
+		if _, ok := byFile[name]; !ok {
+			files = append(files, name)
+		}
+		byFile[name] = append(byFile[name], rp)
+	}
+	for _, name := range files {
+		a := byFile[name]
+		sort.SliceStable(a, func(i, j int) bool {
+			if a[i].primary != a[j].primary {
+				return a[i].primary
+			}
+			if a[i].pos.Line != a[j].pos.Line {
+				return a[i].pos.Line < a[j].pos.Line
+			}
+			return a[i].pos.Column < a[j].pos.Column
+		})
+		p.printFile(name, a, len(positions) > 1)
+	}
+}
+
+// normPosition returns the location of pos, independent of the offset.
+func normPosition(pos token.Pos) token.Position {
+	pp := pos.Position()
+	pp.Offset = 0
+	return pp
+}
+
+func (p *richPrinter) printFile(filename string, a []richPos, label bool) {
+	name := p.cfg.filename(filename)
+	lines := p.lines(filename)
+
+	if lines == nil {
+		// Without source, print positions as in the default mode.
+		for _, rp := range a {
+			s := fmt.Sprintf("%d:%d", rp.pos.Line, rp.pos.Column)
+			if name != "" {
+				s = name + ":" + s
+			}
+			p.fprintf(p.w, "    %s\n", s)
+		}
+		return
+	}
+
+	maxLine := 0
+	for _, rp := range a {
+		if rp.pos.Line > maxLine {
+			maxLine = rp.pos.Line
+		}
+	}
+	width := len(fmt.Sprint(maxLine))
+	gutter := p.color(colorBlue, strings.Repeat(" ", width)+" |")
+	if name == "" {
+		name = "-"
+	}
+	p.fprintf(p.w, "%s%s %s\n", strings.Repeat(" ", width), p.color(colorBlue, "-->"), name)
+	p.fprintf(p.w, "%s\n", gutter)
+
+	for i, rp := range a {
+		n := rp.pos.Line
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		line := bytes.TrimRight(lines[n-1], "\r")
+		if i == 0 || a[i-1].pos.Line != n {
+			num := fmt.Sprintf("%*d |", width, n)
+			p.fprintf(p.w, "%s %s\n", p.color(colorBlue, num), line)
+		}
+
+		col := rp.pos.Column - 1
+		if col < 0 || col > len(line) {
+			col = len(line)
+		}
+		length := tokenLen(line[col:])
+		var indent []byte
+		for _, c := range line[:col] {
+			if c != '\t' {
+				c = ' '
+			}
+			indent = append(indent, c)
+		}
+		marker := strings.Repeat("^", length)
+		if label && rp.label != "" {
+			marker += " " + rp.label
+		}
+		color := colorYellow
+		if rp.primary {
+			color = colorPrimary
+		}
+		p.fprintf(p.w, "%s %s%s\n", gutter, indent, p.color(color, marker))
+	}
+}
+
+// tokenLen reports the length of the expression starting with the first token
+// in src, up to the end of the line. Unary operators, including bounds, are
+// reported together with their operand.
+func tokenLen(src []byte) int {
+	if len(src) == 0 {
+		return 1
+	}
+	n := operatorLen(src)
+	if n == 0 {
+		return literalLen(src)
+	}
+	for n < len(src) && (src[n] == ' ' || src[n] == '\t') {
+		n++
+	}
+	return n + literalLen(src[n:])
+}
+
+// operatorLen returns the length of a unary operator at the start of src or
+// 0 if src does not start with a unary operator.
+func operatorLen(src []byte) int {
+	if len(src) >= 2 {
+		switch string(src[:2]) {
+		case ">=", "<=", "!=", "=~", "!~":
+			return 2
+		}
+	}
+	switch src[0] {
+	case '-', '+', '<', '>', '!':
+		return 1
+	}
+	return 0
+}
+
+// literalLen returns the length of the literal or identifier at the start of
+// src. Other tokens have length 1.
+func literalLen(src []byte) int {
+	if len(src) == 0 {
+		return 0
+	}
+	switch c := src[0]; {
+	case c == '"' || c == '\'':
+		for i := 1; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case c:
+				return i + 1
+			}
+		}
+		return len(src)
+
+	case isDigit(c) || c == '.' && len(src) > 1 && isDigit(src[1]):
+		i := 1
+		for ; i < len(src); i++ {
+			c := src[i]
+			if isLetter(c) || isDigit(c) || c == '.' {
+				continue
+			}
+			if (c == '-' || c == '+') && (src[i-1] == 'e' || src[i-1] == 'E') {
+				continue
+			}
+			break
+		}
+		return i
+
+	case isLetter(c):
+		i := 1
+		for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+			i++
+		}
+		return i
+	}
+	return 1
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+		c == '_' || c == '$' || c == '#' || c >= 0x80
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
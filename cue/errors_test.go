@@ -0,0 +1,77 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+)
+
+func TestRichErrorLabels(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{{
+		in: "a: 1\na: 2\n",
+		want: `a: conflicting values 1 and 2:
+ --> /x.cue
+  |
+1 | a: 1
+  |    ^ conflicting value
+2 | a: 2
+  |    ^ conflicting value
+`,
+	}, {
+		in: "a: 1\na: >=2\n",
+		want: `a: invalid value 1 (out of bound >=2):
+ --> /x.cue
+  |
+2 | a: >=2
+  |    ^^^ constraint
+1 | a: 1
+  |    ^ conflicting value
+`,
+	}, {
+		in: "a: int\na: \"s\"\n",
+		want: `a: conflicting values int and "s" (mismatched types int and string):
+ --> /x.cue
+  |
+1 | a: int
+  |    ^^^ constraint
+2 | a: "s"
+  |    ^^^ conflicting value
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			var r Runtime
+			inst, err := r.Compile("/x.cue", tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = inst.Value().Validate()
+			got := errors.Details(err, &errors.Config{
+				Rich: true,
+				ReadFile: func(string) ([]byte, error) {
+					return []byte(tc.in), nil
+				},
+			})
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
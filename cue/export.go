@@ -1052,7 +1052,7 @@ func appendEscapedRune(buf []byte, r rune, quote byte, graphicOnly bool) []byte
 		buf = append(buf, `\v`...)
 	default:
 		switch {
-		case r < ' ':
+		case r < ' ' && quote == '\'':
 			// Invalid for strings, only bytes.
 			buf = append(buf, `\x`...)
 			buf = append(buf, lowerhex[byte(r)>>4])
@@ -47,6 +47,13 @@ func TestExport(t *testing.T) {
 			multiSep + "hello" +
 			multiSep + "world" +
 			multiSep + `"""`,
+	}, {
+		// Strings may not contain \x escapes, only bytes.
+		in:  `"a\u0001b"`,
+		out: `"a\u0001b"`,
+	}, {
+		in:  `'a\x01b'`,
+		out: `'a\x01b'`,
 	}, {
 		in: `{
 			$type: 3
@@ -0,0 +1,104 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+// Fork returns a Value that is equal to v, but that does not share any
+// evaluation state with v or with other forks of v.
+//
+// Values are evaluated lazily and store the results of evaluation as they go.
+// For this reason, methods of a Value, or of any Value derived from it, may
+// not be called concurrently. A fork, and any Value derived from it, may be
+// used concurrently with other forks of the same Value. For instance, a
+// schema can be shared across goroutines by having each goroutine validate
+// its data against its own fork:
+//
+//	w := schema.Fork().Fill(data)
+//	if err := w.Validate(); err != nil {
+//		...
+//	}
+//
+// Forking is considerably cheaper than compiling a configuration anew: it
+// copies the internal representation of v and the packages it imports, but
+// not the results of evaluation. Values derived from a fork may only be
+// combined with other values derived from the same fork.
+//
+// Fork may be called concurrently with other calls to Fork for the same
+// value, but not concurrently with the evaluation of v itself.
+func (v Value) Fork() Value {
+	if v.path == nil {
+		return v
+	}
+	idx := newForkIndex(v.idx)
+	ctx := idx.newContext()
+
+	// Copy the instances, such as imported packages, that may be referenced
+	// by v. Their roots are allocated in advance so that references between
+	// instances are redirected to the copies.
+	type forkedRoot struct {
+		inst   *Instance
+		root   *structLit
+		shells []*structLit
+	}
+	var roots []forkedRoot
+	forwards := map[*structLit]*structLit{}
+	for x := v.idx; x != nil && x != sharedIndex; x = x.parent {
+		for _, inst := range x.imports {
+			root, ok := inst.rootValue.(*structLit)
+			if !ok || forwards[root] != nil {
+				continue
+			}
+			r := forkedRoot{inst: inst, root: root}
+			for _, s := range []*structLit{root, inst.rootStruct, inst.scope} {
+				if s != nil && forwards[s] == nil {
+					forwards[s] = &structLit{}
+					r.shells = append(r.shells, s)
+				}
+			}
+			roots = append(roots, r)
+		}
+	}
+	for from, to := range forwards {
+		ctx.pushForwards(from, to)
+	}
+	for _, r := range roots {
+		for _, s := range r.shells {
+			*forwards[s] = *ctx.copy(s).(*structLit)
+		}
+		inst := *r.inst
+		inst.index = idx
+		inst.rootValue = forwards[r.root]
+		inst.rootStruct = forwards[r.inst.rootStruct]
+		if inst.scope != nil {
+			inst.scope = forwards[inst.scope]
+		}
+		idx.addInst(&inst)
+	}
+
+	root := v.path
+	for root.parent != nil {
+		root = root.parent
+	}
+	var x value
+	if s, ok := root.v.(*structLit); ok && forwards[s] != nil {
+		x = forwards[s]
+	} else {
+		x = ctx.copy(root.v)
+	}
+	w := newValueRoot(ctx, x)
+	if v.path.parent == nil {
+		return w
+	}
+	return w.LookupPath(v.Path())
+}
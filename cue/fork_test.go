@@ -0,0 +1,183 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const forkSchema = `
+import "example.com/pkg"
+
+Schema :: {
+	name: string
+	kind: pkg.Kind
+	port: *8080 | int
+	url:  "http://\(name):\(port)"
+	tags: [string]: string
+}
+`
+
+const forkPkg = `
+package pkg
+
+Kind :: "a" | "b"
+`
+
+func getForkSchema(t *testing.T) Value {
+	t.Helper()
+	insts := Build(makeInstances([]*bimport{
+		{files: []string{forkSchema}},
+		{path: "example.com/pkg", files: []string{forkPkg}},
+	}))
+	if err := insts[0].Err; err != nil {
+		t.Fatal(err)
+	}
+	return insts[0].Value().LookupPath(ParsePath("#Schema"))
+}
+
+type forkData struct {
+	Name string            `json:"name"`
+	Kind string            `json:"kind"`
+	Port int               `json:"port"`
+	URL  string            `json:"url"`
+	Tags map[string]string `json:"tags"`
+}
+
+func checkFork(schema Value, i int) error {
+	v := schema.Fork().Fill(map[string]interface{}{
+		"name": fmt.Sprintf("host%d", i),
+		"kind": "a",
+		"tags": map[string]string{fmt.Sprintf("tag%d", i): "x"},
+	})
+	if err := v.Validate(Concrete(true)); err != nil {
+		return err
+	}
+	var d forkData
+	if err := v.Decode(&d); err != nil {
+		return err
+	}
+	want := fmt.Sprintf("http://host%d:8080", i)
+	if d.URL != want {
+		return fmt.Errorf("got url %q; want %q", d.URL, want)
+	}
+
+	bad := schema.Fork().Fill(map[string]interface{}{"kind": "c"})
+	if err := bad.Validate(); err == nil {
+		return fmt.Errorf("expected error for invalid kind")
+	}
+	return nil
+}
+
+func TestFork(t *testing.T) {
+	schema := getForkSchema(t)
+	if err := checkFork(schema, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The original value must not be affected by filling a fork.
+	if got := fmt.Sprint(schema.Lookup("name")); got != "string" {
+		t.Errorf("original value modified: got %s; want string", got)
+	}
+	if p := schema.Fork().Path().String(); p != "#Schema" {
+		t.Errorf("got path %q; want #Schema", p)
+	}
+}
+
+// The following tests are intended to be run with the race detector.
+
+func TestConcurrentFork(t *testing.T) {
+	schema := getForkSchema(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = checkFork(schema, i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestConcurrentLabels(t *testing.T) {
+	var r Runtime
+	inst, err := r.Compile("test", `a: 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := inst.Value()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each fork creates new labels in the shared label table.
+			w := v.Fork()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("f%d", (i+j)%20)
+				w = w.FillPath(j, MakePath(Str(name)))
+				if got := w.Lookup(name); !got.Exists() {
+					t.Errorf("field %s not found", name)
+				}
+				_ = w.Path().String()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentBuiltins(t *testing.T) {
+	const src = `
+	import (
+		"list"
+		"strings"
+	)
+
+	a: strings.ToUpper("foo")
+	b: list.Sort([3, 1, 2], list.Ascending)
+	c: list.Comparer & {x: 1, y: 2, less: true}
+	`
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var r Runtime
+			inst, err := r.Compile("test", src)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			v := inst.Value()
+			if err := v.Validate(Concrete(true)); err != nil {
+				t.Error(err)
+			}
+			if got := fmt.Sprint(v.Lookup("a")); got != `"FOO"` {
+				t.Errorf("got %s; want \"FOO\"", got)
+			}
+		}()
+	}
+	wg.Wait()
+}
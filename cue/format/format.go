@@ -57,6 +57,52 @@ func TabIndent(indent bool) Option {
 	return func(c *config) { c.TabIndent = indent }
 }
 
+// SortFields sorts the fields of structs alphabetically by label, with
+// definitions before regular fields. Only runs of consecutive fields are
+// sorted: blank lines, comments that are not attached to a field, and other
+// declarations, such as embeddings, separate runs. Comments attached to a
+// field move with it. Fields with a label that is not a valid identifier or
+// string are not moved.
+func SortFields() Option {
+	return func(c *config) { c.sortFields = true }
+}
+
+// MaxWidth sets the preferred maximum line width. Lists and binary
+// expressions that are printed on a single line and that would exceed this
+// width are split over multiple lines. A width of 0 or less means there is
+// no maximum.
+func MaxWidth(width int) Option {
+	return func(c *config) { c.maxWidth = width }
+}
+
+// ExpandStructs causes structs with a single field that are the value of a
+// field to be printed with curly braces on separate lines, as in
+//
+//	a: {
+//		b: 1
+//	}
+//
+// It overrides the collapsing of such structs by Simplify.
+func ExpandStructs() Option {
+	return func(c *config) {
+		c.expandStructs = true
+		c.collapseStructs = false
+	}
+}
+
+// CollapseStructs causes structs with a single regular field that are the
+// value of a regular field to be printed without curly braces, as in
+//
+//	a: b: 1
+//
+// Structs with comments or attributes are not collapsed.
+func CollapseStructs() Option {
+	return func(c *config) {
+		c.collapseStructs = true
+		c.expandStructs = false
+	}
+}
+
 // TODO: make public
 // sortImportsOption causes import declarations to be sorted.
 func sortImportsOption() Option {
@@ -120,6 +166,11 @@ type config struct {
 
 	simplify    bool
 	sortImports bool
+
+	sortFields      bool
+	maxWidth        int
+	expandStructs   bool
+	collapseStructs bool
 }
 
 func newConfig(opt []Option) *config {
@@ -180,6 +231,13 @@ type formatter struct {
 	stack    []frame
 	current  frame
 	nestExpr int
+
+	// expandStruct is the single-field struct to be expanded next, if any.
+	expandStruct *ast.StructLit
+
+	// wrap holds the binary expressions after whose operator a line break
+	// is inserted to stay within the maximum line width.
+	wrap map[*ast.BinaryExpr]bool
 }
 
 func newFormatter(p *printer) *formatter {
@@ -283,7 +341,8 @@ func (f *formatter) before(node ast.Node) bool {
 
 	if node != nil {
 		s, ok := node.(*ast.StructLit)
-		if ok && len(s.Elts) <= 1 && f.current.nodeSep != blank && f.onOneLine(node) {
+		if ok && len(s.Elts) <= 1 && s != f.expandStruct &&
+			f.current.nodeSep != blank && f.onOneLine(node) {
 			f.current.nodeSep = blank
 		}
 		f.current.cg = node.Comments()
@@ -319,8 +378,10 @@ func (f *formatter) printComment(cg *ast.CommentGroup) {
 	f.Print(cg)
 
 	printBlank := false
-	if cg.Doc && len(f.output) > 0 {
-		f.Print(newline)
+	if cg.Doc {
+		if len(f.output) > 0 {
+			f.Print(newline)
+		}
 		printBlank = true
 	}
 	for _, c := range cg.List {
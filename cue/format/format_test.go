@@ -49,6 +49,10 @@ const (
 	idempotent
 	simplify
 	sortImps
+	sortFlds
+	maxWidth
+	expandStructs
+	collapseStructs
 )
 
 // format parses src, prints the corresponding AST, verifies the resulting
@@ -63,6 +67,18 @@ func format(src []byte, mode checkMode) ([]byte, error) {
 	if mode&sortImps != 0 {
 		opts = append(opts, sortImportsOption())
 	}
+	if mode&sortFlds != 0 {
+		opts = append(opts, SortFields())
+	}
+	if mode&maxWidth != 0 {
+		opts = append(opts, MaxWidth(40))
+	}
+	if mode&expandStructs != 0 {
+		opts = append(opts, ExpandStructs())
+	}
+	if mode&collapseStructs != 0 {
+		opts = append(opts, CollapseStructs())
+	}
 
 	res, err := Source(src, opts...)
 	if err != nil {
@@ -199,6 +215,10 @@ var data = []entry{
 	{"expressions.input", "expressions.golden", 0},
 	{"values.input", "values.golden", 0},
 	{"imports.input", "imports.golden", sortImps},
+	{"sort.input", "sort.golden", sortFlds | idempotent},
+	{"width.input", "width.golden", maxWidth | idempotent},
+	{"expand.input", "expand.golden", expandStructs | idempotent},
+	{"collapse.input", "collapse.golden", collapseStructs | idempotent},
 }
 
 func TestFiles(t *testing.T) {
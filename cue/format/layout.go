@@ -0,0 +1,237 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"sort"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// sortDecls returns a copy of list in which runs of consecutive fields are
+// sorted by label, definitions first. Comment groups that are not attached
+// to a field and other declarations separate runs.
+//
+// Within a run that spans multiple lines, fields with doc comments are
+// separated from other fields by a blank line and other fields are put on
+// consecutive lines. Fields are copied as needed to change their relative
+// position; list itself is not modified.
+func sortDecls(list []ast.Decl) []ast.Decl {
+	var sorted []ast.Decl
+	for i := 0; i < len(list); {
+		j := i + 1
+		if sortKey(list[i]) != nil {
+			for j < len(list) && sortKey(list[j]) != nil {
+				j++
+			}
+		}
+		if j-i < 2 {
+			sorted = append(sorted, list[i:j]...)
+			i = j
+			continue
+		}
+
+		multiline := false
+		for _, d := range list[i+1 : j] {
+			if leadPos(d.(*ast.Field)).RelPos() >= token.Newline {
+				multiline = true
+			}
+		}
+
+		run := append([]ast.Decl(nil), list[i:j]...)
+		sort.SliceStable(run, func(a, b int) bool {
+			ka, kb := sortKey(run[a]), sortKey(run[b])
+			if ka.def != kb.def {
+				return ka.def
+			}
+			return ka.name < kb.name
+		})
+		for k, d := range run {
+			x := d.(*ast.Field)
+			rel := leadPos(list[i+k].(*ast.Field)).RelPos()
+			switch {
+			case k == 0 || !multiline:
+			case fieldDoc(x) != nil || fieldDoc(run[k-1].(*ast.Field)) != nil:
+				rel = token.NewSection
+			default:
+				rel = token.Newline
+			}
+			run[k] = withRelPos(x, rel)
+		}
+		sorted = append(sorted, run...)
+		i = j
+	}
+	return sorted
+}
+
+type fieldKey struct {
+	def  bool
+	name string
+}
+
+// sortKey returns the key by which d is sorted, or nil if d is not a field
+// that can be sorted. Fields that cannot be sorted separate runs.
+func sortKey(d ast.Decl) *fieldKey {
+	x, ok := d.(*ast.Field)
+	if !ok || len(x.Label.Comments()) > 0 {
+		return nil
+	}
+	for _, cg := range x.Comments() {
+		if cg.Position > 0 && !cg.Line {
+			// A comment on its own line after a field, which the parser
+			// attaches to that field, separates the field from what follows.
+			return nil
+		}
+	}
+	label := x.Label
+	switch l := label.(type) {
+	case *ast.Alias:
+		label, _ = l.Expr.(ast.Label)
+	case *ast.Ident, *ast.BasicLit:
+	default:
+		return nil
+	}
+	name, _, err := ast.LabelName(label)
+	if err != nil {
+		return nil
+	}
+	return &fieldKey{def: x.Token == token.ISA, name: name}
+}
+
+// fieldDoc returns the doc comment of x, if any.
+func fieldDoc(x *ast.Field) *ast.CommentGroup {
+	for _, cg := range x.Comments() {
+		if cg.Position == 0 {
+			return cg
+		}
+	}
+	return nil
+}
+
+// leadPos returns the position of the first token printed for x, which is
+// that of its doc comment, if any, or its label.
+func leadPos(x *ast.Field) token.Pos {
+	if cg := fieldDoc(x); cg != nil {
+		return cg.Pos()
+	}
+	return x.Label.Pos()
+}
+
+// withRelPos returns x or a copy of x with the relative position of its
+// first token set to rel.
+func withRelPos(x *ast.Field, rel token.RelPos) *ast.Field {
+	if leadPos(x).RelPos() == rel {
+		return x
+	}
+	f := *x
+	cgs := x.Comments()
+	if len(cgs) > 0 && cgs[0].Position == 0 {
+		cgs = append([]*ast.CommentGroup(nil), cgs...)
+		cg := *cgs[0]
+		c := *cg.List[0]
+		c.Slash = c.Slash.WithRel(rel)
+		cg.List = append([]*ast.Comment{&c}, cg.List[1:]...)
+		cgs[0] = &cg
+		f.SetComments(cgs)
+		return &f
+	}
+	switch l := x.Label.(type) {
+	case *ast.Ident:
+		label := *l
+		label.NamePos = label.NamePos.WithRel(rel)
+		f.Label = &label
+	case *ast.BasicLit:
+		label := *l
+		label.ValuePos = label.ValuePos.WithRel(rel)
+		f.Label = &label
+	case *ast.Alias:
+		alias := *l
+		ident := *l.Ident
+		ident.NamePos = ident.NamePos.WithRel(rel)
+		alias.Ident = &ident
+		f.Label = &alias
+	}
+	return &f
+}
+
+func isSingleField(s *ast.StructLit) bool {
+	if len(s.Elts) != 1 {
+		return false
+	}
+	_, ok := s.Elts[0].(*ast.Field)
+	return ok
+}
+
+// fits reports whether x fits within the maximum line width when printed at
+// the current column. Expressions that already span multiple lines are
+// considered to fit.
+func (f *formatter) fits(x ast.Expr) bool {
+	if f.cfg.maxWidth <= 0 {
+		return true
+	}
+	cfg := *f.cfg
+	cfg.maxWidth = 0
+	b, err := cfg.fprint(x)
+	if err != nil || bytes.IndexByte(b, '\n') >= 0 {
+		return true
+	}
+	col := f.column()
+	if f.allowed&(blank|vtab) != 0 {
+		col++
+	}
+	return col+utf8.RuneCount(b) <= f.cfg.maxWidth
+}
+
+// column returns the width of the output written since the last line break.
+func (f *formatter) column() int {
+	out := f.output
+	if i := bytes.LastIndexAny(out, "\n\f"); i >= 0 {
+		out = out[i+1:]
+	}
+	n := 0
+	for _, r := range string(out) {
+		switch r {
+		case '\t':
+			n += f.cfg.Tabwidth
+		case tabwriter.Escape, utf8.RuneError:
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// wrapChain returns the binary expressions of the chain of operators of the
+// same precedence as that of x, not counting parenthesized subexpressions.
+func wrapChain(x *ast.BinaryExpr) map[*ast.BinaryExpr]bool {
+	m := map[*ast.BinaryExpr]bool{}
+	prec := x.Op.Precedence()
+	var walk func(e ast.Expr)
+	walk = func(e ast.Expr) {
+		b, ok := e.(*ast.BinaryExpr)
+		if !ok || b.Op.Precedence() != prec {
+			return
+		}
+		m[b] = true
+		walk(b.X)
+		walk(b.Y)
+	}
+	walk(x)
+	return m
+}
@@ -102,6 +102,9 @@ func hasDocComments(d ast.Decl) bool {
 }
 
 func (f *formatter) walkDeclList(list []ast.Decl) {
+	if f.cfg.sortFields {
+		list = sortDecls(list)
+	}
 	f.before(nil)
 	d := 0
 	hasEllipsis := false
@@ -184,10 +187,13 @@ func (f *formatter) walkClauseList(list []ast.Clause, ws whiteSpace) {
 	f.after(nil)
 }
 
-func (f *formatter) walkListElems(list []ast.Expr) {
+func (f *formatter) walkListElems(list []ast.Expr, wrap bool) {
 	f.before(nil)
 	for _, x := range list {
 		f.before(x)
+		if wrap {
+			f.print(newline, nooverride)
+		}
 		switch n := x.(type) {
 		case *ast.Comprehension:
 			f.walkClauseList(n.Clauses, blank)
@@ -232,17 +238,22 @@ func (f *formatter) file(file *ast.File) {
 }
 
 func (f *formatter) inlineField(n *ast.Field) *ast.Field {
+	cfg := f.printer.cfg
+	if cfg.expandStructs {
+		return nil
+	}
 	regular := isRegularField(n.Token)
+	collapse := cfg.collapseStructs && regular
 	// shortcut single-element structs.
 	// If the label has a valid position, we assume that an unspecified
 	// Lbrace signals the intend to collapse fields.
-	if !n.Label.Pos().IsValid() && !(f.printer.cfg.simplify && regular) {
+	if !n.Label.Pos().IsValid() && !(cfg.simplify && regular) && !collapse {
 		return nil
 	}
 
 	obj, ok := n.Value.(*ast.StructLit)
 	if !ok || len(obj.Elts) != 1 ||
-		(obj.Lbrace.IsValid() && !f.printer.cfg.simplify) ||
+		(obj.Lbrace.IsValid() && !cfg.simplify && !collapse) ||
 		len(n.Attrs) > 0 {
 		return nil
 	}
@@ -252,6 +263,10 @@ func (f *formatter) inlineField(n *ast.Field) *ast.Field {
 		return nil
 	}
 
+	if collapse && (!isRegularField(mem.Token) || len(obj.Comments()) > 0) {
+		return nil
+	}
+
 	if hasDocComments(mem) {
 		// TODO: this inserts curly braces even in spaces where this
 		// may not be desirable, such as:
@@ -288,7 +303,7 @@ func (f *formatter) decl(decl ast.Decl) {
 			default:
 				fallthrough
 
-			case regular && f.cfg.simplify:
+			case regular && (f.cfg.simplify || f.cfg.collapseStructs):
 				f.print(blank, nooverride)
 				f.decl(mem)
 
@@ -300,6 +315,12 @@ func (f *formatter) decl(decl ast.Decl) {
 			return
 		}
 
+		if f.cfg.expandStructs {
+			if s, ok := n.Value.(*ast.StructLit); ok && isSingleField(s) {
+				f.expandStruct = s
+			}
+		}
+
 		nextFF := f.nextNeedsFormfeed(n.Value)
 		tab := vtab
 		if nextFF {
@@ -397,7 +418,8 @@ func (f *formatter) embedding(decl ast.Expr) {
 		f.ellipsis(n)
 
 	case *ast.Alias:
-		if !decl.Pos().HasRelPos() || decl.Pos().RelPos() >= token.Newline {
+		if len(f.output) > 0 &&
+			(!decl.Pos().HasRelPos() || decl.Pos().RelPos() >= token.Newline) {
 			f.print(formfeed)
 		}
 		f.expr(n.Ident)
@@ -633,7 +655,8 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 				}
 				ff = ffAlt
 			}
-		case !x.Rbrace.HasRelPos() || !x.Elts[0].Pos().HasRelPos():
+		case !x.Rbrace.HasRelPos() || !x.Elts[0].Pos().HasRelPos(),
+			x == f.expandStruct:
 			ws |= newline | nooverride
 		}
 		f.print(x.Lbrace, token.LBRACE, &l, ws, ff, indent)
@@ -651,11 +674,15 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 		f.print(ws, x.Rbrace, token.RBRACE)
 
 	case *ast.ListLit:
+		wrap := len(x.Elts) > 0 && !f.fits(x)
 		f.print(x.Lbrack, token.LBRACK, indent)
-		f.walkListElems(x.Elts)
+		f.walkListElems(x.Elts, wrap)
 		f.print(trailcomma, noblank)
 		f.visitComments(f.current.pos)
 		f.matchUnindent()
+		if wrap {
+			f.print(newline, nooverride)
+		}
 		f.print(noblank, x.Rbrack, token.RBRACK)
 
 	case *ast.Ellipsis:
@@ -856,6 +883,11 @@ func (f *formatter) binaryExpr(x *ast.BinaryExpr, prec1, cutoff, depth int) {
 		return
 	}
 
+	if f.nestExpr == 1 && !f.fits(x) {
+		f.wrap = wrapChain(x)
+		defer func() { f.wrap = nil }()
+	}
+
 	printBlank := prec < cutoff
 
 	f.expr1(x.X, prec, depth+diffPrec(x.X, prec))
@@ -864,12 +896,16 @@ func (f *formatter) binaryExpr(x *ast.BinaryExpr, prec1, cutoff, depth int) {
 		f.print(blank)
 	}
 	f.print(x.OpPos, x.Op)
-	if x.Y.Pos().IsNewline() {
+	switch {
+	case x.Y.Pos().IsNewline():
 		// at least one line break, but respect an extra empty line
 		// in the source
 		f.print(formfeed)
 		printBlank = false // no blank after line break
-	} else {
+	case f.wrap[x]:
+		f.print(formfeed, nooverride)
+		printBlank = false
+	default:
 		f.print(nooverride)
 	}
 	if printBlank {
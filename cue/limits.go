@@ -0,0 +1,235 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	gocontext "context"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Limits bounds the resources spent on evaluating values of a Runtime. It
+// allows evaluating untrusted configurations, which may otherwise take an
+// unbounded amount of time.
+//
+// Limits apply to each evaluation separately, such as a single call to
+// Value.Validate or Value.LookupPath. Once a limit is exceeded, the remainder
+// of that evaluation results in an error. The code of this error, as reported
+// by errors.CodeOf, indicates which limit was exceeded. The results of such an
+// evaluation are discarded, so that repeating it fails in the same way, and
+// evaluating the same value without limits succeeds.
+type Limits struct {
+	// Context, if not nil, aborts evaluation with an error with code
+	// errors.Cancelled when it is done.
+	Context gocontext.Context
+
+	// MaxSteps is the maximum number of fields that may be evaluated. Each
+	// field counts only once, as results are cached. A limit exceeded
+	// results in an error with code errors.MaxStepsExceeded.
+	MaxSteps int
+
+	// MaxDisjuncts is the maximum number of disjuncts that may result from
+	// distributing operations over disjunctions, which is the primary cause
+	// of exponential evaluation times. A limit exceeded results in an error
+	// with code errors.MaxDisjunctsExceeded.
+	MaxDisjuncts int
+
+	// MaxDepth is the maximum depth of nested structures and of nested
+	// evaluations of references. A limit exceeded results in an error with
+	// code errors.MaxDepthExceeded.
+	MaxDepth int
+}
+
+// SetLimits sets the limits for evaluating values of r, including any Value
+// already created with r. A zero value for a limit means that it is not
+// enforced.
+//
+// SetLimits may be called concurrently with evaluation. Evaluations that are
+// already in progress continue to use the limits that were set when they
+// started.
+func (r *Runtime) SetLimits(l Limits) {
+	idx := r.index()
+	idx.limitsMutex.Lock()
+	defer idx.limitsMutex.Unlock()
+	if l == (Limits{}) {
+		idx.limitConfig = nil
+		return
+	}
+	idx.limitConfig = &l
+}
+
+// getLimits returns the limits currently set for idx, or nil if there are
+// none. The returned value must not be modified.
+func (idx *index) getLimits() *Limits {
+	if idx == nil {
+		return nil
+	}
+	idx.limitsMutex.RLock()
+	defer idx.limitsMutex.RUnlock()
+	return idx.limitConfig
+}
+
+// checkInterval is the number of steps between checks of the cancellation of
+// the context.
+const checkInterval = 64
+
+// evalLimits tracks the resources used by a single evaluation against their
+// limits.
+type evalLimits struct {
+	Limits
+
+	steps     int
+	disjuncts int
+
+	err errors.Error // first error encountered
+
+	// undo holds functions that clear the results cached by this evaluation.
+	// These results are cleared when a limit is exceeded, so that the
+	// evaluation leaves no partial results behind for later evaluations.
+	undo []func()
+}
+
+// newEvalLimits returns the tracker for a new evaluation with idx, or nil if
+// no limits are set.
+func (idx *index) newEvalLimits() *evalLimits {
+	l := idx.getLimits()
+	if l == nil {
+		return nil
+	}
+	return &evalLimits{Limits: *l}
+}
+
+// fail records err as the limit error if none was recorded before and
+// returns the recorded error. It clears the results cached so far.
+func (l *evalLimits) fail(err errors.Error) errors.Error {
+	if l.err == nil {
+		l.err = err
+		for i := len(l.undo) - 1; i >= 0; i-- {
+			l.undo[i]()
+		}
+		l.undo = nil
+	}
+	return l.err
+}
+
+// cached records that the current evaluation cached a result, which is
+// cleared by calling undo. It reports whether the result may be cached,
+// which is not the case once a limit has been exceeded.
+func (c *context) cached(undo func()) bool {
+	l := c.limits
+	if l == nil {
+		return true
+	}
+	if l.err != nil {
+		undo()
+		return false
+	}
+	l.undo = append(l.undo, undo)
+	return true
+}
+
+// limitErr reports the error for the first limit that was exceeded, if any.
+func (c *context) limitErr() errors.Error {
+	if c.limits == nil {
+		return nil
+	}
+	return c.limits.err
+}
+
+func limitErrorf(code errors.Code, format string, args ...interface{}) errors.Error {
+	return errors.WithCode(errors.Newf(token.NoPos, format, args...), code)
+}
+
+// step accounts for the evaluation of a single field at the given depth of
+// nested evaluations. It reports an error if a limit has been exceeded.
+func (c *context) step(depth int) errors.Error {
+	l := c.limits
+	if l == nil {
+		return nil
+	}
+	if l.err != nil {
+		return l.err
+	}
+	l.steps++
+	if l.MaxSteps > 0 && l.steps > l.MaxSteps {
+		return l.fail(limitErrorf(errors.MaxStepsExceeded,
+			"evaluation exceeded maximum of %d steps", l.MaxSteps))
+	}
+	if l.Context != nil && l.steps%checkInterval == 1 {
+		if err := l.Context.Err(); err != nil {
+			return l.fail(errors.WithCode(errors.Wrapf(err, token.NoPos,
+				"evaluation cancelled"), errors.Cancelled))
+		}
+	}
+	return c.checkDepth(depth)
+}
+
+// checkDepth reports an error if depth exceeds the maximum depth.
+func (c *context) checkDepth(depth int) errors.Error {
+	l := c.limits
+	if l == nil || l.MaxDepth <= 0 || depth <= l.MaxDepth {
+		return nil
+	}
+	return l.fail(limitErrorf(errors.MaxDepthExceeded,
+		"evaluation exceeded maximum depth of %d", l.MaxDepth))
+}
+
+// addDisjunct accounts for a single disjunct resulting from distributing an
+// operation over disjunctions. It reports an error if a limit has been
+// exceeded.
+func (c *context) addDisjunct() errors.Error {
+	l := c.limits
+	if l == nil {
+		return nil
+	}
+	if l.err != nil {
+		return l.err
+	}
+	l.disjuncts++
+	if l.MaxDisjuncts > 0 && l.disjuncts > l.MaxDisjuncts {
+		return l.fail(limitErrorf(errors.MaxDisjunctsExceeded,
+			"evaluation exceeded maximum of %d disjuncts", l.MaxDisjuncts))
+	}
+	return nil
+}
+
+// checkPathDepth reports an error if the value at p is nested deeper than
+// the maximum depth.
+func (idx *index) checkPathDepth(p *valueData) errors.Error {
+	l := idx.getLimits()
+	if l == nil || l.MaxDepth <= 0 {
+		return nil
+	}
+	depth := -1 // the root value does not count towards the depth
+	for ; p != nil && depth <= l.MaxDepth; p = p.parent {
+		depth++
+	}
+	if depth <= l.MaxDepth {
+		return nil
+	}
+	return limitErrorf(errors.MaxDepthExceeded,
+		"evaluation exceeded maximum depth of %d", l.MaxDepth)
+}
+
+// checkChild replaces the value of the child value d with an error if it is
+// nested deeper than the maximum depth.
+func (idx *index) checkChild(d *valueData) *valueData {
+	if err := idx.checkPathDepth(d); err != nil {
+		b := idx.mkErr(d.arc.v, codeFatal, err)
+		d.arc.v, d.arc.cache = b, b
+	}
+	return d
+}
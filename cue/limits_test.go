@@ -0,0 +1,185 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	gocontext "context"
+	"sync"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+)
+
+func TestLimits(t *testing.T) {
+	cancelled, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+
+	testCases := []struct {
+		desc   string
+		in     string
+		path   string
+		limits Limits
+		code   errors.Code
+	}{{
+		desc:   "within limits",
+		in:     `a: b: {c: 1 | 2, d: c + 1}`,
+		limits: Limits{MaxSteps: 100, MaxDisjuncts: 100, MaxDepth: 10},
+	}, {
+		desc: "steps",
+		in: `
+		a: 1, b: a + 1, c: b + 1, d: c + 1, e: d + 1, f: e + 1
+		`,
+		limits: Limits{MaxSteps: 3},
+		code:   errors.MaxStepsExceeded,
+	}, {
+		desc: "disjuncts",
+		in: `
+		a: (1|2|3|4) & (1|2|3|4) & (1|2|3|4) & (1|2|3|4) & (1|2|3|4)
+		`,
+		limits: Limits{MaxDisjuncts: 50},
+		code:   errors.MaxDisjunctsExceeded,
+	}, {
+		desc:   "depth of structure",
+		in:     `a: b: c: d: e: 1`,
+		limits: Limits{MaxDepth: 3},
+		code:   errors.MaxDepthExceeded,
+	}, {
+		desc:   "depth of lookup",
+		in:     `a: b: c: d: e: 1`,
+		path:   "a.b.c.d",
+		limits: Limits{MaxDepth: 3},
+		code:   errors.MaxDepthExceeded,
+	}, {
+		desc:   "cancelled",
+		in:     `a: 1, b: a + 1`,
+		limits: Limits{Context: cancelled},
+		code:   errors.Cancelled,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r Runtime
+			r.SetLimits(tc.limits)
+			inst, err := r.Compile("test", tc.in)
+			if err == nil {
+				v := inst.Value()
+				if tc.path != "" {
+					v = v.LookupPath(ParsePath(tc.path))
+					err = v.Err()
+				} else {
+					err = v.Validate()
+				}
+			}
+			if got := errors.CodeOf(err); got != tc.code {
+				t.Errorf("got code %q, want %q (error: %v)", got, tc.code, err)
+			}
+		})
+	}
+}
+
+func TestLimitsPerEvaluation(t *testing.T) {
+	var r Runtime
+	r.SetLimits(Limits{MaxSteps: 4})
+	inst, err := r.Compile("test", `a: 1, b: a + 1, c: b + 1, d: c + 1, e: d + 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = inst.Value().Validate()
+	if got := errors.CodeOf(err); got != errors.MaxStepsExceeded {
+		t.Errorf("got code %q, want %q (error: %v)", got, errors.MaxStepsExceeded, err)
+	}
+
+	// Exceeding a limit does not affect later evaluations.
+	inst, err = r.Compile("test", `x: 1, y: x + 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.Value().Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLimitsRevalidate(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		in     string
+		limits Limits
+		code   errors.Code
+	}{{
+		desc: "steps",
+		in: `
+		a: 1, b: a + 1, c: b + 1, d: c + 1, e: d + 1, f: e + 1
+		`,
+		limits: Limits{MaxSteps: 3},
+		code:   errors.MaxStepsExceeded,
+	}, {
+		desc: "disjuncts",
+		in: `
+		a: (1|2|3|4) & (1|2|3|4) & (1|2|3|4) & (1|2|3|4) & (1|2|3|4)
+		b: a
+		`,
+		limits: Limits{MaxDisjuncts: 50},
+		code:   errors.MaxDisjunctsExceeded,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r Runtime
+			r.SetLimits(tc.limits)
+			inst, err := r.Compile("test", tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := inst.Value()
+
+			// Failed evaluations leave no results behind that a later
+			// evaluation could build on.
+			for i := 0; i < 2; i++ {
+				err := v.Validate()
+				if got := errors.CodeOf(err); got != tc.code {
+					t.Errorf("%d: got code %q, want %q (error: %v)", i, got, tc.code, err)
+				}
+			}
+
+			r.SetLimits(Limits{})
+			if err := v.Validate(); err != nil {
+				t.Errorf("unexpected error after removing limits: %v", err)
+			}
+			if err := v.Lookup("a").Err(); err != nil {
+				t.Errorf("unexpected error for a after removing limits: %v", err)
+			}
+		})
+	}
+}
+
+func TestSetLimitsConcurrently(t *testing.T) {
+	var r Runtime
+	inst, err := r.Compile("test", `a: 1, b: a + 1, c: b + 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := inst.Value()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = v.Fork().Validate()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		r.SetLimits(Limits{MaxSteps: 100 + i})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// archiveVersion must be incremented whenever the computation of archive
+// keys or the layout of the cache directory changes.
+const archiveVersion = 2
+
+const (
+	archiveSuffix  = ".cuearchive"
+	manifestSuffix = ".manifest"
+)
+
+// DefaultCacheDir returns the default directory for storing package archives.
+// See Config.CacheDir.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cue", "archive"), nil
+}
+
+// loadDep resolves the imported package for path, memoizing the result so
+// that the package can be used both to compute the archive key of its
+// importer and to complete the importer.
+func (l *loader) loadDep(pos token.Pos, path string) *build.Instance {
+	if p, ok := l.deps[path]; ok {
+		return p
+	}
+	if l.loadingDeps[path] {
+		// An import cycle. The package is loaded again, as it would be without
+		// archives, to report the cycle.
+		return nil
+	}
+	l.loadingDeps[path] = true
+	p := l.loadFunc()(pos, path)
+	delete(l.loadingDeps, path)
+	l.deps[path] = p
+	return p
+}
+
+// archiveKey computes the key for the archive of p, which must be an imported
+// package for which all files have been processed. It returns "" if p cannot
+// be archived.
+func (l *loader) archiveKey(pos token.Pos, p *build.Instance) string {
+	version := cueVersion()
+	if version == "" {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "cue archive %d\n%s\n%s\n%s\n",
+		archiveVersion, version, p.ImportPath, p.PkgName)
+
+	sum := l.sourcesHash(p)
+	if sum == "" {
+		return ""
+	}
+	fmt.Fprintf(h, "sources %s\n", sum)
+
+	for _, path := range p.ImportPaths {
+		if strings.IndexByte(strings.Split(path, "/")[0], '.') == -1 &&
+			l.cfg.StdRoot == "" {
+			continue // builtin package; covered by the CUE version.
+		}
+		dep := l.loadDep(pos, path)
+		if dep == nil || dep.Err != nil {
+			return ""
+		}
+		key := l.archiveKeys[dep]
+		if key == "" {
+			return ""
+		}
+		fmt.Fprintf(h, "import %s %s\n", path, key)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// manifestDelay is the minimum age of a file for its size and modification
+// time to be recorded in a manifest. A younger file could still be modified
+// without changing either.
+const manifestDelay = 2 * time.Second
+
+// sourcesHash returns a hash of the contents of the CUE files of p. It returns
+// "" if p has files of other encodings or if its files cannot be read.
+//
+// Reading the files of unchanged packages is avoided by recording the hash
+// in a manifest in the cache directory, along with the sizes and modification
+// times of the files.
+func (l *loader) sourcesHash(p *build.Instance) string {
+	files := append([]*build.File(nil), p.BuildFiles...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+
+	fs := &l.cfg.fileSystem
+	stats := &bytes.Buffer{}
+	manifest := l.manifestPath(p)
+	for _, f := range files {
+		if f.Encoding != build.CUE {
+			return ""
+		}
+		if f.Source != nil || fs.getOverlay(fs.makeAbs(f.Filename)) != nil {
+			manifest = ""
+			continue
+		}
+		fi, err := fs.stat(f.Filename)
+		if err != nil || time.Since(fi.ModTime()) < manifestDelay {
+			manifest = ""
+			continue
+		}
+		fmt.Fprintf(stats, "file %s %d %d\n",
+			f.Filename, fi.Size(), fi.ModTime().UnixNano())
+	}
+	if manifest != "" {
+		b, err := ioutil.ReadFile(manifest)
+		if err == nil && bytes.HasPrefix(b, stats.Bytes()) {
+			var sum string
+			rest := string(b[stats.Len():])
+			if n, _ := fmt.Sscanf(rest, "sum %s\n", &sum); n == 1 &&
+				rest == "sum "+sum+"\n" {
+				return sum
+			}
+		}
+	}
+
+	h := sha256.New()
+	for _, f := range files {
+		if f.Source == nil {
+			r, err := fs.openFile(f.Filename)
+			if err != nil {
+				return ""
+			}
+			b, rerr := ioutil.ReadAll(r)
+			r.Close()
+			if rerr != nil {
+				return ""
+			}
+			// Avoid reading the file again when it is parsed.
+			f.Source = b
+		}
+		b, ok := f.Source.([]byte)
+		if !ok {
+			return ""
+		}
+		fmt.Fprintf(h, "file %s %d\n", f.Filename, len(b))
+		_, _ = h.Write(b)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if manifest != "" {
+		fmt.Fprintf(stats, "sum %s\n", sum)
+		writeCacheFile(manifest, stats.Bytes())
+	}
+	return sum
+}
+
+// manifestPath returns the path of the manifest for the sources of p.
+func (l *loader) manifestPath(p *build.Instance) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "cue manifest %d\n%s\n%s\n", archiveVersion, p.ImportPath, p.Dir)
+	key := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(l.cfg.CacheDir, key[:2], key+manifestSuffix)
+}
+
+func (l *loader) archivePath(key string) string {
+	return filepath.Join(l.cfg.CacheDir, key[:2], key+archiveSuffix)
+}
+
+// loadArchive adds the files of the archive with the given key to p. It
+// reports whether such an archive exists and could be read. The names of the
+// files are relative to the archive.
+func (l *loader) loadArchive(p *build.Instance, key string) bool {
+	filename := l.archivePath(key)
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+	files, err := internal.ArchiveFiles(b, filename)
+	if err != nil || len(files) == 0 {
+		return false
+	}
+	for _, f := range files {
+		_ = p.AddSyntax(f)
+	}
+	return true
+}
+
+// storeArchive compiles p and writes it to the cache directory.
+// Failures are ignored: the package is then loaded from source again the next
+// time.
+func (l *loader) storeArchive(p *build.Instance, key string) {
+	var r cue.Runtime
+	inst, err := r.Build(p)
+	if err != nil {
+		return
+	}
+	b, err := r.Marshal(inst)
+	if err != nil {
+		return
+	}
+
+	writeCacheFile(l.archivePath(key), b)
+}
+
+// writeCacheFile writes b to filename. Failures are ignored.
+func writeCacheFile(filename string, b []byte) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so that concurrent loaders never
+	// observe a partially written file.
+	tmp, err := ioutil.TempFile(dir, filepath.Base(filename)+".tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+// cueVersion returns a string identifying the version of the CUE
+// implementation. For development builds, which do not have a proper version,
+// it identifies the running executable instead. It returns "" if no version
+// could be determined.
+func cueVersion() string {
+	versionOnce.Do(func() {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			mod := &bi.Main
+			for _, m := range bi.Deps {
+				if m.Path == "cuelang.org/go" {
+					mod = m
+				}
+			}
+			if mod.Replace != nil {
+				mod = mod.Replace
+			}
+			if mod.Path == "cuelang.org/go" && mod.Version != "(devel)" {
+				version = mod.Version + " " + mod.Sum
+			}
+		}
+		if version == "" {
+			if id := executableID(); id != "" {
+				version = "exe " + id
+			}
+		}
+	})
+	return version
+}
+
+func executableID() string {
+	filename, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
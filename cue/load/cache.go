@@ -0,0 +1,134 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue/ast"
+)
+
+// A FileCache holds parsed CUE files so that they can be reused across calls
+// to Instances within the same process. An entry is reused as long as the
+// modification time and size of the file on disk are unchanged.
+//
+// Each load obtains its own copy of a cached syntax tree, so instances loaded
+// with the same FileCache may be modified independently. A FileCache is safe
+// for concurrent use.
+type FileCache struct {
+	mu    sync.Mutex
+	files map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	file    *ast.File
+}
+
+// NewFileCache returns a new, empty FileCache.
+func NewFileCache() *FileCache {
+	return &FileCache{files: map[string]*cacheEntry{}}
+}
+
+func (c *FileCache) get(filename string, modTime time.Time, size int64) *ast.File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.files[filename]
+	if e == nil || !e.modTime.Equal(modTime) || e.size != size {
+		return nil
+	}
+	return copyFile(e.file)
+}
+
+func (c *FileCache) put(filename string, modTime time.Time, size int64, f *ast.File) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.files[filename] = &cacheEntry{modTime: modTime, size: size, file: copyFile(f)}
+}
+
+// copyFile returns a deep copy of f. References between nodes of f, such as
+// the Node and Scope fields of resolved identifiers, refer to the
+// corresponding nodes of the copy.
+func copyFile(f *ast.File) *ast.File {
+	c := &copier{nodes: map[interface{}]reflect.Value{}}
+	return c.copy(reflect.ValueOf(f)).Interface().(*ast.File)
+}
+
+type copier struct {
+	nodes map[interface{}]reflect.Value
+}
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if w, ok := c.nodes[v.Interface()]; ok {
+			return w
+		}
+		w := reflect.New(v.Type().Elem())
+		c.nodes[v.Interface()] = w
+		c.copyStruct(w.Elem(), v.Elem())
+		if n, ok := w.Interface().(ast.Node); ok {
+			cgs := ast.Comments(v.Interface().(ast.Node))
+			if len(cgs) > 0 {
+				a := make([]*ast.CommentGroup, len(cgs))
+				for i, cg := range cgs {
+					a[i] = c.copy(reflect.ValueOf(cg)).Interface().(*ast.CommentGroup)
+				}
+				ast.SetComments(n, a)
+			}
+		}
+		return w
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		w := reflect.New(v.Type()).Elem()
+		w.Set(c.copy(v.Elem()))
+		return w
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		w := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			w.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return w
+	}
+	// Values that do not refer to other nodes, like strings and positions.
+	return v
+}
+
+// copyStruct copies the exported fields of v to w. Unexported fields only
+// hold the comments of a node, which are copied separately.
+func (c *copier) copyStruct(w, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			continue
+		}
+		w.Field(i).Set(c.copy(v.Field(i)))
+	}
+}
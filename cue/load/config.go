@@ -189,6 +189,26 @@ type Config struct {
 	// the syntax tree.
 	ParseFile func(name string, src interface{}) (*ast.File, error)
 
+	// Cache, if non-nil, is used to reuse parsed CUE files across calls to
+	// Instances. Cache is ignored if ParseFile is set.
+	Cache *FileCache
+
+	// CacheDir, if non-empty, specifies a directory in which archives of
+	// imported packages are stored, using cue.Runtime.Marshal. An imported
+	// package is loaded from its archive instead of from source if its
+	// sources, the archives of its imports, and the version of CUE are
+	// unchanged.
+	//
+	// The sources of a package are only read to detect changes if their sizes
+	// or modification times differ from when they were last read. Loading an
+	// archive replaces parsing the sources of a package with parsing the
+	// single file of the archive, which holds the merged declarations of the
+	// package. The declarations still need to be compiled.
+	//
+	// Positions of archived packages refer to the file within the archive,
+	// which is named after the archive, instead of to the original sources.
+	CacheDir string
+
 	// Overlay provides a mapping of absolute file paths to file contents.
 	// If the file  with the given path already exists, the parser will use the
 	// alternative file contents provided by the map.
@@ -436,7 +456,12 @@ func (c Config) complete() (cfg *Config, err error) {
 		}
 	}
 
-	c.loader = &loader{cfg: &c}
+	c.loader = &loader{
+		cfg:         &c,
+		deps:        map[string]*build.Instance{},
+		loadingDeps: map[string]bool{},
+		archiveKeys: map[*build.Instance]string{},
+	}
 
 	// TODO: also make this work if run from outside the module?
 	switch {
@@ -49,6 +49,10 @@ const (
 	importComment
 
 	allowAnonymous
+
+	// If useArchive is set, the package may be loaded from and stored to
+	// the archives in Config.CacheDir.
+	useArchive
 )
 
 // importPkg returns details about the CUE package named by the import path,
@@ -66,20 +70,33 @@ const (
 // If an error occurs, importPkg sets the error in the returned instance,
 // which then may contain partial information.
 //
-func (l *loader) importPkg(pos token.Pos, p *build.Instance) *build.Instance {
+func (l *loader) importPkg(pos token.Pos, p *build.Instance, mode importMode) *build.Instance {
 	l.stk.Push(p.ImportPath)
 	defer l.stk.Pop()
 
+	if l.findFiles(pos, p) {
+		l.buildPkg(pos, p, mode)
+	}
+	return p
+}
+
+// findFiles determines the files that make up p and the packages imported
+// by them. It reports whether this succeeded; if not, the errors are recorded
+// in p.
+//
+// findFiles only reads the configuration and may be called concurrently for
+// different packages.
+func (l *loader) findFiles(pos token.Pos, p *build.Instance) bool {
 	cfg := l.cfg
 	ctxt := &cfg.fileSystem
 
 	if p.Err != nil {
-		return p
+		return false
 	}
 
 	if !strings.HasPrefix(p.Dir, cfg.ModuleRoot) {
 		p.Err = errors.Newf(token.NoPos, "module root not defined", p.DisplayPath)
-		return p
+		return false
 	}
 
 	fp := newFileProcessor(cfg, p)
@@ -104,7 +121,7 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) *build.Instance {
 				if err != nil {
 					// should not happen
 					p.Err = errors.Wrapf(err, token.NoPos, "invalid path")
-					return p
+					return false
 				}
 				base := filepath.Join(cfg.ModuleRoot, modDir, sub)
 				dir := filepath.Join(base, rel)
@@ -126,7 +143,7 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) *build.Instance {
 
 	if !found {
 		p.Err = errors.Newf(token.NoPos, "cannot find package %q", p.DisplayPath)
-		return p
+		return false
 	}
 
 	// This algorithm assumes that multiple directories within cue.mod/*/
@@ -144,7 +161,7 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) *build.Instance {
 			files, err := ctxt.readDir(dir)
 			if err != nil && !os.IsNotExist(err) {
 				p.ReportError(errors.Wrapf(err, pos, "import failed reading dir %v", dirs[0][1]))
-				return p
+				return false
 			}
 			for _, f := range files {
 				if f.IsDir() {
@@ -189,12 +206,36 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) *build.Instance {
 		for _, e := range errors.Errors(errs) {
 			p.ReportError(e)
 		}
-		return p
+		return false
 	}
+	return true
+}
 
-	l.addFiles(cfg.ModuleRoot, p)
-	p.Complete()
-	return p
+// buildPkg adds the syntax of the files found by findFiles to p, loading
+// them from an archive if possible, and completes p.
+func (l *loader) buildPkg(pos token.Pos, p *build.Instance, mode importMode) {
+	cfg := l.cfg
+
+	for _, path := range p.ImportPaths {
+		l.prefetch(pos, path)
+	}
+
+	key := ""
+	if mode&useArchive != 0 && cfg.CacheDir != "" {
+		key = l.archiveKey(pos, p)
+	}
+	if key != "" && l.loadArchive(p, key) {
+		p.Complete()
+	} else {
+		l.addFiles(cfg.ModuleRoot, p)
+		p.Complete()
+		if key != "" && p.Err == nil {
+			l.storeArchive(p, key)
+		}
+	}
+	if key != "" && p.Err == nil {
+		l.archiveKeys[p] = key
+	}
 }
 
 // loadFunc creates a LoadFunc that can be used to create new build.Instances.
@@ -203,6 +244,10 @@ func (l *loader) loadFunc() build.LoadFunc {
 	return func(pos token.Pos, path string) *build.Instance {
 		cfg := l.cfg
 
+		if cfg.CacheDir != "" && l.deps[path] != nil {
+			return l.deps[path]
+		}
+
 		impPath := importPath(path)
 		if isLocalImport(path) {
 			return cfg.newErrInstance(pos, impPath,
@@ -213,13 +258,13 @@ func (l *loader) loadFunc() build.LoadFunc {
 		if strings.IndexByte(strings.Split(path, "/")[0], '.') == -1 {
 			if l.cfg.StdRoot != "" {
 				p := cfg.newInstance(pos, impPath)
-				return l.importPkg(pos, p)
+				return l.importPkg(pos, p, useArchive)
 			}
 			return nil
 		}
 
 		p := cfg.newInstance(pos, impPath)
-		return l.importPkg(pos, p)
+		return l.importPkg(pos, p, useArchive)
 	}
 }
 
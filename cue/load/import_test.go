@@ -31,7 +31,7 @@ func getInst(pkg, cwd string) (*build.Instance, error) {
 	c, _ := (&Config{Dir: cwd}).complete()
 	l := loader{cfg: c}
 	inst := c.newRelInstance(token.NoPos, pkg, c.Package)
-	p := l.importPkg(token.NoPos, inst)
+	p := l.importPkg(token.NoPos, inst, 0)
 	return p, p.Err
 }
 
@@ -22,9 +22,12 @@ package load
 import (
 	pathpkg "path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"unicode"
 
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
@@ -49,6 +52,7 @@ func Instances(args []string, c *Config) []*build.Instance {
 	c = newC
 
 	l := c.loader
+	defer l.wait()
 
 	// TODO: require packages to be placed before files. At some point this
 	// could be relaxed.
@@ -97,6 +101,18 @@ const (
 type loader struct {
 	cfg *Config
 	stk importStack
+
+	// Used for archives. See Config.CacheDir.
+	deps        map[string]*build.Instance
+	loadingDeps map[string]bool
+	archiveKeys map[*build.Instance]string
+
+	// Used for loading files and packages in parallel.
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+	sem        chan struct{}
+	parsing    map[string]*parseJob // keyed by filename
+	prefetched map[string]bool      // keyed by import path
 }
 
 func (l *loader) abs(filename string) string {
@@ -161,6 +177,9 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 	// 	bp.ImportPath = ModDirImportPath(dir)
 	// }
 
+	for _, path := range pkg.ImportPaths {
+		l.prefetch(pos, path)
+	}
 	l.addFiles(cfg.Dir, pkg)
 
 	pkg.User = true
@@ -174,22 +193,207 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 	return pkg
 }
 
+// parallelism is the maximum number of files that are parsed, or packages
+// whose files are determined, concurrently by a single loader.
+var parallelism = runtime.GOMAXPROCS(0)
+
+// decodeResult holds the syntax trees decoded from a single build file.
+type decodeResult struct {
+	files []*ast.File
+	err   errors.Error
+}
+
+// A parseJob decodes a single file in the background.
+type parseJob struct {
+	done chan struct{}
+	r    decodeResult
+}
+
+// initParallel initializes the fields used for loading in parallel. It must be
+// called with l.mu held.
+func (l *loader) initParallel() {
+	if l.sem == nil {
+		l.sem = make(chan struct{}, parallelism)
+		l.parsing = map[string]*parseJob{}
+		l.prefetched = map[string]bool{}
+	}
+}
+
+// goLimited runs f in a new goroutine, making sure that no more than
+// parallelism of such functions are running at the same time. initParallel
+// must have been called.
+func (l *loader) goLimited(f func()) {
+	sem := l.sem
+	l.wg.Add(1)
+	go func() {
+		sem <- struct{}{}
+		defer func() { <-sem; l.wg.Done() }()
+		f()
+	}()
+}
+
+// wait waits for all work started in the background to finish.
+func (l *loader) wait() {
+	l.wg.Wait()
+}
+
+// startParse starts decoding f in the background, unless f needs to be
+// interpreted or is already being decoded.
+func (l *loader) startParse(f *build.File) {
+	if !l.canParseConcurrently(f) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.initParallel()
+	if l.parsing[f.Filename] != nil {
+		return
+	}
+	j := &parseJob{done: make(chan struct{})}
+	l.parsing[f.Filename] = j
+	l.goLimited(func() {
+		defer close(j.done)
+		j.r = l.decodeFile(f)
+	})
+}
+
+// parse returns the result of decoding f, waiting for a decoding started by
+// startParse, if any, to finish. The result of a decoding is only handed out
+// once, so that the syntax trees of different instances are never shared.
+func (l *loader) parse(f *build.File) decodeResult {
+	l.mu.Lock()
+	j := l.parsing[f.Filename]
+	delete(l.parsing, f.Filename)
+	l.mu.Unlock()
+
+	if j == nil {
+		return l.decodeFile(f)
+	}
+	<-j.done
+	return j.r
+}
+
+// prefetch starts determining and parsing the files of the package with the
+// given import path in the background, and recursively those of the packages
+// it imports, so that they are ready by the time the package is loaded to
+// complete an importing package.
+func (l *loader) prefetch(pos token.Pos, path string) {
+	cfg := l.cfg
+	if cfg.CacheDir != "" || isLocalImport(path) {
+		// Imported packages are loaded from archives or cannot be imported.
+		return
+	}
+	if strings.IndexByte(strings.Split(path, "/")[0], '.') == -1 && cfg.StdRoot == "" {
+		return // builtin package
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.initParallel()
+	if l.prefetched[path] {
+		return
+	}
+	l.prefetched[path] = true
+	l.goLimited(func() {
+		p := cfg.newInstance(pos, importPath(path))
+		if !l.findFiles(pos, p) {
+			return
+		}
+		for _, f := range p.BuildFiles {
+			l.startParse(f)
+		}
+		for _, path := range p.ImportPaths {
+			l.prefetch(pos, path)
+		}
+	})
+}
+
+// findAllFiles calls findFiles for each of the given packages concurrently
+// and reports for each package whether it succeeded.
+func (l *loader) findAllFiles(pos token.Pos, a []*build.Instance) []bool {
+	l.mu.Lock()
+	l.initParallel()
+	l.mu.Unlock()
+
+	found := make([]bool, len(a))
+	var wg sync.WaitGroup
+	for i, p := range a {
+		wg.Add(1)
+		i, p := i, p
+		l.goLimited(func() {
+			defer wg.Done()
+			if found[i] = l.findFiles(pos, p); found[i] {
+				for _, f := range p.BuildFiles {
+					l.startParse(f)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return found
+}
+
 func (l *loader) addFiles(dir string, p *build.Instance) {
+	// Start parsing all plain CUE files concurrently. Other files may need
+	// to be interpreted using a cue.Runtime and are decoded in order below.
 	for _, f := range p.BuildFiles {
-		d := encoding.NewDecoder(f, &encoding.Config{
-			Stdin:     l.cfg.stdin(),
-			ParseFile: l.cfg.ParseFile,
-		})
-		for ; !d.Done(); d.Next() {
-			_ = p.AddSyntax(d.File())
+		l.startParse(f)
+	}
+
+	// Add the results in file order to keep the resulting instance and the
+	// order of errors deterministic.
+	for _, f := range p.BuildFiles {
+		r := l.parse(f)
+		for _, file := range r.files {
+			_ = p.AddSyntax(file)
 		}
-		if err := d.Err(); err != nil {
-			p.ReportError(errors.Promote(err, "load"))
+		if r.err != nil {
+			p.ReportError(r.err)
 		}
-		d.Close()
 	}
 }
 
+func (l *loader) canParseConcurrently(f *build.File) bool {
+	return f.Encoding == build.CUE && f.Interpretation == "" && f.Filename != "-"
+}
+
+// decodeFile decodes all syntax trees from f, using the file cache of the
+// configuration, if any, for plain CUE files.
+func (l *loader) decodeFile(f *build.File) (r decodeResult) {
+	cache := l.cfg.Cache
+	if cache != nil && l.cfg.ParseFile == nil && l.canParseConcurrently(f) {
+		if _, ok := f.Source.(*ast.File); !ok {
+			fi, err := l.cfg.fileSystem.stat(f.Filename)
+			if err == nil {
+				if file := cache.get(f.Filename, fi.ModTime(), fi.Size()); file != nil {
+					f.Source = file
+				} else {
+					defer func() {
+						if r.err == nil && len(r.files) == 1 {
+							cache.put(f.Filename, fi.ModTime(), fi.Size(), r.files[0])
+						}
+					}()
+				}
+			}
+		}
+	}
+
+	d := encoding.NewDecoder(f, &encoding.Config{
+		Stdin:     l.cfg.stdin(),
+		ParseFile: l.cfg.ParseFile,
+	})
+	defer d.Close()
+	for ; !d.Done(); d.Next() {
+		r.files = append(r.files, d.File())
+	}
+	if err := d.Err(); err != nil {
+		r.err = errors.Promote(err, "load")
+	}
+	return r
+}
+
 func cleanImport(path string) string {
 	orig := path
 	path = pathpkg.Clean(path)
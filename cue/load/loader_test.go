@@ -16,17 +16,21 @@ package load
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/kylelemons/godebug/diff"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/str"
 )
@@ -351,3 +355,170 @@ func TestOverlays(t *testing.T) {
 		}
 	}
 }
+
+func TestFileCache(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewFileCache()
+	load := func() []*build.Instance {
+		return Instances([]string{"./hello", "./imports"}, &Config{
+			Dir:   filepath.Join(cwd, testdata),
+			Cache: cache,
+		})
+	}
+	first := load()
+
+	// Mark the cached syntax trees to detect whether they are reused.
+	marks := map[string]bool{}
+	for _, e := range cache.files {
+		e.file.Decls = append(e.file.Decls, &ast.EmbedDecl{Expr: ast.NewIdent("cached")})
+		marks[e.file.Filename] = true
+	}
+	if len(marks) == 0 {
+		t.Fatal("no files were cached")
+	}
+
+	files := func(a []*build.Instance) map[string]*ast.File {
+		m := map[string]*ast.File{}
+		var add func(a []*build.Instance)
+		add = func(a []*build.Instance) {
+			for _, inst := range a {
+				if inst.Err != nil {
+					t.Fatal(inst.Err)
+				}
+				for _, f := range inst.Files {
+					m[f.Filename] = f
+				}
+				add(inst.Imports)
+			}
+		}
+		add(a)
+		return m
+	}
+	before := files(first)
+	for name, f := range files(load()) {
+		if !marks[name] {
+			continue
+		}
+		delete(marks, name)
+		if f == before[name] {
+			t.Errorf("syntax tree of file %s is shared", name)
+		}
+		var id *ast.Ident
+		if last, ok := f.Decls[len(f.Decls)-1].(*ast.EmbedDecl); ok {
+			id, _ = last.Expr.(*ast.Ident)
+		}
+		if id == nil || id.Name != "cached" {
+			t.Errorf("file %s was parsed again", name)
+		}
+	}
+	for name := range marks {
+		t.Errorf("cached file %s was not loaded", name)
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cue-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	cacheDir := filepath.Join(tmp, "cache")
+	modDir := filepath.Join(tmp, "mod")
+
+	// Files are dated back so that their modification times are recorded in
+	// the manifest.
+	mtime := time.Now().Add(-time.Hour)
+	writeFile := func(name, content string) {
+		filename := filepath.Join(modDir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		mtime = mtime.Add(time.Second)
+	}
+	writeFile("cue.mod/module.cue", `module: "example.org/test"`)
+	writeFile("test.cue", `package test
+
+import "example.org/test/sub"
+
+out: "Hello \(sub.User)!"
+`)
+	writeFile("sub/sub.cue", "package sub\n\nUser: \"sub\"\n")
+
+	testCases := []struct {
+		name    string
+		edit    string // new contents of sub/sub.cue
+		archive bool
+		want    string
+	}{{
+		name: "source",
+		want: `packagetestout:"Hellosub!"`,
+	}, {
+		name:    "archive",
+		archive: true,
+		want:    `packagetestout:"Hellosub!"`,
+	}, {
+		name: "edited",
+		edit: "package sub\n\nUser: \"edited\"\n",
+		want: `packagetestout:"Helloedited!"`,
+	}, {
+		name:    "edited archive",
+		archive: true,
+		want:    `packagetestout:"Helloedited!"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.edit != "" {
+				writeFile("sub/sub.cue", tc.edit)
+			}
+			a := Instances([]string{"."}, &Config{
+				Dir:      modDir,
+				CacheDir: cacheDir,
+			})
+			if len(a) != 1 || a[0].Err != nil {
+				t.Fatalf("unexpected load result: %v", a)
+			}
+			p := a[0]
+			if len(p.Imports) != 1 {
+				t.Fatalf("got %d imports; want 1", len(p.Imports))
+			}
+			dep := p.Imports[0]
+			isArchive := strings.HasPrefix(dep.Files[0].Filename, cacheDir)
+			if isArchive != tc.archive {
+				t.Errorf("dependency loaded from %s", dep.Files[0].Filename)
+			}
+			for _, f := range dep.BuildFiles {
+				if read := f.Source != nil; read == tc.archive {
+					t.Errorf("%s: read is %v; want %v", f.Filename, read, !tc.archive)
+				}
+			}
+
+			inst := cue.Build([]*build.Instance{p})[0]
+			if inst.Err != nil {
+				t.Fatal(inst.Err)
+			}
+			b, err := format.Node(inst.Value().Syntax())
+			if err != nil {
+				t.Fatal(err)
+			}
+			rmSpace := func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}
+			if got := string(bytes.Map(rmSpace, b)); got != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -162,6 +162,7 @@ func (l *loader) matchPackagesInFS(pattern, pkgName string) *match {
 	// TODO(legacy): remove
 	pkgDir2 := filepath.Join(root, "pkg")
 
+	var insts []*build.Instance
 	_ = c.fileSystem.walk(root, func(path string, fi os.FileInfo, err errors.Error) errors.Error {
 		if err != nil || !fi.IsDir() {
 			return nil
@@ -204,8 +205,19 @@ func (l *loader) matchPackagesInFS(pattern, pkgName string) *match {
 			dir = "./" + dir
 		}
 		// TODO: consider not doing these checks here.
-		inst := c.newRelInstance(token.NoPos, dir, pkgName)
-		p := l.importPkg(token.NoPos, inst)
+		insts = append(insts, c.newRelInstance(token.NoPos, dir, pkgName))
+		return nil
+	})
+
+	// Determine the files of all packages in parallel before building them
+	// in order.
+	found := l.findAllFiles(token.NoPos, insts)
+	for i, p := range insts {
+		if found[i] {
+			l.stk.Push(p.ImportPath)
+			l.buildPkg(token.NoPos, p, 0)
+			l.stk.Pop()
+		}
 		if err := p.Err; err != nil && (p == nil || len(p.InvalidCUEFiles) == 0) {
 			switch err.(type) {
 			case nil:
@@ -214,15 +226,14 @@ func (l *loader) matchPackagesInFS(pattern, pkgName string) *match {
 				if c.DataFiles && len(p.OrphanedFiles) > 0 {
 					break
 				}
-				return nil
+				continue
 			default:
 				m.Err = err
 			}
 		}
 
 		m.Pkgs = append(m.Pkgs, p)
-		return nil
-	})
+	}
 	return m
 }
 
@@ -365,7 +376,7 @@ func (l *loader) importPathsQuiet(patterns []string) []*match {
 			p = l.cfg.newInstance(token.NoPos, importPath(orig))
 		}
 
-		pkg := l.importPkg(token.NoPos, p)
+		pkg := l.importPkg(token.NoPos, p, 0)
 		out = append(out, &match{Pattern: a, Literal: true, Pkgs: []*build.Instance{pkg}})
 	}
 	return out
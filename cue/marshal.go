@@ -24,7 +24,9 @@ import (
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
 )
 
 // root.
@@ -91,9 +93,41 @@ func compileInstances(r *Runtime, data []*instanceData) (instances []*Instance,
 	return r.build(builds)
 }
 
+func init() {
+	internal.ArchiveFiles = func(b []byte, dir string) ([]*ast.File, error) {
+		data, err := decodeInstances(b)
+		if err != nil {
+			return nil, err
+		}
+		files := []*ast.File{}
+		for _, i := range data {
+			if !i.Root {
+				continue
+			}
+			for _, f := range i.Files {
+				name := filepath.Join(dir, filepath.FromSlash(f.Name))
+				file, err := parser.ParseFile(name, f.Data, parser.ParseComments)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, file)
+			}
+		}
+		return files, nil
+	}
+}
+
 // Unmarshal creates an Instance from bytes generated by the MarshalBinary
 // method of an instance.
 func (r *Runtime) Unmarshal(b []byte) ([]*Instance, error) {
+	data, err := decodeInstances(b)
+	if err != nil {
+		return nil, err
+	}
+	return compileInstances(r, data)
+}
+
+func decodeInstances(b []byte) ([]*instanceData, error) {
 	if len(b) == 0 {
 		return nil, errors.Newf(token.NoPos, "unmarshal failed: empty buffer")
 	}
@@ -115,8 +149,7 @@ func (r *Runtime) Unmarshal(b []byte) ([]*Instance, error) {
 	if err != nil {
 		return nil, errors.Newf(token.NoPos, "unmarshal failed: %v", err)
 	}
-
-	return compileInstances(r, data)
+	return data, nil
 }
 
 // Marshal creates bytes from a group of instances. Imported instances will
@@ -66,6 +66,15 @@ var (
 		p.mode |= allErrorsMode
 	}
 
+	// RecoverErrors causes the parser to recover from syntax errors at field
+	// and struct boundaries, so that the parts of a broken file that are valid
+	// are still returned with their positions. Erroneous parts are represented
+	// by ast.BadExpr and ast.BadDecl nodes. It implies AllErrors.
+	RecoverErrors Option = recoverErrors
+	recoverErrors        = func(p *parser) {
+		p.mode |= recoverMode | allErrorsMode
+	}
+
 	// AllowPartial allows the parser to be used on a prefix buffer.
 	AllowPartial Option = allowPartial
 	allowPartial        = func(p *parser) {
@@ -123,6 +132,7 @@ const (
 	traceMode             // print a trace of parsed productions
 	declarationErrorsMode // report declaration errors
 	allErrorsMode         // report all errors (not just the first 10 on different lines)
+	recoverMode           // recover from errors at field and struct boundaries
 )
 
 // ParseFile parses the source code of a single CUE source file and returns
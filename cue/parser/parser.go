@@ -55,6 +55,9 @@ type parser struct {
 	syncPos token.Pos // last synchronization position
 	syncCnt int       // number of calls to syncXXX without progress
 
+	// closing tokens of the enclosing structs, lists, and parentheses
+	closers []token.Token
+
 	// Non-syntactic parser control
 	exprLev int // < 0: in control clause, >= 0: in expression
 
@@ -75,7 +78,8 @@ func (p *parser) init(filename string, src []byte, mode []Option) {
 		m = scanner.ScanComments
 	}
 	eh := func(pos token.Pos, msg string, args []interface{}) {
-		p.errors = errors.Append(p.errors, errors.Newf(pos, msg, args...))
+		err := errors.WithCode(errors.Newf(pos, msg, args...), errors.SyntaxError)
+		p.errors = errors.Append(p.errors, err)
 	}
 	p.scanner.Init(p.file, src, eh, m)
 
@@ -398,7 +402,8 @@ func (p *parser) errf(pos token.Pos, msg string, args ...interface{}) {
 		}
 	}
 
-	p.errors = errors.Append(p.errors, errors.Newf(ePos, msg, args...))
+	err := errors.WithCode(errors.Newf(ePos, msg, args...), errors.SyntaxError)
+	p.errors = errors.Append(p.errors, err)
 }
 
 func (p *parser) errorExpected(pos token.Pos, obj string) {
@@ -424,6 +429,11 @@ func (p *parser) expect(tok token.Token) token.Pos {
 	pos := p.pos
 	if p.tok != tok {
 		p.errorExpected(pos, "'"+tok.String()+"'")
+		if p.mode&recoverMode != 0 && (p.atEnclosingClose() || p.atImplicitEnd()) {
+			// Assume tok is missing and leave the current token, which
+			// may be an implicit comma, to the enclosing construct.
+			return token.NoPos
+		}
 	}
 	p.next() // make progress
 	return pos
@@ -452,8 +462,14 @@ func (p *parser) expectComma() {
 	}
 }
 
-func (p *parser) atComma(context string, follow ...token.Token) bool {
+// skipComma skips the comma separating two elements and reports whether
+// another element may follow. A missing comma is reported and assumed to be
+// present, unless the current token is one of follow. If the current token
+// cannot start an element, it is skipped or, in recovery mode, the parser
+// skips to the next element.
+func (p *parser) skipComma(context string, follow ...token.Token) bool {
 	if p.tok == token.COMMA {
+		p.next()
 		return true
 	}
 	for _, t := range follow {
@@ -467,15 +483,64 @@ func (p *parser) atComma(context string, follow ...token.Token) bool {
 	} else {
 		p.errf(p.pos, "missing ',' in %s", context)
 	}
-	return true // "insert" comma and continue
+	switch {
+	case startsElement(p.tok):
+		// "insert" comma and continue with the current token.
+	case p.mode&recoverMode != 0:
+		// Skip to the next element, if any.
+		syncExpr(p)
+		if p.tok != token.COMMA {
+			return false
+		}
+		p.next()
+	default:
+		p.next() // make progress
+	}
+	return true
+}
+
+// startsElement reports whether tok may start an element of a struct, list,
+// or argument list, in which case only the separating comma is missing.
+func startsElement(tok token.Token) bool {
+	switch tok {
+	case token.IDENT, token.INT, token.FLOAT, token.STRING,
+		token.INTERPOLATION, token.BOTTOM, token.TRUE, token.FALSE,
+		token.NULL, token.LPAREN, token.LBRACK, token.LBRACE,
+		token.ADD, token.SUB, token.MUL, token.NOT, token.LSS, token.LEQ,
+		token.GTR, token.GEQ, token.NEQ, token.MAT, token.NMAT,
+		token.FOR, token.IF:
+		return true
+	}
+	return false
 }
 
 // syncExpr advances to the next field in a field list.
 // Used for synchronization after an error.
+//
+// In recovery mode, syncExpr skips over bracketed expressions and stops at
+// a token closing an enclosing construct.
 func syncExpr(p *parser) {
+	depth := 0
 	for {
 		switch p.tok {
+		case token.LBRACE, token.LBRACK, token.LPAREN:
+			if p.mode&recoverMode != 0 {
+				depth++
+			}
+
+		case token.RBRACE, token.RBRACK, token.RPAREN:
+			if p.mode&recoverMode != 0 {
+				if depth > 0 {
+					depth--
+				} else if p.atEnclosingClose() {
+					return
+				}
+			}
+
 		case token.COMMA:
+			if depth > 0 {
+				break
+			}
 			// Return only if parser made some progress since last
 			// sync or if it has not reached 10 sync calls without
 			// progress. Otherwise consume at least one token to
@@ -487,7 +552,9 @@ func syncExpr(p *parser) {
 				p.syncCnt++
 				return
 			}
-			if p.syncPos.Before(p.pos) {
+			if p.syncPos.Before(p.pos) ||
+				// The first synchronization always makes progress.
+				p.mode&recoverMode != 0 && p.syncPos == token.NoPos {
 				p.syncPos = p.pos
 				p.syncCnt = 0
 				return
@@ -504,6 +571,53 @@ func syncExpr(p *parser) {
 	}
 }
 
+// openBrackets records that the construct being parsed is closed by tok.
+func (p *parser) openBrackets(tok token.Token) {
+	p.closers = append(p.closers, tok)
+}
+
+func (p *parser) closeBrackets() {
+	p.closers = p.closers[:len(p.closers)-1]
+}
+
+// atEnclosingClose reports whether the current token is EOF or closes an
+// enclosing struct, list, or parenthesized expression.
+func (p *parser) atEnclosingClose() bool {
+	if p.tok == token.EOF {
+		return true
+	}
+	for _, t := range p.closers {
+		if p.tok == t {
+			return true
+		}
+	}
+	return false
+}
+
+// atEnd reports whether the current token ends a list of elements closed by
+// tok. In recovery mode, a token closing any enclosing construct ends the
+// list as well.
+func (p *parser) atEnd(tok token.Token) bool {
+	if p.mode&recoverMode != 0 {
+		return p.atEnclosingClose()
+	}
+	return p.tok == tok || p.tok == token.EOF
+}
+
+// atImplicitEnd reports whether the current token is EOF or a comma inserted
+// by the scanner at the end of a line or the file.
+func (p *parser) atImplicitEnd() bool {
+	return p.tok == token.EOF || p.tok == token.COMMA && p.lit != ","
+}
+
+func isClosing(tok token.Token) bool {
+	switch tok {
+	case token.RBRACE, token.RBRACK, token.RPAREN:
+		return true
+	}
+	return false
+}
+
 // safePos returns a valid file position for a given position: If pos
 // is valid to begin with, safePos returns pos. If pos is out-of-range,
 // safePos returns the EOF position.
@@ -534,7 +648,7 @@ func (p *parser) parseIdent() *ast.Ident {
 		name = p.lit
 		p.next()
 	} else {
-		p.expect(token.IDENT) // use expect() error handling
+		pos = p.expect(token.IDENT) // use expect() error handling
 	}
 	ident := &ast.Ident{NamePos: pos, Name: name}
 	c.closeNode(p, ident)
@@ -593,7 +707,9 @@ func (p *parser) parseOperand() (expr ast.Expr) {
 		p.next()
 		p.exprLev++
 		p.openList()
+		p.openBrackets(token.RPAREN)
 		x := p.parseRHS() // types may be parenthesized: (some type)
+		p.closeBrackets()
 		p.closeList()
 		p.exprLev--
 		rparen := p.expect(token.RPAREN)
@@ -629,6 +745,7 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) (expr ast.Expr) {
 	lbrack := p.expect(token.LBRACK)
 
 	p.exprLev++
+	p.openBrackets(token.RBRACK)
 	var index [N]ast.Expr
 	var colons [N - 1]token.Pos
 	if p.tok != token.COLON {
@@ -643,6 +760,7 @@ func (p *parser) parseIndexOrSlice(x ast.Expr) (expr ast.Expr) {
 			index[nColons] = p.parseRHS()
 		}
 	}
+	p.closeBrackets()
 	p.exprLev--
 	rbrack := p.expect(token.RBRACK)
 
@@ -671,14 +789,15 @@ func (p *parser) parseCallOrConversion(fun ast.Expr) (expr *ast.CallExpr) {
 
 	lparen := p.expect(token.LPAREN)
 	p.exprLev++
+	p.openBrackets(token.RPAREN)
 	var list []ast.Expr
-	for p.tok != token.RPAREN && p.tok != token.EOF {
+	for !p.atEnd(token.RPAREN) {
 		list = append(list, p.parseRHS()) // builtins may expect a type: make(some type, ...)
-		if !p.atComma("argument list", token.RPAREN) {
+		if !p.skipComma("argument list", token.RPAREN) {
 			break
 		}
-		p.next()
 	}
+	p.closeBrackets()
 	p.exprLev--
 	rparen := p.expectClosing(token.RPAREN, "argument list")
 
@@ -696,16 +815,26 @@ func (p *parser) parseFieldList() (list []ast.Decl) {
 	p.openList()
 	defer p.closeList()
 
-	for p.tok != token.RBRACE && p.tok != token.ELLIPSIS && p.tok != token.EOF {
+	for p.tok != token.ELLIPSIS && !p.atEnd(token.RBRACE) {
+		if p.mode&recoverMode != 0 && isClosing(p.tok) {
+			// The token does not close any enclosing construct.
+			pos := p.pos
+			p.errf(pos, "unexpected '%s'", p.tok)
+			p.next()
+			list = append(list, &ast.BadDecl{From: pos, To: p.pos})
+			if p.tok == token.COMMA {
+				p.next()
+			}
+			continue
+		}
+
 		switch p.tok {
 		case token.FOR, token.IF:
 			list = append(list, p.parseComprehension())
 
 		case token.ATTRIBUTE:
 			list = append(list, p.parseAttribute())
-			if p.atComma("struct literal", token.RBRACE) { // TODO: may be EOF
-				p.next()
-			}
+			p.skipComma("struct literal", token.RBRACE) // TODO: may be EOF
 
 		default:
 			list = append(list, p.parseField())
@@ -778,9 +907,7 @@ func (p *parser) parseComprehension() (decl ast.Decl) {
 		}
 
 		fc.closeNode(p, decl)
-		if p.atComma("struct literal", token.RBRACE, token.EOF) {
-			p.next()
-		}
+		p.skipComma("struct literal", token.RBRACE, token.EOF)
 
 		return decl
 	}
@@ -789,9 +916,7 @@ func (p *parser) parseComprehension() (decl ast.Decl) {
 	expr := p.parseStruct()
 	sc.closeExpr(p, expr)
 
-	if p.atComma("struct literal", token.RBRACE) { // TODO: may be EOF
-		p.next()
-	}
+	p.skipComma("struct literal", token.RBRACE) // TODO: may be EOF
 
 	return &ast.Comprehension{
 		Clauses: clauses,
@@ -827,15 +952,11 @@ func (p *parser) parseField() (decl ast.Decl) {
 					p.errorExpected(p.pos, "label or ':'")
 					return &ast.BadDecl{From: pos, To: p.pos}
 				}
-				if p.atComma("struct literal", token.RBRACE) {
-					p.next()
-				}
+				p.skipComma("struct literal", token.RBRACE)
 				return a
 			}
 			e := &ast.EmbedDecl{Expr: expr}
-			if p.atComma("struct literal", token.RBRACE) {
-				p.next()
-			}
+			p.skipComma("struct literal", token.RBRACE)
 			return e
 		}
 
@@ -929,9 +1050,7 @@ func (p *parser) parseField() (decl ast.Decl) {
 		m.Attrs = attrs
 	}
 
-	if p.atComma("struct literal", token.RBRACE) { // TODO: may be EOF
-		p.next()
-	}
+	p.skipComma("struct literal", token.RBRACE) // TODO: may be EOF
 
 	return this
 }
@@ -948,9 +1067,7 @@ func (p *parser) parseAttributes() (attrs []*ast.Attribute) {
 func (p *parser) parseAttributeDecls() (a []ast.Decl) {
 	for p.tok == token.ATTRIBUTE {
 		a = append(a, p.parseAttribute())
-		if p.atComma("struct literal", token.RBRACE) { // TODO: may be EOF
-			p.next()
-		}
+		p.skipComma("struct literal", token.RBRACE) // TODO: may be EOF
 	}
 	return a
 }
@@ -1022,7 +1139,13 @@ func (p *parser) parseStruct() (expr ast.Expr) {
 		defer un(trace(p, "StructLit"))
 	}
 
+	if p.mode&recoverMode != 0 && lbrace == token.NoPos {
+		return &ast.BadExpr{From: p.pos, To: p.pos}
+	}
+
+	p.openBrackets(token.RBRACE)
 	elts := p.parseStructBody()
+	p.closeBrackets()
 	rbrace := p.expectClosing(token.RBRACE, "struct literal")
 	return &ast.StructLit{
 		Lbrace: lbrace,
@@ -1126,6 +1249,7 @@ func (p *parser) parseList() (expr ast.Expr) {
 		defer un(trace(p, "ListLiteral"))
 	}
 
+	p.openBrackets(token.RBRACK)
 	elts := p.parseListElements()
 
 	if clauses, _ := p.parseComprehensionClauses(false); clauses != nil {
@@ -1137,6 +1261,7 @@ func (p *parser) parseList() (expr ast.Expr) {
 		if len(elts) > 0 {
 			expr = elts[0]
 		}
+		p.closeBrackets()
 		rbrack := p.expectClosing(token.RBRACK, "list comprehension")
 
 		return &ast.ListComprehension{
@@ -1156,11 +1281,10 @@ func (p *parser) parseList() (expr ast.Expr) {
 		if p.tok != token.COMMA && p.tok != token.RBRACK {
 			ellipsis.Type = p.parseRHS()
 		}
-		if p.atComma("list literal", token.RBRACK) {
-			p.next()
-		}
+		p.skipComma("list literal", token.RBRACK)
 	}
 
+	p.closeBrackets()
 	rbrack := p.expectClosing(token.RBRACK, "list literal")
 	return &ast.ListLit{
 		Lbrack: lbrack,
@@ -1175,7 +1299,7 @@ func (p *parser) parseListElements() (list []ast.Expr) {
 	p.openList()
 	defer p.closeList()
 
-	for p.tok != token.RBRACK && p.tok != token.ELLIPSIS && p.tok != token.EOF {
+	for p.tok != token.ELLIPSIS && !p.atEnd(token.RBRACK) {
 		expr, ok := p.parseListElement()
 		list = append(list, expr)
 		if !ok {
@@ -1209,9 +1333,7 @@ func (p *parser) parseListElement() (expr ast.Expr, ok bool) {
 			expr := p.parseStruct()
 			sc.closeExpr(p, expr)
 
-			if p.atComma("struct literal", token.RBRACK) { // TODO: may be EOF
-				p.next()
-			}
+			p.skipComma("struct literal", token.RBRACK) // TODO: may be EOF
 
 			return &ast.Comprehension{
 				Clauses: clauses,
@@ -1243,10 +1365,13 @@ func (p *parser) parseListElement() (expr ast.Expr, ok bool) {
 			return expr, false
 		}
 		p.errf(p.pos, "missing ',' before newline in list literal")
-	} else if !p.atComma("list literal", token.RBRACK, token.FOR, token.IF) {
+		if p.mode&recoverMode != 0 {
+			// Take the newline as the separator.
+			return expr, !p.atEnclosingClose()
+		}
+	} else if !p.skipComma("list literal", token.RBRACK, token.FOR, token.IF) {
 		return expr, false
 	}
-	p.next()
 
 	return expr, true
 }
@@ -1334,7 +1459,11 @@ L:
 			default:
 				pos := p.pos
 				p.errorExpected(pos, "selector")
-				p.next() // make progress
+				if p.mode&recoverMode != 0 && (p.atEnclosingClose() || p.atImplicitEnd()) {
+					pos = token.NoPos // leave the closing token
+				} else {
+					p.next() // make progress
+				}
 				x = &ast.SelectorExpr{X: x, Sel: &ast.Ident{NamePos: pos, Name: "_"}}
 			}
 			c.closeNode(p, x)
@@ -1446,6 +1575,9 @@ func (p *parser) parseInterpolation() (expr ast.Expr) {
 		cc = p.openComments()
 		if p.tok != token.RPAREN {
 			p.errf(p.pos, "expected ')' for string interpolation")
+			if p.mode&recoverMode != 0 && p.atImplicitEnd() {
+				break
+			}
 		}
 		lit = p.scanner.ResumeInterpolation()
 		pos = p.pos
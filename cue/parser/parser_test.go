@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
 )
 
 func TestParse(t *testing.T) {
@@ -661,6 +662,112 @@ func TestIncompleteSelection(t *testing.T) {
 	}
 }
 
+func TestRecoverErrors(t *testing.T) {
+	testCases := []struct{ desc, in, out string }{{
+		desc: "missing operand",
+		in: `
+		a: 1
+		g: {x: 1 +}
+		h: 4
+		`,
+		out: `a: 1, g: {x: 1+<*ast.BadExpr>}, h: 4
+input:3:13: expected operand, found '}'`,
+	}, {
+		desc: "missing closing bracket",
+		in: `
+		b: {
+			c: [1, 2
+		}
+		h: 4
+		`,
+		out: `b: {c: [1, 2]}, h: 4
+input:4:3: expected ']', found '}'`,
+	}, {
+		desc: "missing closing brace",
+		in: `
+		b: {
+			c: 3
+		h: 4
+		`,
+		out: `b: {c: 3, h: 4}
+input:5:3: expected '}', found 'EOF'`,
+	}, {
+		desc: "stray closing tokens",
+		in: `
+		a: 1
+		b: { c: 3 )
+		}
+		i: )
+		]
+		j: 5
+		`,
+		out: `a: 1, b: {c: 3}, i: <*ast.BadExpr>, <*ast.BadDecl>, j: 5
+input:3:13: missing ',' in struct literal
+input:5:6: expected operand, found ')'
+input:6:3: unexpected ']'`,
+	}, {
+		desc: "unclosed call",
+		in: `
+		a: [ len(x ]
+		b: 2
+		`,
+		out: `a: [len(x)], b: 2
+input:2:14: expected ')', found ']'`,
+	}, {
+		desc: "incomplete selector",
+		in: `
+		a: {
+			b: c.
+		}
+		d: 1
+		`,
+		out: `a: {b: c._}, d: 1
+input:4:3: expected selector, found '}'`,
+	}, {
+		desc: "unclosed parenthesis",
+		in: `
+		a: (1 + 2
+		b: 2
+		`,
+		out: `a: (1+2), b: 2
+input:2:12: expected ')', found newline`,
+	}, {
+		desc: "missing commas between fields",
+		in:   `a: 1 b: 2 c: 3`,
+		out: `a: 1, b: 2, c: 3
+input:1:6: missing ',' in struct literal`,
+	}, {
+		desc: "missing comma in struct",
+		in:   `a: {b: 1 c: 2}`,
+		out: `a: {b: 1, c: 2}
+input:1:10: missing ',' in struct literal`,
+	}, {
+		desc: "missing commas in list",
+		in:   `a: [1 2 3]`,
+		out: `a: [1, 2, 3]
+input:1:7: missing ',' in list literal`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := ParseFile("input", tc.in, RecoverErrors)
+			got := debugStr(f)
+			for _, e := range errors.Errors(err) {
+				got += fmt.Sprintf("\n%s: %s", e.Position(), e.Error())
+			}
+			if got != tc.out {
+				t.Errorf("\ngot  %s;\nwant %s", got, tc.out)
+			}
+
+			ast.Walk(f, func(n ast.Node) bool {
+				if end := n.End(); end.IsValid() && end.Offset() > len(tc.in) {
+					t.Errorf("end position of %s beyond end of file", debugStr(n))
+				}
+				return true
+			}, nil)
+		})
+	}
+}
+
 // For debugging, do not delete.
 func TestX(t *testing.T) {
 	t.Skip()
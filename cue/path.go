@@ -0,0 +1,406 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+// A Selector is a component of a path.
+type Selector struct {
+	kind  selectorKind
+	name  string
+	index int
+}
+
+type selectorKind int
+
+const (
+	stringSelector selectorKind = iota
+	definitionSelector
+	hiddenSelector
+	indexSelector
+)
+
+// Str returns a Selector for a regular field with the given name. The name
+// may be any string, including strings that are not valid identifiers.
+func Str(name string) Selector {
+	return Selector{kind: stringSelector, name: name}
+}
+
+// Def returns a Selector for the definition with the given name.
+func Def(name string) Selector {
+	return Selector{kind: definitionSelector, name: name}
+}
+
+// Hid returns a Selector for the hidden field with the given name. The name
+// must start with an underscore.
+func Hid(name string) Selector {
+	if !strings.HasPrefix(name, "_") {
+		panic("cue: hidden field name must start with an underscore")
+	}
+	return Selector{kind: hiddenSelector, name: name}
+}
+
+// Index returns a Selector for the list element at index x.
+func Index(x int) Selector {
+	return Selector{kind: indexSelector, index: x}
+}
+
+// String reports the CUE representation of sel. Definitions are prefixed
+// with a '#' to distinguish them from regular fields.
+func (sel Selector) String() string {
+	switch sel.kind {
+	case definitionSelector:
+		return "#" + sel.name
+	case hiddenSelector:
+		return sel.name
+	case indexSelector:
+		return "[" + strconv.Itoa(sel.index) + "]"
+	}
+	if isIdent(sel.name) && !strings.HasPrefix(sel.name, "_") || isNumber(sel.name) {
+		return sel.name
+	}
+	return quote(sel.name, '"')
+}
+
+func (sel Selector) isField() bool {
+	return sel.kind != indexSelector
+}
+
+func (sel Selector) label(idx *index) label {
+	return idx.label(sel.name, sel.kind == hiddenSelector)
+}
+
+// A Path is a sequence of selectors that addresses a value relative to
+// another value.
+type Path struct {
+	path []Selector
+	err  errors.Error
+}
+
+// MakePath creates a Path from a sequence of selectors.
+func MakePath(selectors ...Selector) Path {
+	return Path{path: append([]Selector(nil), selectors...)}
+}
+
+// ParsePath parses a CUE path, such as
+//
+//	a.b[3]."quoted label".#D._hidden
+//
+// Fields are separated by dots and may be identifiers or quoted strings.
+// List elements are selected with an index between square brackets.
+// Definitions are prefixed with a '#' and hidden fields start with an
+// underscore.
+//
+// If the path cannot be parsed, the error is reported by the Err method of
+// the returned Path.
+func ParsePath(s string) Path {
+	p := Path{}
+	if s == "" {
+		return p
+	}
+	errf := func(format string, args ...interface{}) Path {
+		msg := "invalid path %q: " + format
+		return Path{err: errors.Newf(token.NoPos, msg, append([]interface{}{s}, args...)...)}
+	}
+
+	rest := s
+	for afterDot := false; ; afterDot = true {
+		if afterDot || !strings.HasPrefix(rest, "[") {
+			switch {
+			case rest == "":
+				return errf("missing selector")
+
+			case rest[0] == '"':
+				n := quotedLen(rest)
+				if n < 0 {
+					return errf("unterminated string")
+				}
+				name, err := literal.Unquote(rest[:n])
+				if err != nil {
+					return errf("%v", err)
+				}
+				p.path = append(p.path, Str(name))
+				rest = rest[n:]
+
+			default:
+				def := rest[0] == '#'
+				if def {
+					rest = rest[1:]
+				}
+				n := identLen(rest)
+				if n == 0 && !def {
+					n = len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+				}
+				if n == 0 {
+					return errf("expected identifier")
+				}
+				name := rest[:n]
+				rest = rest[n:]
+				switch {
+				case def:
+					p.path = append(p.path, Def(name))
+				case name[0] == '_':
+					p.path = append(p.path, Hid(name))
+				default:
+					p.path = append(p.path, Str(name))
+				}
+			}
+		}
+
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return errf("missing ']'")
+			}
+			x, err := strconv.Atoi(rest[1:end])
+			if err != nil || x < 0 || rest[1] == '+' {
+				return errf("invalid index %q", rest[1:end])
+			}
+			p.path = append(p.path, Index(x))
+			rest = rest[end+1:]
+		}
+
+		if rest == "" {
+			break
+		}
+		if rest[0] != '.' {
+			return errf("unexpected %q", rest[:1])
+		}
+		rest = rest[1:]
+	}
+	return p
+}
+
+// quotedLen returns the length of the double-quoted string at the start of
+// s, or -1 if it is not terminated.
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// identLen returns the length of the identifier at the start of s.
+func identLen(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if r != '_' && !unicode.IsLetter(r) && (n == 0 || !unicode.IsDigit(r)) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// Selectors reports the individual selectors of a path.
+func (p Path) Selectors() []Selector {
+	return p.path
+}
+
+// Err reports errors that occurred when generating the path.
+func (p Path) Err() error {
+	if p.err == nil {
+		return nil
+	}
+	return p.err
+}
+
+// String reports the CUE representation of p.
+func (p Path) String() string {
+	if err := p.Err(); err != nil {
+		return "_|_"
+	}
+	b := &strings.Builder{}
+	for i, sel := range p.path {
+		if i > 0 && sel.isField() {
+			b.WriteByte('.')
+		}
+		b.WriteString(sel.String())
+	}
+	return b.String()
+}
+
+// Path returns the path to this value from the root of its instance.
+func (v Value) Path() Path {
+	if v.path == nil {
+		return Path{}
+	}
+	a, _ := v.path.appendSelectors(nil, v.idx)
+	return Path{path: a}
+}
+
+// appendSelectors is like appendPath, but reports selectors.
+func (v *valueData) appendSelectors(a []Selector, idx *index) ([]Selector, kind) {
+	var k kind
+	if v.parent != nil {
+		a, k = v.parent.appendSelectors(a, idx)
+	}
+	switch k {
+	case listKind:
+		a = append(a, Index(int(v.index)))
+	case structKind:
+		f := v.arc.feature
+		name := idx.labelStr(f)
+		switch {
+		case f&hidden != 0:
+			a = append(a, Hid(name))
+		case v.arc.definition:
+			a = append(a, Def(name))
+		default:
+			a = append(a, Str(name))
+		}
+	}
+	return a, v.arc.cache.kind()
+}
+
+// LookupPath reports the value for path p relative to v. Unlike Lookup,
+// LookupPath can be used to select list elements, definitions, hidden fields
+// and optional fields.
+//
+// Use the Exists method of the returned value to verify that the value
+// existed. The Err method of the returned value reports which selector of
+// the path could not be resolved.
+func (v Value) LookupPath(p Path) Value {
+	if v.path == nil {
+		return v
+	}
+	ctx := v.ctx()
+	if p.err != nil {
+		return newErrValue(v, ctx.mkErr(v.path.v, p.err))
+	}
+
+	for i, sel := range p.path {
+		prefix := Path{path: p.path[:i+1]}
+
+		if sel.kind == indexSelector {
+			v, _ = v.Default()
+			if err := v.checkKind(ctx, listKind); err != nil {
+				return newErrValue(v, ctx.mkErr(v.path.v, err, codeNotExist,
+					"%s: cannot select index from %v value", prefix, v.Kind()))
+			}
+			l := v.eval(ctx).(*list)
+			if sel.index >= len(l.elem.arcs) {
+				return newErrValue(v, ctx.mkErr(v.path.v, codeNotExist,
+					"%s: index out of range (list has %d elements)",
+					prefix, len(l.elem.arcs)))
+			}
+			v = v.makeChild(ctx, uint32(sel.index), l.iterAt(ctx, sel.index))
+			continue
+		}
+
+		obj, err := v.structValOpts(ctx, options{})
+		if err != nil {
+			return newErrValue(v, ctx.mkErr(v.path.v, err, codeNotExist,
+				"%s: cannot select field from %v value", prefix, v.Kind()))
+		}
+		f := sel.label(ctx.index)
+		found := false
+		for j, a := range obj.arcs {
+			if a.feature != f || a.definition != (sel.kind == definitionSelector) {
+				continue
+			}
+			v = newChildValue(&obj, j)
+			found = true
+			break
+		}
+		if !found {
+			what := "field"
+			if sel.kind == definitionSelector {
+				what = "definition"
+			}
+			return newErrValue(v, ctx.mkErr(v.path.v, codeNotExist,
+				"%s: %s not found", prefix, what))
+		}
+	}
+	return v
+}
+
+// FillPath creates a new value by unifying v with the value of x at path p.
+//
+// Values may be any Go value that can be converted to CUE, an ast.Expr or
+// a Value. In the latter case, it will panic if the Value is not from the same
+// Runtime.
+//
+// Unlike Fill, FillPath can fill in list elements, definitions and hidden
+// fields. Filling a list element unifies v with an open list of which only
+// the element at the given index is constrained.
+//
+// Any reference in v referring to the value at the given path will resolve
+// to x in the newly created value. The resulting value is not validated.
+func (v Value) FillPath(x interface{}, p Path) Value {
+	if v.path == nil {
+		return v
+	}
+	ctx := v.ctx()
+	if p.err != nil {
+		return newErrValue(v, ctx.mkErr(v.path.v, p.err))
+	}
+	root := v.path.val()
+
+	var value value
+	if w, ok := x.(Value); ok {
+		if ctx.index != w.ctx().index {
+			panic("value of type Value is not created with same Runtime as Instance")
+		}
+		value = w.eval(ctx)
+	} else {
+		value = convert(ctx, root, true, x)
+	}
+
+	src := root.base()
+	for i := len(p.path) - 1; i >= 0; i-- {
+		sel := p.path[i]
+		if sel.kind == indexSelector {
+			arcs := make([]arc, sel.index+1)
+			for j := range arcs {
+				arcs[j] = arc{feature: label(j), v: &top{src}}
+			}
+			arcs[sel.index].v = value
+			l := &list{baseValue: src, elem: &structLit{baseValue: src, arcs: arcs}}
+			l.initLit()
+			l.len = newBound(ctx, src, opGeq, intKind, l.len)
+			value = l
+			continue
+		}
+		obj := newStruct(root)
+		obj.arcs = []arc{{
+			feature:    sel.label(ctx.index),
+			definition: sel.kind == definitionSelector,
+			v:          value,
+		}}
+		value = obj
+	}
+
+	a := v.path.arc
+	a.v = mkBin(ctx, v.Pos(), opUnify, root, value)
+	a.cache = a.v.evalPartial(ctx)
+	// TODO: validate recursively?
+	return Value{v.idx, &valueData{v.path.parent, v.path.index, a}}
+}
@@ -0,0 +1,210 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	testCases := []struct {
+		in   string
+		out  string
+		sels []Selector
+		err  string
+	}{{
+		in:  "",
+		out: "",
+	}, {
+		in:   "a.b[3]",
+		out:  "a.b[3]",
+		sels: []Selector{Str("a"), Str("b"), Index(3)},
+	}, {
+		in:   `a."quoted label"[1][2].#D._h`,
+		out:  `a."quoted label"[1][2].#D._h`,
+		sels: []Selector{Str("a"), Str("quoted label"), Index(1), Index(2), Def("D"), Hid("_h")},
+	}, {
+		in:   `"_x".123`,
+		out:  `"_x".123`,
+		sels: []Selector{Str("_x"), Str("123")},
+	}, {
+		in:   `[0].a`,
+		out:  `[0].a`,
+		sels: []Selector{Index(0), Str("a")},
+	}, {
+		in:  "a.",
+		err: `invalid path "a.": missing selector`,
+	}, {
+		in:  "a.[1]",
+		err: `invalid path "a.[1]": expected identifier`,
+	}, {
+		in:  "a[-1]",
+		err: `invalid path "a[-1]": invalid index "-1"`,
+	}, {
+		in:  `a."b`,
+		err: `invalid path "a.\"b": unterminated string`,
+	}, {
+		in:  "a b",
+		err: `invalid path "a b": unexpected " "`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			p := ParsePath(tc.in)
+			if err := p.Err(); err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); got != tc.err {
+					t.Fatalf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			if got := p.String(); got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+			if got, want := fmt.Sprint(p.Selectors()), fmt.Sprint(tc.sels); got != want {
+				t.Errorf("selectors: got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	r := &Runtime{}
+	inst, err := r.Compile("in", `
+	a: b: [1, {c: 2}]
+	D :: {e: 3}
+	_h: 4
+	"quoted label": 5
+	opt?: 6
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		path string
+		out  string
+		err  string
+	}{{
+		path: "a.b[1].c",
+		out:  "2",
+	}, {
+		path: "#D.e",
+		out:  "3",
+	}, {
+		path: "_h",
+		out:  "4",
+	}, {
+		path: `"quoted label"`,
+		out:  "5",
+	}, {
+		path: "opt",
+		out:  "6",
+	}, {
+		path: "D",
+		err:  "D: field not found",
+	}, {
+		path: "a.b[2]",
+		err:  "a.b[2]: index out of range (list has 2 elements)",
+	}, {
+		path: "a.b[0].c",
+		err:  "a.b[0].c: cannot select field from int value",
+	}, {
+		path: "a[0]",
+		err:  "a[0]: cannot select index from {...} value",
+	}, {
+		path: "a..b",
+		err:  `invalid path "a..b": expected identifier`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			v := inst.Value().LookupPath(ParsePath(tc.path))
+			if err := v.Err(); err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); !strings.Contains(got, tc.err) || tc.err == "" {
+					t.Fatalf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			if got := fmt.Sprint(v); got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+			if got := v.Path().String(); got != tc.path {
+				t.Errorf("path: got %v; want %v", got, tc.path)
+			}
+		})
+	}
+}
+
+func TestFillPath(t *testing.T) {
+	r := &Runtime{}
+	inst, err := r.Compile("in", `
+	a: b: [int, {c: int}]
+	D :: {e: int}
+	_h: int
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := inst.Value()
+	v = v.FillPath(1, ParsePath("a.b[0]"))
+	v = v.FillPath(2, ParsePath("a.b[1].c"))
+	v = v.FillPath(3, ParsePath("#D.e"))
+	v = v.FillPath(4, ParsePath("_h"))
+	v = v.FillPath(5, ParsePath(`"quoted label"`))
+
+	for path, want := range map[string]string{
+		"a.b[0]":         "1",
+		"a.b[1].c":       "2",
+		"#D.e":           "3",
+		"_h":             "4",
+		`"quoted label"`: "5",
+	} {
+		w := v.LookupPath(ParsePath(path))
+		if got := fmt.Sprint(w.Eval()); got != want {
+			t.Errorf("%s: got %v; want %v", path, got, want)
+		}
+	}
+
+	if err := v.FillPath(3, ParsePath("a.b[0]")).Validate(); err == nil {
+		t.Errorf("expected conflict error")
+	}
+}
+
+func TestIteratorPath(t *testing.T) {
+	r := &Runtime{}
+	inst, err := r.Compile("in", `
+	a: [1, {b: 2}]
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{}
+	inst.Value().Walk(func(v Value) bool {
+		paths = append(paths, v.Path().String())
+		return true
+	}, nil)
+	want := "[ a a[0] a[1] a[1].b]"
+	if got := fmt.Sprint(paths); got != want {
+		t.Errorf("walk: got %v; want %v", got, want)
+	}
+
+	list, _ := inst.Lookup("a").List()
+	sels := []string{}
+	for list.Next() {
+		sels = append(sels, list.Selector().String())
+	}
+	if got, want := fmt.Sprint(sels), "[[0] [1]]"; got != want {
+		t.Errorf("iterator: got %v; want %v", got, want)
+	}
+}
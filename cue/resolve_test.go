@@ -1194,8 +1194,8 @@ a: {
 		out: `<0>{` +
 			`Foo :: <1>C{field: int, recursive: <2>C{field: string}}, ` +
 			`Foo1 :: <3>C{field: int, field2: string}, ` +
-			`foo: _|_(2:field "feild" not allowed in closed struct), ` +
-			`foo1: <4>C{field: 2, recursive: _|_(2:field "feild" not allowed in closed struct)}, ` +
+			`foo: _|_(2:field "feild" not allowed in closed struct; did you mean "field"?), ` +
+			`foo1: <4>C{field: 2, recursive: _|_(2:field "feild" not allowed in closed struct; did you mean "field"?)}, ` +
 			`Bar :: <5>{[]: <6>(A: string)->int, field: int}, ` +
 			`bar: <7>{[]: <8>(A: string)->int, field: int, feild: 2}, ` +
 			`Mixed: _|_(field "Mixed" declared as definition and regular field), ` +
@@ -2019,7 +2019,7 @@ func TestFullEval(t *testing.T) {
 		in: `
 				a: 8000.9
 				a: 7080 | int`,
-		out: `<0>{a: _|_((8000.9 & (int | int)):conflicting values 8000.9 and int (mismatched types float and int))}`, // TODO: fix repetition
+		out: `<0>{a: _|_((8000.9 & (7080 | int)):conflicting values 8000.9 and int (mismatched types float and int))}`,
 	}, {
 		desc: "conflicts in optional fields are okay ",
 		in: `
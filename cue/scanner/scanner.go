@@ -297,7 +297,7 @@ func (s *Scanner) scanQuotedIdentifier() string {
 			s.next()
 			return string(s.src[offs:s.offset])
 
-		case s.ch == '\n':
+		case s.ch == '\n' || s.ch < 0:
 			s.errf(s.offset, "quoted identifier not terminated")
 			return string(s.src[offs:s.offset])
 		}
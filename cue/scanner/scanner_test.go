@@ -731,6 +731,7 @@ var errorTests = []struct {
 
 	{"`foo=bar`", token.IDENT, 4, "`foo=bar`", "invalid character '=' in identifier"},
 	{"`foo\nbar`", token.IDENT, 4, "`foo", "quoted identifier not terminated"},
+	{"`foo", token.IDENT, 4, "`foo", "quoted identifier not terminated"},
 
 	{`@`, token.ATTRIBUTE, 1, `@`, "invalid attribute: expected '('"},
 	{`@foo`, token.ATTRIBUTE, 4, `@foo`, "invalid attribute: expected '('"},
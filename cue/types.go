@@ -285,6 +285,22 @@ func (i *Iterator) Label() string {
 	return i.ctx.labelStr(i.f)
 }
 
+// Selector reports the selector of the current value relative to the value
+// over which i iterates. The full path of the current value can be obtained
+// with the Path method of Value.
+func (i *Iterator) Selector() Selector {
+	if _, ok := i.iter.(*list); ok {
+		return Index(i.p - 1)
+	}
+	switch {
+	case i.f&hidden != 0:
+		return Hid(i.ctx.labelStr(i.f))
+	case i.IsDefinition():
+		return Def(i.ctx.labelStr(i.f))
+	}
+	return Str(i.ctx.labelStr(i.f))
+}
+
 // IsHidden reports if a field is hidden from the data model.
 func (i *Iterator) IsHidden() bool {
 	return i.f&hidden != 0
@@ -645,7 +661,7 @@ func newChildValue(obj *structValue, i int) Value {
 		}
 	}
 
-	return Value{obj.ctx.index, &valueData{obj.path, uint32(i), a}}
+	return Value{obj.ctx.index, obj.ctx.checkChild(&valueData{obj.path, uint32(i), a})}
 }
 
 // Dereference reports to the value v refers to if v is a reference or v itself
@@ -749,7 +765,7 @@ func (v Value) ctx() *context {
 }
 
 func (v Value) makeChild(ctx *context, i uint32, a arc) Value {
-	return Value{v.idx, &valueData{v.path, i, a}}
+	return Value{v.idx, v.idx.checkChild(&valueData{v.path, i, a})}
 }
 
 func (v Value) makeElem(x value) Value {
@@ -2078,6 +2094,7 @@ func isGroundRecursive(ctx *context, v value) *bottom {
 // Walk descends into all values of v, calling f. If f returns false, Walk
 // will not descent further. It only visits values that are part of the data
 // model, so this excludes optional fields, hidden fields, and definitions.
+// The Path method of a visited value reports its location.
 func (v Value) Walk(before func(Value) bool, after func(Value)) {
 	ctx := v.ctx()
 	switch v.Kind() {
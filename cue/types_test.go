@@ -487,6 +487,39 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestErrorSuggestions(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want []string
+	}{{
+		in:   `A :: {name: string, port: int}, a: A & {nmae: "x"}`,
+		want: []string{"name"},
+	}, {
+		in:   `name: "x", a: nmae`,
+		want: []string{"name"},
+	}, {
+		in:   `a: itn`,
+		want: []string{"int"},
+	}, {
+		in: `A :: {name: string}, a: A & {image: "x"}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			var r Runtime
+			inst, err := r.Compile("test", tc.in)
+			if err == nil {
+				err = inst.Value().Validate()
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := errors.Suggestions(err); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q; want %q (error: %v)", got, tc.want, err)
+			}
+		})
+	}
+}
+
 func TestNull(t *testing.T) {
 	testCases := []struct {
 		value string
@@ -31,6 +31,10 @@ func validate(ctx *context, v value) (err *bottom) {
 		if ctx.maxDepth++; ctx.maxDepth > internal.MaxDepth {
 			return nil
 		}
+		if err := ctx.checkDepth(ctx.maxDepth); err != nil {
+			ctx.maxDepth--
+			return ctx.mkErr(x, codeFatal, err)
+		}
 		for i, a := range x.arcs {
 			if a.optional {
 				continue
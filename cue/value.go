@@ -959,6 +959,17 @@ func (x *structLit) isClosed() bool {
 	return x.closeStatus.isClosed()
 }
 
+// isFinalized reports whether updateCloseStatus would not modify x.
+func (x *structLit) isFinalized() bool {
+	switch x.closeStatus {
+	case shouldFinalize:
+		return true
+	case isClosed | shouldFinalize:
+		return x.optionals == nil || x.optionals.closed.isClosed()
+	}
+	return false
+}
+
 func (x *structLit) addTemplate(ctx *context, pos token.Pos, key, value value) {
 	if x.optionals == nil {
 		x.optionals = &optionals{}
@@ -1052,6 +1063,9 @@ func (x *structLit) at(ctx *context, i int) evaluated {
 	// literal nodes or nodes obtained from references. In the later case,
 	// noderef will have ensured that the ancestors were evaluated.
 	if v := x.arcs[i].cache; v == nil {
+		if err := ctx.step(len(ctx.evalStack) + 1); err != nil {
+			return ctx.mkErr(x.arcs[i].v, codeFatal, err)
+		}
 
 		// cycle detection
 
@@ -1093,6 +1107,9 @@ func (x *structLit) at(ctx *context, i int) evaluated {
 			}
 		}
 		x.arcs[i].cache = v
+		if ctx.limits != nil && !ctx.cached(func() { x.arcs[i].cache = nil }) {
+			return v
+		}
 		if doc != nil {
 			x.arcs[i].docs = &docNode{left: doc, right: x.arcs[i].docs}
 		}
@@ -1126,6 +1143,7 @@ func (x *structLit) expandFields(ctx *context) (st *structLit, err *bottom) {
 	}
 
 	x.expanded = x
+	defer ctx.cached(func() { x.expanded = nil })
 
 	comprehensions := x.comprehensions
 
@@ -1313,6 +1331,11 @@ func wrapFinalize(ctx *context, v value) value {
 func updateCloseStatus(ctx *context, v evaluated) evaluated {
 	switch x := v.(type) {
 	case *structLit:
+		if x.isFinalized() {
+			// Finalized structs may be shared across evaluations, such as
+			// those of builtin packages, and should not be modified.
+			return x
+		}
 		if x.closeStatus.shouldClose() {
 			x.closeStatus = isClosed
 			x.optionals = x.optionals.close()
@@ -1672,7 +1695,8 @@ func (x *disjunction) add(ctx *context, v value, marked bool) {
 }
 
 // normalize removes redundant element from unification.
-// x must already have been evaluated.
+// x must already have been evaluated. x itself is not modified, as it may be
+// shared between evaluations.
 func (x *disjunction) normalize(ctx *context, src source) mVal {
 	leq := func(ctx *context, lt, gt dValue) bool {
 		if isBottom(lt.val) {
@@ -1681,7 +1705,8 @@ func (x *disjunction) normalize(ctx *context, src source) mVal {
 		s := subsumer{ctx: ctx}
 		return (!lt.marked || gt.marked) && s.subsumes(gt.val, lt.val)
 	}
-	k := 0
+	values := make([]dValue, 0, len(x.values))
+	errors := x.errors[:len(x.errors):len(x.errors)]
 
 	hasMarked := false
 	var markedErr *bottom
@@ -1693,7 +1718,7 @@ outer:
 		// defaults. The drawback of this approach is that printed intermediate
 		// results will not look great.
 		if err := validate(ctx, v.val); err != nil {
-			x.errors = append(x.errors, err)
+			errors = append(errors, err)
 			if v.marked {
 				markedErr = err
 			}
@@ -1714,21 +1739,22 @@ outer:
 		}
 		// If there was a three-way equality, an element w, where w == v could
 		// already have been added.
-		for j := 0; j < k; j++ {
-			if leq(ctx, v, x.values[j]) {
+		for _, w := range values {
+			if leq(ctx, v, w) {
 				continue outer
 			}
 		}
-		// TODO: do not modify value, but create a new disjunction.
-		x.values[k] = v
-		k++
+		values = append(values, v)
 	}
-	if !hasMarked && markedErr != nil && (k > 1 || !x.values[0].val.kind().isGround()) {
-		x.values[k] = dValue{&bottom{}, true}
-		k++
+	first := x.values[0]
+	if len(values) > 0 {
+		first = values[0]
+	}
+	if !hasMarked && markedErr != nil && (len(values) > 1 || !first.val.kind().isGround()) {
+		values = append(values, dValue{&bottom{}, true})
 	}
 
-	switch k {
+	switch len(values) {
 	case 0:
 		// Empty disjunction. All elements must be errors.
 		// Take the first error as an example.
@@ -1737,14 +1763,16 @@ outer:
 			// TODO: use format instead of debugStr.
 			err = ctx.mkErr(src, ctx.str(err))
 		}
-		return mVal{x.computeError(ctx, src), false}
+		d := &disjunction{x.baseValue, x.values, errors, x.hasDefaults}
+		return mVal{d.computeError(ctx, src), false}
 	case 1:
-		v := x.values[0]
+		v := values[0]
 		return mVal{v.val.(evaluated), v.marked}
 	}
-	// TODO: do not modify value, but create a new disjunction.
-	x.values = x.values[:k]
-	return mVal{x, false}
+	if len(values) == len(x.values) && len(errors) == len(x.errors) {
+		return mVal{x, false}
+	}
+	return mVal{&disjunction{x.baseValue, values, errors, x.hasDefaults}, false}
 }
 
 func (x *disjunction) computeError(ctx *context, src source) evaluated {
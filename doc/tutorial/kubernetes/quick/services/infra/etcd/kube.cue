@@ -74,14 +74,14 @@ statefulSet: etcd: spec: {
 					"http://$(IP):2379,http://127.0.0.1:2379",
 					"-advertise-client-urls",
 					"http://$(IP):2379",
+					// bootstrap
+					// "-initial-cluster-token", "etcd-prod-events2",
 					"-discovery",
 					"https://discovery.etcd.io/xxxxxx",
 				]
 			}]
 		}
 	}
-	// bootstrap
-	// "-initial-cluster-token", "etcd-prod-events2",
 
 	volumeClaimTemplates: [{
 		metadata: {
@@ -0,0 +1,67 @@
+/**
+ * A Person is someone we know.
+ *
+ * People may have friends.
+ */
+export interface Person {
+	/**
+	 * Name is the full name.
+	 */
+	name: string;
+	nickname?: string;
+	age: number;
+	height: number;
+	admin: boolean;
+	role: "admin" | "user" | "guest";
+	friends?: Person[];
+	address: Address;
+	"e-mail": string;
+	tags: {
+		[key: string]: string;
+	};
+	extra: {
+		a: number;
+		[key: string]: any;
+	};
+	pair: [string, number];
+	any: any;
+	null: null | string;
+	data: string;
+}
+
+export interface Address {
+	street: string;
+	city: string;
+}
+
+export type Employee = Person & {
+	company: string;
+};
+
+export type Status = "active" | "inactive" | "pending";
+
+export type Level = 1 | 2 | 3;
+
+export type Shape = Circle | Square;
+
+export interface Circle {
+	kind: "circle";
+	radius: number;
+}
+
+export interface Square {
+	kind: "square";
+	side: number;
+}
+
+export type Names = string[];
+
+export interface Config {
+	[key: string]: number;
+}
+
+export interface Lists {
+	a: number[];
+	b: number[];
+	c: [1] | string[];
+}
@@ -0,0 +1,66 @@
+package basic
+
+// A Person is someone we know.
+//
+// People may have friends.
+Person :: {
+	// Name is the full name.
+	name:      string
+	nickname?: string
+	age:       int & >=0
+	height:    number
+	admin:     *false | bool
+	role:      "admin" | "user" | *"guest"
+	friends?: [...Person]
+	address:  Address
+	"e-mail": string
+	tags: [string]: string
+	extra: {
+		a:   int
+		...
+	}
+	pair: [string, int]
+	any:  _
+	null: null | string
+	data: bytes
+}
+
+Address :: {
+	street: string
+	city:   string
+}
+
+Employee :: {
+	Person
+	company: string
+}
+
+Status :: "active" | "inactive" | "pending"
+
+Level :: 1 | 2 | 3
+
+Shape :: Circle | Square
+
+Circle :: {
+	kind:   "circle"
+	radius: float
+}
+
+Square :: {
+	kind: "square"
+	side: number
+}
+
+Names :: [...string]
+
+Config :: {
+	[string]: int
+}
+
+Lists :: {
+	a: [...int] | *[1]
+	b: *[] | [...int]
+	c: *[1] | [...string]
+}
+
+notADefinition: 1
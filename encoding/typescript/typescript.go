@@ -0,0 +1,593 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package typescript converts CUE definitions to TypeScript declarations.
+//
+// Each top-level definition of an instance is mapped to an exported
+// TypeScript interface, if it is a struct, or a type alias otherwise.
+// Values are mapped as follows:
+//
+//	CUE                       TypeScript
+//	string, bytes             string
+//	number, int, float        number
+//	bool                      boolean
+//	null                      null
+//	_                         any
+//	"a", 1, true              literal types
+//	a | b                     a | b
+//	Def & {x: int}            Def & { x: number }
+//	[...T]                    T[]
+//	[A, B]                    [A, B]
+//	{a?: T}                   { a?: T }
+//	{[string]: T}             { [key: string]: T }
+//	{a: T, ...}               { a: T; [key: string]: any }
+//
+// References to other top-level definitions of the same instance are
+// referred to by name. All other references are expanded.
+//
+// Structs that are closed, which includes all structs defined within a
+// definition, allow only the declared fields. Open structs are given an index
+// signature that allows any other field. Doc comments are converted to JSDoc
+// comments. Generate fails for definitions whose names are not valid
+// TypeScript type names, such as reserved words.
+package typescript
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Config defines options for generating TypeScript declarations.
+type Config struct {
+	// Indent is the string used for each level of indentation. It defaults to
+	// two spaces.
+	Indent string
+}
+
+// Generate returns the TypeScript declarations for the top-level definitions
+// of inst. It is an error if inst has no top-level definitions.
+func Generate(inst *cue.Instance, c *Config) (b []byte, err error) {
+	if c == nil {
+		c = &Config{}
+	}
+	g := &generator{
+		inst:   inst,
+		indent: c.Indent,
+		defs:   map[string]bool{},
+	}
+	if g.indent == "" {
+		g.indent = "  "
+	}
+
+	defer func() {
+		switch x := recover().(type) {
+		case nil:
+		case *generateError:
+			err = x.err
+		default:
+			panic(x)
+		}
+	}()
+
+	iter, err := inst.Value().Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	type def struct {
+		name string
+		v    cue.Value
+	}
+	var defs []def
+	for iter.Next() {
+		if !iter.IsDefinition() {
+			continue
+		}
+		name := iter.Label()
+		g.defs[name] = true
+		defs = append(defs, def{name, iter.Value()})
+	}
+	if len(defs) == 0 {
+		return nil, errors.Newf(inst.Value().Pos(),
+			"no top-level definitions to generate TypeScript declarations for")
+	}
+
+	for i, d := range defs {
+		if i > 0 {
+			g.WriteByte('\n')
+		}
+		g.decl(d.name, d.v)
+	}
+	return g.Bytes(), nil
+}
+
+type generateError struct {
+	err error
+}
+
+type generator struct {
+	bytes.Buffer
+
+	inst   *cue.Instance
+	indent string
+	defs   map[string]bool // names of top-level definitions
+	depth  int             // current indentation level
+}
+
+func (g *generator) fail(err error) {
+	panic(&generateError{err})
+}
+
+// decl writes the declaration of the definition with the given name.
+func (g *generator) decl(name string, v cue.Value) {
+	if !isTypeName(name) {
+		g.fail(errors.Newf(v.Pos(),
+			"definition %s: %q is not a valid TypeScript type name", name, name))
+	}
+	g.doc(v)
+	if g.isInterface(v) {
+		fmt.Fprintf(g, "export interface %s ", name)
+		g.object(v)
+		g.WriteByte('\n')
+		return
+	}
+	fmt.Fprintf(g, "export type %s = ", name)
+	g.typ(v)
+	g.WriteString(";\n")
+}
+
+// isInterface reports whether v can be represented as an interface, which is
+// the case for structs that are not the result of a disjunction or a
+// unification with references.
+func (g *generator) isInterface(v cue.Value) bool {
+	if v.IncompleteKind() != cue.StructKind {
+		return false
+	}
+	for _, x := range split(cue.AndOp, v) {
+		if g.ref(x) != "" {
+			return false
+		}
+		if op, _ := x.Expr(); op == cue.OrOp {
+			return false
+		}
+	}
+	return true
+}
+
+// doc writes the doc comments of v, if any, as a JSDoc comment.
+func (g *generator) doc(v cue.Value) {
+	var lines []string
+	for _, cg := range v.Doc() {
+		text := strings.TrimSpace(cg.Text())
+		if text == "" {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(text, "\n")...)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	g.WriteString("/**\n")
+	for _, l := range lines {
+		g.writeIndent()
+		// Prevent the comment from being terminated early.
+		l = strings.Replace(l, "*/", "*\\/", -1)
+		if l == "" {
+			g.WriteString(" *\n")
+		} else {
+			fmt.Fprintf(g, " * %s\n", l)
+		}
+	}
+	g.writeIndent()
+	g.WriteString(" */\n")
+	g.writeIndent()
+}
+
+func (g *generator) writeIndent() {
+	for i := 0; i < g.depth; i++ {
+		g.WriteString(g.indent)
+	}
+}
+
+// ref returns the name of the top-level definition to which v refers, or ""
+// if v is not such a reference.
+func (g *generator) ref(v cue.Value) string {
+	inst, path := v.Reference()
+	if inst != g.inst || len(path) != 1 || !g.defs[path[0]] || !isTypeName(path[0]) {
+		return ""
+	}
+	return path[0]
+}
+
+// typ writes the TypeScript type of v.
+func (g *generator) typ(v cue.Value) {
+	if name := g.ref(v); name != "" {
+		g.WriteString(name)
+		return
+	}
+
+	switch op, a := v.Expr(); op {
+	case cue.OrOp:
+		g.union(a)
+		return
+
+	case cue.NoOp:
+		// A disjunction reduces to a single value if its defaults are
+		// subsumed by another disjunct. Use that value rather than the
+		// default to which v evaluates.
+		if len(a) == 1 {
+			v = a[0]
+		}
+
+	case cue.AndOp:
+		// Keep references to definitions and combine all other conjuncts.
+		var names []string
+		var rest cue.Value
+		n := 0
+		for _, x := range split(cue.AndOp, v) {
+			if name := g.ref(x); name != "" {
+				names = append(names, name)
+				continue
+			}
+			rest = rest.Unify(x)
+			n++
+		}
+		if len(names) == 0 {
+			break
+		}
+		for i, name := range names {
+			if i > 0 {
+				g.WriteString(" & ")
+			}
+			g.WriteString(name)
+		}
+		if n > 0 && !isTop(rest) {
+			g.WriteString(" & ")
+			g.operand(rest, cue.AndOp)
+		}
+		return
+	}
+
+	v = v.Eval()
+	if op, a := v.Expr(); op == cue.OrOp {
+		g.union(a)
+		return
+	}
+	if err := v.Err(); err != nil && !v.IsIncomplete() {
+		g.WriteString("never")
+		return
+	}
+
+	if v.IsConcrete() {
+		switch v.Kind() {
+		case cue.StringKind:
+			s, _ := v.String()
+			g.WriteString(strconv.Quote(s))
+			return
+		case cue.IntKind, cue.FloatKind, cue.NumberKind:
+			g.number(v)
+			return
+		case cue.BoolKind:
+			b, _ := v.Bool()
+			g.WriteString(strconv.FormatBool(b))
+			return
+		case cue.NullKind:
+			g.WriteString("null")
+			return
+		}
+	}
+
+	switch k := v.IncompleteKind(); k {
+	case cue.StructKind:
+		g.object(v)
+	case cue.ListKind:
+		g.list(v)
+	case cue.StringKind, cue.BytesKind, cue.StringKind | cue.BytesKind:
+		g.WriteString("string")
+	case cue.IntKind, cue.FloatKind, cue.NumberKind:
+		g.WriteString("number")
+	case cue.BoolKind:
+		g.WriteString("boolean")
+	case cue.NullKind:
+		g.WriteString("null")
+	case cue.BottomKind:
+		g.WriteString("never")
+	default:
+		g.kinds(k)
+	}
+}
+
+// kinds writes a union of the types of the basic kinds in k, or any if k
+// includes all kinds.
+func (g *generator) kinds(k cue.Kind) {
+	if k&cue.StructKind != 0 && k&cue.ListKind != 0 {
+		g.WriteString("any")
+		return
+	}
+	var types []string
+	if k&(cue.StringKind|cue.BytesKind) != 0 {
+		types = append(types, "string")
+	}
+	if k&cue.NumberKind != 0 {
+		types = append(types, "number")
+	}
+	if k&cue.BoolKind != 0 {
+		types = append(types, "boolean")
+	}
+	if k&cue.NullKind != 0 {
+		types = append(types, "null")
+	}
+	if k&cue.StructKind != 0 {
+		types = append(types, "{ [key: string]: any }")
+	}
+	if k&cue.ListKind != 0 {
+		types = append(types, "any[]")
+	}
+	if len(types) == 0 {
+		g.WriteString("any")
+		return
+	}
+	g.WriteString(strings.Join(types, " | "))
+}
+
+// union writes the union of the types of the given disjuncts.
+func (g *generator) union(a []cue.Value) {
+	seen := map[string]bool{}
+	first := true
+	for _, x := range a {
+		s := g.sub(func() { g.operand(x, cue.OrOp) })
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		if !first {
+			g.WriteString(" | ")
+		}
+		first = false
+		g.WriteString(s)
+	}
+}
+
+// operand writes the type of v as an operand of a union or intersection,
+// enclosing it in parentheses if needed.
+func (g *generator) operand(v cue.Value, op cue.Op) {
+	s := g.sub(func() { g.typ(v) })
+	if op == cue.AndOp && strings.Contains(topLevel(s), " | ") {
+		s = "(" + s + ")"
+	}
+	g.WriteString(s)
+}
+
+// sub returns what f writes to g instead of writing it.
+func (g *generator) sub(f func()) string {
+	saved := g.Buffer
+	g.Buffer = bytes.Buffer{}
+	f()
+	s := g.String()
+	g.Buffer = saved
+	return s
+}
+
+func (g *generator) number(v cue.Value) {
+	if v.Kind() == cue.IntKind {
+		var z big.Int
+		if _, err := v.Int(&z); err == nil {
+			g.WriteString(z.String())
+			return
+		}
+	}
+	f, err := v.Float64()
+	if err != nil {
+		g.fail(err)
+	}
+	g.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// list writes the type of a list as an array or tuple type.
+func (g *generator) list(v cue.Value) {
+	var elems []string
+	if iter, err := v.List(); err == nil {
+		for iter.Next() {
+			elems = append(elems, g.sub(func() { g.typ(iter.Value()) }))
+		}
+	}
+	elem, hasElem := v.Elem()
+	if hasElem && isBottom(elem) {
+		hasElem = false
+	}
+	switch {
+	case len(elems) == 0 && hasElem:
+		g.WriteString(arrayOf(g.sub(func() { g.typ(elem) })))
+	case len(elems) == 0 && v.IsClosed():
+		g.WriteString("[]")
+	case len(elems) == 0:
+		g.WriteString("any[]")
+	default:
+		if hasElem && !v.IsClosed() {
+			elems = append(elems, "..."+arrayOf(g.sub(func() { g.typ(elem) })))
+		}
+		fmt.Fprintf(g, "[%s]", strings.Join(elems, ", "))
+	}
+}
+
+func arrayOf(s string) string {
+	if strings.Contains(topLevel(s), " ") {
+		s = "(" + s + ")"
+	}
+	return s + "[]"
+}
+
+// object writes the type of a struct as an object type.
+func (g *generator) object(v cue.Value) {
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		g.fail(err)
+	}
+
+	type field struct {
+		name     string
+		optional bool
+		v        cue.Value
+	}
+	var fields []field
+	for iter.Next() {
+		if iter.IsHidden() {
+			continue
+		}
+		fields = append(fields, field{iter.Label(), iter.IsOptional(), iter.Value()})
+	}
+
+	var index string
+	if elem, ok := v.Elem(); ok && !isBottom(elem) {
+		index = g.sub(func() {
+			g.depth++
+			g.typ(elem)
+			g.depth--
+		})
+	} else if !v.IsClosed() {
+		index = "any"
+	}
+
+	if len(fields) == 0 && index == "" {
+		g.WriteString("{}")
+		return
+	}
+
+	g.WriteString("{\n")
+	g.depth++
+	for _, f := range fields {
+		g.writeIndent()
+		g.doc(f.v)
+		g.WriteString(propertyName(f.name))
+		if f.optional {
+			g.WriteByte('?')
+		}
+		g.WriteString(": ")
+		g.typ(f.v)
+		g.WriteString(";\n")
+	}
+	if index != "" {
+		g.writeIndent()
+		fmt.Fprintf(g, "[key: string]: %s;\n", index)
+	}
+	g.depth--
+	g.writeIndent()
+	g.WriteString("}")
+}
+
+// split returns the operands of v if v is an expression of the given
+// operator, flattening nested expressions of the same operator.
+func split(op cue.Op, v cue.Value) []cue.Value {
+	if o, a := v.Expr(); o == op {
+		var result []cue.Value
+		for _, x := range a {
+			result = append(result, split(op, x)...)
+		}
+		return result
+	}
+	return []cue.Value{v}
+}
+
+const allKinds = cue.NullKind | cue.BoolKind | cue.NumberKind |
+	cue.StringKind | cue.BytesKind | cue.StructKind | cue.ListKind
+
+func isTop(v cue.Value) bool {
+	return v.IncompleteKind()&allKinds == allKinds
+}
+
+func isBottom(v cue.Value) bool {
+	return v.IncompleteKind() == cue.BottomKind
+}
+
+// topLevel returns s with all text enclosed in brackets, braces, parentheses,
+// or quotes removed.
+func topLevel(s string) string {
+	var b strings.Builder
+	depth := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '{' || c == '[' || c == '(':
+			depth++
+		case c == '}' || c == ']' || c == ')':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// propertyName returns name as a TypeScript property name, quoting it if it
+// is not a valid identifier.
+func propertyName(name string) string {
+	if isIdent(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// isTypeName reports whether s can be used as the name of a type.
+func isTypeName(s string) bool {
+	return isIdent(s) && !reserved[s]
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_' || c == '$':
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// reserved holds the words that may not be used as type names.
+var reserved = func() map[string]bool {
+	words := strings.Fields(`
+		any boolean break case catch class const continue debugger default
+		delete do else enum export extends false finally for function if
+		implements import in instanceof interface let never new null number
+		object package private protected public return static string super
+		switch symbol this throw true try typeof undefined unknown var void
+		while with yield`)
+	m := map[string]bool{}
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}()
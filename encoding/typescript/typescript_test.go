@@ -0,0 +1,109 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typescript_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylelemons/godebug/diff"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/encoding/typescript"
+)
+
+var update *bool = flag.Bool("update", false, "update the test output")
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		in, out string
+		config  *typescript.Config
+	}{{
+		"basic.cue",
+		"basic.ts",
+		nil,
+	}, {
+		"basic.cue",
+		"basic-tabs.ts",
+		&typescript.Config{Indent: "\t"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.out, func(t *testing.T) {
+			inst := cue.Build(load.Instances([]string{tc.in}, &load.Config{
+				Dir: "./testdata",
+			}))[0]
+			if inst.Err != nil {
+				t.Fatal(errors.Details(inst.Err, nil))
+			}
+
+			b, err := typescript.Generate(inst, tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantFile := filepath.Join("testdata", tc.out)
+			if *update {
+				_ = ioutil.WriteFile(wantFile, b, 0644)
+				return
+			}
+
+			want, err := ioutil.ReadFile(wantFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d := diff.Diff(string(b), string(want)); d != "" {
+				t.Errorf("files differ:\n%v", d)
+			}
+		})
+	}
+}
+
+func TestGenerateInvalidName(t *testing.T) {
+	testCases := []string{
+		`default :: string`,
+		`interface :: {a: int}`,
+		`"a-b" :: int`,
+	}
+	for _, in := range testCases {
+		t.Run(in, func(t *testing.T) {
+			var r cue.Runtime
+			inst, err := r.Compile("test", in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := typescript.Generate(inst, nil)
+			if err == nil {
+				t.Errorf("expected error; got:\n%s", b)
+			}
+		})
+	}
+}
+
+func TestGenerateNoDefinitions(t *testing.T) {
+	var r cue.Runtime
+	inst, err := r.Compile("test", `a: 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := typescript.Generate(inst, nil)
+	if err == nil {
+		t.Errorf("expected error; got:\n%s", b)
+	}
+}
@@ -104,3 +104,117 @@ c: baz
 		})
 	}
 }
+
+func TestExtractComments(t *testing.T) {
+	testCases := []struct {
+		name string
+		yaml string
+		want string
+	}{{
+		name: "fields",
+		yaml: `# Head of document.
+
+# Doc of a.
+a: 1 # line a
+b: # doc of b
+  c: 2
+  # foot of b
+# foot of document
+`,
+		want: `// Head of document.
+
+// Doc of a.
+a: 1 // line a
+// doc of b
+b: {
+	c: 2
+	// foot of b
+}
+// foot of document`,
+	}, {
+		name: "lists",
+		yaml: `ports:
+  # first
+  - 80 # http
+  - 443
+  # foot of ports
+items:
+- # doc of item
+  name: x
+# doc of c
+c: true
+`,
+		want: `ports: [
+	// first
+	80, // http
+	443,
+	// foot of ports
+]
+items: [{
+	// doc of item
+	name: "x"
+}]
+// doc of c
+c: true`,
+	}, {
+		name: "indentless",
+		yaml: `a:
+- 1
+# doc of b
+b: 2
+`,
+		want: `a: [
+	1,
+]
+// doc of b
+b: 2`,
+	}, {
+		name: "flow and block scalars",
+		yaml: `a: {x: 1, z: 2} # line a
+b: |
+  text # not a comment
+# doc of c
+c: 3
+`,
+		want: `a: {x: 1, z: 2} // line a
+b: """
+		text # not a comment
+
+		"""
+
+// doc of c
+c: 3`,
+	}, {
+		name: "single-line collections",
+		yaml: `c:
+  x: 1 # line x
+d: {x: 1} # line d
+l:
+  - a # line a
+m: [1, 2] # line m
+`,
+		want: `c: {
+	x: 1 // line x
+}
+d: {x: 1} // line d
+l: [
+	"a", // line a
+]
+m: [1, 2] // line m`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Extract(tc.name, tc.yaml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(string(b)); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
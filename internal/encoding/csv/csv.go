@@ -0,0 +1,321 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csv converts CSV and TSV to and from CUE.
+//
+// The first row of the input is a header row holding the field names. Each
+// following row is converted to a struct with a field for each column. For
+// example,
+//
+//	name,age                     {name: "alice", age: "30"}
+//	alice,30                     {name: "bob", age: "25"}
+//	bob,25
+//
+// All values are strings, unless a schema is given. In that case, a value
+// is converted to null, a boolean, an integer, or a number if the
+// corresponding field in the schema does not allow strings and the value
+// has the appropriate syntax.
+//
+// Converting CUE to CSV applies the reverse mapping to a list of structs.
+package csv
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/source"
+)
+
+// Config defines options for decoding and encoding.
+type Config struct {
+	// Comma is the field delimiter. It defaults to ','.
+	Comma rune
+
+	// Schema, if it exists, determines the types of the decoded values.
+	Schema cue.Value
+}
+
+func (c *Config) comma() rune {
+	if c == nil || c.Comma == 0 {
+		return ','
+	}
+	return c.Comma
+}
+
+// A Decoder converts the rows of a CSV file to CUE structs.
+type Decoder struct {
+	file   *token.File
+	src    []byte
+	offset int
+	comma  rune
+	schema cue.Value
+	header []column
+	row    int
+	err    error
+}
+
+type column struct {
+	name string
+	kind cue.Kind
+}
+
+type cell struct {
+	value  string
+	offset int
+}
+
+// NewDecoder creates a decoder for the CSV input of r. The filename is used
+// for recording position information.
+func NewDecoder(filename string, r io.Reader, cfg *Config) *Decoder {
+	b, err := source.Read(filename, r)
+	d := &Decoder{
+		file:  token.NewFile(filename, -1, len(b)),
+		src:   b,
+		comma: cfg.comma(),
+		err:   err,
+	}
+	if cfg != nil {
+		d.schema = cfg.Schema
+	}
+	d.file.SetLinesForContent(b)
+	return d
+}
+
+// Extract converts the next row to a struct. It returns io.EOF if the input
+// has been exhausted.
+func (d *Decoder) Extract() (ast.Expr, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.header == nil {
+		if d.err = d.readHeader(); d.err != nil {
+			return nil, d.err
+		}
+	}
+	start := d.offset
+	cells, err := d.record()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	d.row++
+	if len(cells) != len(d.header) {
+		d.err = errors.Newf(d.pos(start, token.NoRelPos),
+			"csv: row %d has %d fields; expected %d", d.row, len(cells), len(d.header))
+		return nil, d.err
+	}
+
+	obj := &ast.StructLit{}
+	for i, c := range cells {
+		col := d.header[i]
+		obj.Elts = append(obj.Elts, &ast.Field{
+			Label: label(col.name, d.pos(c.offset, token.Newline)),
+			Value: d.value(col.kind, c),
+		})
+	}
+	return obj, nil
+}
+
+func (d *Decoder) readHeader() error {
+	cells, err := d.record()
+	if err == io.EOF {
+		return errors.Newf(token.NoPos, "csv: missing header row")
+	}
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, c := range cells {
+		if c.value == "" {
+			return errors.Newf(d.pos(c.offset, token.NoRelPos), "csv: empty field name")
+		}
+		if seen[c.value] {
+			return errors.Newf(d.pos(c.offset, token.NoRelPos), "csv: duplicate field name %q", c.value)
+		}
+		seen[c.value] = true
+		d.header = append(d.header, column{name: c.value, kind: d.kind(c.value)})
+	}
+	return nil
+}
+
+// kind returns the kinds of values allowed by the schema for the given
+// field, or cue.StringKind if there is no such field.
+func (d *Decoder) kind(name string) cue.Kind {
+	if !d.schema.Exists() {
+		return cue.StringKind
+	}
+	v := d.schema.Lookup(name)
+	if !v.Exists() {
+		if t := d.schema.Template(); t != nil {
+			v = t(name)
+		}
+	}
+	if !v.Exists() {
+		return cue.StringKind
+	}
+	return v.IncompleteKind()
+}
+
+func (d *Decoder) pos(offset int, rel token.RelPos) token.Pos {
+	return d.file.Pos(offset, rel)
+}
+
+var (
+	intRE = regexp.MustCompile(`^-?[0-9]+$`)
+	numRE = regexp.MustCompile(`^-?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// value converts the cell to a value of the given kind, or to a string if
+// it does not have the syntax of such a value.
+func (d *Decoder) value(kind cue.Kind, c cell) ast.Expr {
+	pos := d.pos(c.offset, token.Blank)
+	s := c.value
+	if kind&cue.StringKind != 0 {
+		return str(pos, s)
+	}
+	if kind&cue.NullKind != 0 && (s == "" || s == "null") {
+		return &ast.BasicLit{ValuePos: pos, Kind: token.NULL, Value: "null"}
+	}
+	if kind&cue.BoolKind != 0 {
+		switch strings.ToLower(s) {
+		case "true":
+			return &ast.BasicLit{ValuePos: pos, Kind: token.TRUE, Value: "true"}
+		case "false":
+			return &ast.BasicLit{ValuePos: pos, Kind: token.FALSE, Value: "false"}
+		}
+	}
+	if kind&cue.IntKind != 0 && intRE.MatchString(s) {
+		return number(pos, token.INT, s)
+	}
+	if kind&cue.FloatKind != 0 && numRE.MatchString(s) {
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return number(pos, token.FLOAT, s)
+	}
+	return str(pos, s)
+}
+
+func str(pos token.Pos, s string) ast.Expr {
+	lit := ast.NewString(s)
+	lit.ValuePos = pos
+	return lit
+}
+
+func number(pos token.Pos, kind token.Token, s string) ast.Expr {
+	if strings.HasPrefix(s, "-") {
+		return &ast.UnaryExpr{
+			OpPos: pos,
+			Op:    token.SUB,
+			X:     &ast.BasicLit{ValuePos: pos, Kind: kind, Value: s[1:]},
+		}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: kind, Value: s}
+}
+
+// record reads the cells of the next non-empty row.
+func (d *Decoder) record() ([]cell, error) {
+	for d.offset < len(d.src) && (d.src[d.offset] == '\n' || bytes.HasPrefix(d.src[d.offset:], []byte("\r\n"))) {
+		d.offset = d.skipNewline(d.offset)
+	}
+	if d.offset >= len(d.src) {
+		return nil, io.EOF
+	}
+
+	var cells []cell
+	for {
+		c, err := d.cell()
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, c)
+		if d.offset >= len(d.src) {
+			return cells, nil
+		}
+		if r := d.src[d.offset]; r == '\n' || r == '\r' {
+			d.offset = d.skipNewline(d.offset)
+			return cells, nil
+		}
+		d.offset += len(string(d.comma)) // skip delimiter
+	}
+}
+
+// cell reads a single cell, leaving the offset at the subsequent delimiter
+// or end of line.
+func (d *Decoder) cell() (cell, error) {
+	start := d.offset
+	src := d.src
+	if start < len(src) && src[start] == '"' {
+		var buf strings.Builder
+		i := start + 1
+		for {
+			j := bytes.IndexByte(src[i:], '"')
+			if j < 0 {
+				return cell{}, errors.Newf(d.pos(start, token.NoRelPos), "csv: quoted field not terminated")
+			}
+			buf.Write(src[i : i+j])
+			i += j + 1
+			if i < len(src) && src[i] == '"' {
+				buf.WriteByte('"')
+				i++
+				continue
+			}
+			break
+		}
+		d.offset = i
+		if !d.atEndOfCell() {
+			return cell{}, errors.Newf(d.pos(i, token.NoRelPos), `csv: extraneous " in quoted field`)
+		}
+		return cell{value: strings.Replace(buf.String(), "\r\n", "\n", -1), offset: start}, nil
+	}
+
+	for !d.atEndOfCell() {
+		if src[d.offset] == '"' {
+			return cell{}, errors.Newf(d.pos(d.offset, token.NoRelPos), `csv: bare " in non-quoted field`)
+		}
+		d.offset++
+	}
+	return cell{value: string(src[start:d.offset]), offset: start}, nil
+}
+
+func (d *Decoder) atEndOfCell() bool {
+	if d.offset >= len(d.src) {
+		return true
+	}
+	rest := d.src[d.offset:]
+	return rest[0] == '\n' ||
+		bytes.HasPrefix(rest, []byte("\r\n")) ||
+		bytes.HasPrefix(rest, []byte(string(d.comma)))
+}
+
+func (d *Decoder) skipNewline(offset int) int {
+	if d.src[offset] == '\r' {
+		offset++
+	}
+	return offset + 1
+}
+
+func label(name string, pos token.Pos) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
@@ -0,0 +1,246 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+func extractAll(t *testing.T, src string, cfg *Config) string {
+	t.Helper()
+	d := NewDecoder("test", strings.NewReader(src), cfg)
+	list := &ast.ListLit{}
+	for {
+		expr, err := d.Extract()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		list.Elts = append(list.Elts, expr)
+	}
+	b, err := format.Node(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name   string
+		csv    string
+		comma  rune
+		schema string
+		want   string
+	}{{
+		name: "strings",
+		csv:  "name,\"with space\"\r\nfoo,\"a, \"\"b\"\"\nc\"\n\nbar,\n",
+		want: `[{
+	name:         "foo"
+	"with space": "a, \"b\"\nc"
+}, {
+	name:         "bar"
+	"with space": ""
+}]`,
+	}, {
+		name:  "tsv",
+		csv:   "a\tb\n1,2\t3\n",
+		comma: '\t',
+		want: `[{
+	a: "1,2"
+	b: "3"
+}]`,
+	}, {
+		name: "schema",
+		csv: `name,age,score,active,note,other
+1,-2,3,TRUE,,4.5
+x,y,-1.5e3,no,7,z
+`,
+		schema: `{
+			name:   string
+			age:    int
+			score:  float
+			active: bool
+			note:   null | int
+		}`,
+		want: `[{
+	name:   "1"
+	age:    -2
+	score:  3.0
+	active: true
+	note:   null
+	other:  "4.5"
+}, {
+	name:   "x"
+	age:    "y"
+	score:  -1.5e3
+	active: "no"
+	note:   7
+	other:  "z"
+}]`,
+	}, {
+		name:   "template",
+		csv:    "a,b\n1,2.5\n",
+		schema: `[string]: number`,
+		want: `[{
+	a: 1
+	b: 2.5
+}]`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Comma: tc.comma}
+			if tc.schema != "" {
+				inst, err := r.Compile("schema", tc.schema)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cfg.Schema = inst.Value()
+			}
+			got := strings.TrimSpace(extractAll(t, tc.csv, cfg))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		csv string
+		pos string
+		err string
+	}{{
+		csv: "a,b\n1,2\n3\n",
+		pos: "test:3:1",
+		err: "csv: row 2 has 1 fields; expected 2",
+	}, {
+		csv: "a,a\n",
+		pos: "test:1:3",
+		err: `csv: duplicate field name "a"`,
+	}, {
+		csv: "a\n\"foo\n",
+		pos: "test:2:1",
+		err: "csv: quoted field not terminated",
+	}, {
+		csv: "a\n\"foo\"bar\n",
+		pos: "test:2:6",
+		err: `csv: extraneous " in quoted field`,
+	}, {
+		csv: "a\nfo\"o\n",
+		pos: "test:2:3",
+		err: `csv: bare " in non-quoted field`,
+	}, {
+		csv: "",
+		pos: "-",
+		err: "csv: missing header row",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.csv, func(t *testing.T) {
+			d := NewDecoder("test", strings.NewReader(tc.csv), nil)
+			var err error
+			for err == nil {
+				_, err = d.Extract()
+			}
+			if err == io.EOF {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+			if got := err.(errors.Error).Position().String(); got != tc.pos {
+				t.Errorf("got position %s; want %s", got, tc.pos)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	r := &cue.Runtime{}
+	inst, err := r.Compile("test", `[{
+	name:  "foo, \"bar\""
+	age:   30
+	score: 1.5
+}, {
+	name:   "baz"
+	active: true
+	score:  null
+}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encode(inst.Value(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `name,age,score,active
+"foo, ""bar""",30,1.5,
+baz,,,true
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	b, err = Encode(inst.Value(), &Config{Comma: '\t'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "name\tage\tscore\tactive\n\"foo, \"\"bar\"\"\"\t30\t1.5\t\nbaz\t\t\ttrue\n"
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	testCases := []struct {
+		cue string
+		err string
+	}{{
+		cue: `{a: 1}`,
+		err: "csv: top-level value must be a list of structs",
+	}, {
+		cue: `[{a: 1}, 2]`,
+		err: "csv: row 2 is not a struct",
+	}, {
+		cue: `[{a: {b: 1}}]`,
+		err: `csv: invalid value for field "a" in row 1: value must be null, a boolean, a number, or a string`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			inst, err := r.Compile("test", tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Encode(inst.Value(), nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// Encode converts a list of structs to CSV.
+//
+// The header row holds the names of the fields of all structs, in order of
+// first appearance. The values of the fields must be null, booleans, numbers,
+// or strings. Missing fields and null values are written as empty cells.
+func Encode(v cue.Value, cfg *Config) ([]byte, error) {
+	iter, err := v.List()
+	if err != nil {
+		return nil, errors.Newf(v.Pos(), "csv: top-level value must be a list of structs")
+	}
+
+	var names []string
+	index := map[string]int{}
+	var rows []map[string]string
+	for iter.Next() {
+		elem := iter.Value()
+		fields, err := elem.Fields()
+		if err != nil {
+			return nil, errors.Newf(elem.Pos(), "csv: row %d is not a struct", len(rows)+1)
+		}
+		row := map[string]string{}
+		for fields.Next() {
+			name := fields.Label()
+			s, err := text(fields.Value())
+			if err != nil {
+				return nil, errors.Wrapf(err, fields.Value().Pos(),
+					"csv: invalid value for field %q in row %d", name, len(rows)+1)
+			}
+			if _, ok := index[name]; !ok {
+				index[name] = len(names)
+				names = append(names, name)
+			}
+			row[name] = s
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = cfg.comma()
+	_ = w.Write(names)
+	record := make([]string, len(names))
+	for _, row := range rows {
+		for i, name := range names {
+			record[i] = row[name]
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// text returns the text of a cell for a scalar value.
+func text(v cue.Value) (string, error) {
+	switch v.Kind() {
+	case cue.NullKind:
+		return "", nil
+	case cue.StringKind:
+		return v.String()
+	case cue.BoolKind, cue.IntKind, cue.FloatKind:
+		b, err := v.MarshalJSON()
+		return string(b), err
+	}
+	if err := v.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.Newf(v.Pos(), "value must be null, a boolean, a number, or a string")
+}
@@ -27,8 +27,16 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/encoding/hcl"
 	"cuelang.org/go/encoding/openapi"
+	"cuelang.org/go/encoding/typescript"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/encoding/csv"
+	"cuelang.org/go/internal/encoding/env"
+	cuejson "cuelang.org/go/internal/encoding/json"
+	"cuelang.org/go/internal/encoding/properties"
+	"cuelang.org/go/internal/encoding/xml"
+	cueyaml "cuelang.org/go/internal/encoding/yaml"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/pkg/encoding/yaml"
 )
@@ -39,6 +47,7 @@ type Encoder struct {
 	cfg          *Config
 	closer       io.Closer
 	interpret    func(*cue.Instance) (*ast.File, error)
+	encInst      func(*cue.Instance) error
 	encFile      func(*ast.File) error
 	encValue     func(cue.Value) error
 	autoSimplify bool
@@ -135,6 +144,23 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 		e.encFile = func(f *ast.File) error { return format(f.Filename, f) }
 
 	case build.JSON, build.JSONL:
+		if f.Tags["canonical"] == "true" {
+			streamed := false
+			e.encValue = func(v cue.Value) error {
+				if streamed {
+					fmt.Fprintln(w)
+				}
+				streamed = true
+
+				b, err := canonicalJSON(v)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+			break
+		}
 		d := json.NewEncoder(w)
 		d.SetIndent("", "    ")
 		d.SetEscapeHTML(cfg.EscapeHTML)
@@ -147,6 +173,7 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 		}
 
 	case build.YAML:
+		docs := f.Tags["docs"] == "true"
 		streamed := false
 		e.encValue = func(v cue.Value) error {
 			if streamed {
@@ -154,6 +181,15 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			}
 			streamed = true
 
+			if docs {
+				b, err := yamlWithDocs(v)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+
 			str, err := yaml.Marshal(v)
 			if err != nil {
 				return err
@@ -162,6 +198,56 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return err
 		}
 
+	case build.HCL:
+		e.encValue = func(v cue.Value) error {
+			b, err := hcl.Encode(v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
+	case build.XML:
+		e.encValue = func(v cue.Value) error {
+			b, err := xml.Encode(v.Syntax(cue.Final()))
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
+	case build.Env:
+		e.encValue = func(v cue.Value) error {
+			b, err := env.Encode(v.Syntax(cue.Final()))
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
+	case build.Properties:
+		e.encValue = func(v cue.Value) error {
+			b, err := properties.Encode(v.Syntax(cue.Final()))
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
+	case build.CSV, build.TSV:
+		e.encValue = func(v cue.Value) error {
+			b, err := csv.Encode(v, csvConfig(f, cfg))
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
 	case build.Text:
 		e.encValue = func(v cue.Value) error {
 			s, err := v.String()
@@ -176,6 +262,21 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return err
 		}
 
+	case build.Code:
+		switch lang := f.Tags["lang"]; lang {
+		case "ts":
+			e.encInst = func(inst *cue.Instance) error {
+				b, err := typescript.Generate(inst, nil)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported language %q for encoding code", lang)
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported encoding %q", f.Encoding)
 	}
@@ -183,6 +284,24 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 	return e, nil
 }
 
+// yamlWithDocs encodes v as YAML, writing its doc comments as YAML comments.
+func yamlWithDocs(v cue.Value) ([]byte, error) {
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	return cueyaml.Encode(v.Syntax(cue.Final(), cue.Concrete(true), cue.Docs(true)))
+}
+
+// canonicalJSON encodes v as canonical JSON as defined by RFC 8785. The
+// result is not terminated by a newline, so that it can be hashed or signed
+// as is.
+func canonicalJSON(v cue.Value) ([]byte, error) {
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	return cuejson.Encode(v.Syntax(cue.Final(), cue.Concrete(true)), cuejson.Canonical())
+}
+
 func (e *Encoder) EncodeFile(f *ast.File) error {
 	e.autoSimplify = false
 	return e.encodeFile(f, e.interpret)
@@ -190,6 +309,9 @@ func (e *Encoder) EncodeFile(f *ast.File) error {
 
 func (e *Encoder) Encode(inst *cue.Instance) error {
 	e.autoSimplify = true
+	if e.encInst != nil {
+		return e.encInst(inst)
+	}
 	if e.interpret != nil {
 		f, err := e.interpret(inst)
 		if err != nil {
@@ -213,7 +335,7 @@ func (e *Encoder) encodeFile(f *ast.File, interpret func(*cue.Instance) (*ast.Fi
 	if err != nil {
 		return err
 	}
-	if interpret != nil {
+	if interpret != nil || e.encInst != nil {
 		return e.Encode(inst)
 	}
 	return e.encValue(inst.Value())
@@ -33,11 +33,17 @@ import (
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/encoding/hcl"
 	"cuelang.org/go/encoding/json"
 	"cuelang.org/go/encoding/jsonschema"
 	"cuelang.org/go/encoding/openapi"
 	"cuelang.org/go/encoding/protobuf"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/encoding/csv"
+	"cuelang.org/go/internal/encoding/env"
+	"cuelang.org/go/internal/encoding/jsonc"
+	"cuelang.org/go/internal/encoding/properties"
+	"cuelang.org/go/internal/encoding/xml"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/internal/third_party/yaml"
 )
@@ -140,6 +146,10 @@ type Config struct {
 	ProtoPath  []string
 	Format     []format.Option
 	ParseFile  func(name string, src interface{}) (*ast.File, error)
+
+	// Schema, if it exists, is used to determine the types of values of
+	// formats that do not encode them, such as CSV.
+	Schema cue.Value
 }
 
 // NewDecoder returns a stream of non-rooted data expressions. The encoding
@@ -208,15 +218,49 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 	case build.JSON, build.JSONL:
 		i.next = json.NewDecoder(nil, path, r).Extract
 		i.Next()
+	case build.JSONC:
+		i.expr, i.err = jsonc.Extract(path, r)
+		if i.err == nil {
+			i.doInterpret()
+		}
 	case build.YAML:
 		d, err := yaml.NewDecoder(path, r)
 		i.err = err
+		if err == nil && f.Tags["refs"] == "true" {
+			d.UseReferences()
+		}
+		if err == nil && f.Tags["version"] == "1.2" {
+			d.UseCoreSchema()
+		}
 		i.next = d.Decode
 		i.Next()
 	case build.Text:
 		b, err := ioutil.ReadAll(r)
 		i.err = err
 		i.expr = ast.NewString(string(b))
+	case build.HCL:
+		i.file, i.err = hcl.Extract(path, r)
+		if i.err == nil {
+			i.doInterpret()
+		}
+	case build.XML:
+		i.file, i.err = xml.Extract(path, r)
+		if i.err == nil {
+			i.doInterpret()
+		}
+	case build.Env:
+		i.file, i.err = env.Extract(path, r)
+		if i.err == nil {
+			i.doInterpret()
+		}
+	case build.Properties:
+		i.file, i.err = properties.Extract(path, r)
+		if i.err == nil {
+			i.doInterpret()
+		}
+	case build.CSV, build.TSV:
+		i.next = csv.NewDecoder(path, r, csvConfig(f, cfg)).Extract
+		i.Next()
 	case build.Protobuf:
 		paths := &protobuf.Config{
 			Paths:   cfg.ProtoPath,
@@ -230,6 +274,14 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 	return i
 }
 
+func csvConfig(f *build.File, cfg *Config) *csv.Config {
+	c := &csv.Config{Schema: cfg.Schema}
+	if f.Encoding == build.TSV {
+		c.Comma = '\t'
+	}
+	return c
+}
+
 func jsonSchemaFunc(cfg *Config, f *build.File) interpretFunc {
 	return func(i *cue.Instance) (file *ast.File, id string, err error) {
 		id = f.Tags["id"]
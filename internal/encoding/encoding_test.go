@@ -15,12 +15,15 @@
 package encoding
 
 import (
+	"bytes"
 	"path"
 	"strings"
 	"testing"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/filetypes"
 )
 
 func TestValidate(t *testing.T) {
@@ -84,3 +87,71 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeYAMLDocs(t *testing.T) {
+	r := &cue.Runtime{}
+	inst, err := r.Compile("test", `
+	// Doc of a.
+	a: 1
+	b: {
+		// Doc of c.
+		c: "foo"
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		tags map[string]string
+		want string
+	}{{
+		want: "a: 1\nb:\n    c: foo\n",
+	}, {
+		tags: map[string]string{"docs": "true"},
+		want: "# Doc of a.\na: 1\nb:\n    # Doc of c.\n    c: foo\n",
+	}}
+	for _, tc := range testCases {
+		buf := &bytes.Buffer{}
+		e, err := NewEncoder(&build.File{
+			Filename: "-",
+			Encoding: build.YAML,
+			Tags:     tc.tags,
+		}, &Config{Mode: filetypes.Export, Out: buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Encode(inst); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+		}
+	}
+}
+
+func TestEncodeCanonicalJSON(t *testing.T) {
+	r := &cue.Runtime{}
+	inst, err := r.Compile("test", `
+	b: " "
+	a: [1.0, 1e21, {d: 1, c: 2}]
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	e, err := NewEncoder(&build.File{
+		Filename: "-",
+		Encoding: build.JSON,
+		Tags:     map[string]string{"canonical": "true"},
+	}, &Config{Mode: filetypes.Export, Out: buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(inst); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"a\":[1,1e+21,{\"c\":2,\"d\":1}],\"b\":\" \"}"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
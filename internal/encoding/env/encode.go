@@ -0,0 +1,169 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// Encode converts a CUE AST to a dotenv file.
+//
+// The given node must be a File or StructLit of which all fields are
+// regular, have a valid variable name, and have a null, boolean, number, or
+// string value. Null is converted to an empty value.
+func Encode(n ast.Node) ([]byte, error) {
+	var decls []ast.Decl
+	switch x := n.(type) {
+	case *ast.File:
+		decls = x.Decls
+	case *ast.StructLit:
+		decls = x.Elts
+	default:
+		return nil, errors.Newf(n.Pos(), "env: top-level value must be a struct, found %s", internal.DebugStr(n))
+	}
+
+	var buf bytes.Buffer
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Package:
+			continue
+
+		case *ast.CommentGroup:
+			writeComments(&buf, x)
+
+		case *ast.Field:
+			name, err := fieldName(x)
+			if err != nil {
+				return nil, err
+			}
+			s, err := text(name, x.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range ast.Comments(x) {
+				if !c.Line && c.Position == 0 {
+					writeComments(&buf, c)
+				}
+			}
+			buf.WriteString(name)
+			buf.WriteByte('=')
+			buf.WriteString(quote(s))
+			for _, c := range ast.Comments(x) {
+				if c.Line || c.Position > 0 {
+					buf.WriteString(" #")
+					buf.WriteString(strings.TrimPrefix(c.List[0].Text, "//"))
+				}
+			}
+			buf.WriteByte('\n')
+
+		default:
+			return nil, errors.Newf(d.Pos(), "env: unsupported node %s (%T)", internal.DebugStr(d), d)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func fieldName(x *ast.Field) (string, error) {
+	if x.Token == token.ISA {
+		return "", errors.Newf(x.TokenPos, "env: definition not allowed")
+	}
+	if x.Optional != token.NoPos {
+		return "", errors.Newf(x.Optional, "env: optional fields not allowed")
+	}
+	name, _, err := ast.LabelName(x.Label)
+	if err != nil {
+		return "", errors.Newf(x.Label.Pos(), "env: only literal labels allowed")
+	}
+	if !isName(name) {
+		return "", errors.Newf(x.Label.Pos(), "env: invalid variable name %q", name)
+	}
+	return name, nil
+}
+
+// text returns the text of the value of the variable with the given name.
+func text(name string, v ast.Expr) (string, error) {
+	switch x := v.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			return literal.Unquote(x.Value)
+		case token.INT, token.FLOAT:
+			var ni literal.NumInfo
+			if err := literal.ParseNum(x.Value, &ni); err != nil {
+				return "", err
+			}
+			return ni.String(), nil
+		case token.TRUE, token.FALSE:
+			return x.Value, nil
+		case token.NULL:
+			return "", nil
+		}
+
+	case *ast.UnaryExpr:
+		if b, ok := x.X.(*ast.BasicLit); ok && x.Op == token.SUB {
+			s, err := text(name, b)
+			return "-" + s, err
+		}
+
+	case *ast.StructLit:
+		return "", errors.Newf(v.Pos(), "env: struct value not allowed for variable %q", name)
+
+	case *ast.ListLit:
+		return "", errors.Newf(v.Pos(), "env: list value not allowed for variable %q", name)
+	}
+	return "", errors.Newf(v.Pos(), "env: unsupported value %s (%T) for variable %q", internal.DebugStr(v), v, name)
+}
+
+// quote returns s as a double-quoted string if it cannot be written as an
+// unquoted value.
+func quote(s string) string {
+	if !strings.ContainsAny(s, " \t\r\n#\"'\\$`=") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func writeComments(buf *bytes.Buffer, cg *ast.CommentGroup) {
+	for _, c := range cg.List {
+		buf.WriteByte('#')
+		buf.WriteString(strings.TrimPrefix(c.Text, "//"))
+		buf.WriteByte('\n')
+	}
+}
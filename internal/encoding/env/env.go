@@ -0,0 +1,328 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package env converts dotenv files to and from CUE.
+//
+// A dotenv file consists of lines of the form
+//
+//	[export] NAME=value
+//
+// Each variable is converted to a field of a flat struct holding a string.
+// Values may be unquoted, single-quoted, or double-quoted. Unquoted values
+// end at the end of the line or at a # preceded by white space, which
+// starts a comment. Single-quoted values are taken literally. Double-quoted
+// values may contain the escape sequences \n, \r, \t, \", \\, and \$. Quoted
+// values may span multiple lines. Variable references are not expanded.
+//
+// Comments starting with # are retained.
+package env
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/source"
+)
+
+// Extract parses the dotenv source to a CUE file.
+//
+// If src != nil, Extract parses the source from src and the filename is only
+// used when recording position information. The type of the argument for
+// the src parameter must be string, []byte, or io.Reader. If src == nil,
+// Extract parses the file specified by filename.
+func Extract(filename string, src interface{}) (f *ast.File, err error) {
+	b, err := source.Read(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		file: token.NewFile(filename, -1, len(b)),
+		src:  b,
+		seen: map[string]bool{},
+	}
+	d.file.SetLinesForContent(b)
+
+	defer func() {
+		if x := recover(); x != nil {
+			e, ok := x.(*parseError)
+			if !ok {
+				panic(x)
+			}
+			f, err = nil, e.err
+		}
+	}()
+
+	f = &ast.File{Filename: filename}
+	f.Decls = d.decls()
+	return f, nil
+}
+
+type parseError struct{ err errors.Error }
+
+type decoder struct {
+	file   *token.File
+	src    []byte
+	offset int
+	seen   map[string]bool
+
+	comments *ast.CommentGroup // pending comments
+	blank    bool              // a blank line precedes the current line
+	started  bool              // a comment or field has been converted
+}
+
+func (d *decoder) errf(offset int, format string, args ...interface{}) {
+	pos := d.file.Pos(offset, token.NoRelPos)
+	panic(&parseError{errors.Newf(pos, "env: "+format, args...)})
+}
+
+// rel returns the relative position of the line starting at offset.
+func (d *decoder) rel(offset int) token.Pos {
+	rel := token.Newline
+	switch {
+	case !d.started:
+		rel = token.NoRelPos
+	case d.blank:
+		rel = token.NewSection
+	}
+	d.started = true
+	d.blank = false
+	return d.file.Pos(offset, rel)
+}
+
+func (d *decoder) decls() []ast.Decl {
+	var decls []ast.Decl
+	flush := func() {
+		if d.comments != nil {
+			decls = append(decls, d.comments)
+			d.comments = nil
+		}
+	}
+	for d.offset < len(d.src) {
+		d.skipSpace()
+		start := d.offset
+		switch {
+		case d.offset >= len(d.src):
+
+		case d.src[d.offset] == '\n':
+			flush()
+			d.blank = true
+			d.offset++
+
+		case d.src[d.offset] == '#':
+			text := d.comment()
+			if d.comments == nil {
+				d.comments = &ast.CommentGroup{}
+			}
+			c := &ast.Comment{Slash: d.rel(start), Text: text}
+			d.comments.List = append(d.comments.List, c)
+			d.skipLine()
+
+		default:
+			field := d.field()
+			if d.comments != nil {
+				d.comments.Doc = true
+				ast.AddComment(field, d.comments)
+				d.comments = nil
+			}
+			d.lineComment(field)
+			decls = append(decls, field)
+		}
+	}
+	flush()
+	return decls
+}
+
+// field parses an assignment.
+func (d *decoder) field() *ast.Field {
+	start := d.offset
+	if d.hasPrefix("export") {
+		d.offset += len("export")
+		if d.offset < len(d.src) && (d.src[d.offset] == ' ' || d.src[d.offset] == '\t') {
+			d.skipSpace()
+			start = d.offset
+		} else {
+			d.offset = start
+		}
+	}
+
+	for d.offset < len(d.src) && isNameChar(d.src[d.offset], d.offset == start) {
+		d.offset++
+	}
+	name := string(d.src[start:d.offset])
+	if name == "" {
+		d.errf(start, "invalid variable name")
+	}
+	if d.seen[name] {
+		d.errf(start, "variable %q redefined", name)
+	}
+	d.seen[name] = true
+
+	d.skipSpace()
+	if d.offset >= len(d.src) || d.src[d.offset] != '=' {
+		d.errf(d.offset, "expected '=' after %s", name)
+	}
+	d.offset++
+	d.skipSpace()
+
+	valueStart := d.offset
+	value := ast.NewString(d.value())
+	value.ValuePos = d.file.Pos(valueStart, token.Blank)
+
+	return &ast.Field{
+		Label: label(name, d.rel(start)),
+		Value: value,
+	}
+}
+
+// value parses the value of an assignment, leaving the offset at the end of
+// the line or the start of a line comment.
+func (d *decoder) value() string {
+	start := d.offset
+	if start >= len(d.src) {
+		return ""
+	}
+	switch q := d.src[start]; q {
+	case '"', '\'':
+		var buf strings.Builder
+		d.offset++
+		for {
+			if d.offset >= len(d.src) {
+				d.errf(start, "quoted value not terminated")
+			}
+			c := d.src[d.offset]
+			d.offset++
+			if c == q {
+				break
+			}
+			if c == '\\' && q == '"' && d.offset < len(d.src) {
+				switch e := d.src[d.offset]; e {
+				case 'n':
+					c = '\n'
+				case 'r':
+					c = '\r'
+				case 't':
+					c = '\t'
+				case '"', '\\', '$':
+					c = e
+				default:
+					buf.WriteByte('\\')
+					continue
+				}
+				d.offset++
+			}
+			buf.WriteByte(c)
+		}
+		d.skipSpace()
+		if d.offset < len(d.src) && d.src[d.offset] != '\n' && d.src[d.offset] != '#' {
+			d.errf(d.offset, "unexpected text after quoted value")
+		}
+		return buf.String()
+
+	default:
+		end := d.offset
+		for d.offset < len(d.src) && d.src[d.offset] != '\n' {
+			c := d.src[d.offset]
+			if c == '#' && d.offset > start && isSpace(d.src[d.offset-1]) {
+				break
+			}
+			d.offset++
+			if !isSpace(c) {
+				end = d.offset
+			}
+		}
+		return string(d.src[start:end])
+	}
+}
+
+// comment returns the comment at the current offset as a CUE comment and
+// advances to the end of the line.
+func (d *decoder) comment() string {
+	start := d.offset + 1
+	end := bytes.IndexByte(d.src[start:], '\n')
+	if end < 0 {
+		end = len(d.src)
+	} else {
+		end += start
+	}
+	d.offset = end
+	text := strings.TrimRight(string(d.src[start:end]), " \t\r")
+	return "//" + text
+}
+
+// lineComment attaches a comment that follows a value on the same line.
+func (d *decoder) lineComment(n ast.Node) {
+	if d.offset >= len(d.src) || d.src[d.offset] != '#' {
+		d.skipLine()
+		return
+	}
+	pos := d.file.Pos(d.offset, token.Blank)
+	ast.AddComment(n, &ast.CommentGroup{
+		Line:     true,
+		Position: 5,
+		List:     []*ast.Comment{{Slash: pos, Text: d.comment()}},
+	})
+	d.skipLine()
+}
+
+func (d *decoder) skipLine() {
+	for d.offset < len(d.src) && d.src[d.offset] != '\n' {
+		if !isSpace(d.src[d.offset]) {
+			d.errf(d.offset, "unexpected text after value")
+		}
+		d.offset++
+	}
+	if d.offset < len(d.src) {
+		d.offset++
+	}
+}
+
+func (d *decoder) skipSpace() {
+	for d.offset < len(d.src) && isSpace(d.src[d.offset]) {
+		d.offset++
+	}
+}
+
+func (d *decoder) hasPrefix(s string) bool {
+	return bytes.HasPrefix(d.src[d.offset:], []byte(s))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+		!first && '0' <= c && c <= '9'
+}
+
+// isName reports whether s is a valid variable name.
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func label(name string, pos token.Pos) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
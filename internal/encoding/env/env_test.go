@@ -0,0 +1,198 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  string
+		want string
+	}{{
+		name: "values",
+		env: `
+A=plain value
+export B = 'single $X \n'
+C="double \"q\"\n\$X"
+D=
+E=a#b # comment
+`,
+		want: `
+A: "plain value"
+B: "single $X \\n"
+C: "double \"q\"\n$X"
+D: ""
+E: "a#b" // comment`,
+	}, {
+		name: "comments",
+		env: `# Doc comment.
+A=1
+
+# Standalone.
+
+B="multi
+line"
+`,
+		want: `
+// Doc comment.
+A: "1"
+
+// Standalone.
+
+B: "multi\nline"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Extract(tc.name, tc.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		env string
+		pos string
+		err string
+	}{{
+		env: "A=1\nA=2\n",
+		pos: "test:2:1",
+		err: `env: variable "A" redefined`,
+	}, {
+		env: "A-B=1\n",
+		pos: "test:1:2",
+		err: "env: expected '=' after A",
+	}, {
+		env: "=1\n",
+		pos: "test:1:1",
+		err: "env: invalid variable name",
+	}, {
+		env: "A=\"foo\n",
+		pos: "test:1:3",
+		err: "env: quoted value not terminated",
+	}, {
+		env: "A='foo' bar\n",
+		pos: "test:1:9",
+		err: "env: unexpected text after quoted value",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.env, func(t *testing.T) {
+			_, err := Extract("test", tc.env)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+			if got := err.(errors.Error).Position().String(); got != tc.pos {
+				t.Errorf("got position %s; want %s", got, tc.pos)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	r := &cue.Runtime{}
+	inst, err := r.Compile("test", `
+A: "plain"
+B: "with space and \"quotes\"\n$HOME"
+C: 8080
+D: true
+F: -1.5
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encode(inst.Value().Syntax(cue.Final()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `A=plain
+B="with space and \"quotes\"\n\$HOME"
+C=8080
+D=true
+F=-1.5
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Converting back must yield the same strings.
+	f, err := Extract("test", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := r.CompileFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := back.Lookup("B").String()
+	if want, _ := inst.Lookup("B").String(); got != want {
+		t.Errorf("round trip: got %q; want %q", got, want)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	testCases := []struct {
+		cue string
+		err string
+	}{{
+		cue: `[1]`,
+		err: "env: top-level value must be a struct, found [1]",
+	}, {
+		cue: `a: b: 1`,
+		err: `env: struct value not allowed for variable "a"`,
+	}, {
+		cue: `a: [1]`,
+		err: `env: list value not allowed for variable "a"`,
+	}, {
+		cue: `"a-b": 1`,
+		err: `env: invalid variable name "a-b"`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			inst, err := r.Compile("test", tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Encode(inst.Value().Syntax(cue.Final()))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,539 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hcl
+
+import (
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// A decoder converts HCL native syntax to a CUE AST.
+type decoder struct {
+	scanner
+
+	// prevEnd is the end offset of the last token or comment that was
+	// converted. It is used to compute relative positions.
+	prevEnd int
+}
+
+// file converts the body of an HCL file.
+func (d *decoder) file(filename string) *ast.File {
+	d.prevEnd = -1
+	d.next()
+	f := &ast.File{Filename: filename}
+	f.Decls = d.body(false)
+	return f
+}
+
+// relPos returns the position of the given offset, relative to the last
+// converted token.
+func (d *decoder) relPos(offset int) token.Pos {
+	pos := d.pos(offset)
+	if d.prevEnd < 0 {
+		return pos
+	}
+	switch n := d.line(offset) - d.line(d.prevEnd); {
+	case n >= 2:
+		pos = pos.WithRel(token.NewSection)
+	case n == 1:
+		pos = pos.WithRel(token.Newline)
+	case offset > d.prevEnd:
+		pos = pos.WithRel(token.Blank)
+	default:
+		pos = pos.WithRel(token.NoSpace)
+	}
+	return pos
+}
+
+// advance converts the current token and moves to the next one.
+func (d *decoder) advance() {
+	d.prevEnd = d.end
+	d.next()
+}
+
+func (d *decoder) skipNewlines() {
+	for d.tok == tNewline {
+		d.next()
+	}
+}
+
+func (d *decoder) is(op string) bool {
+	return d.tok == tPunct && d.lit == op
+}
+
+func (d *decoder) expect(op string) int {
+	if !d.is(op) {
+		d.errf(d.start, "expected %q, found %s", op, d.describe())
+	}
+	start := d.start
+	d.advance()
+	return start
+}
+
+func (d *decoder) describe() string {
+	switch d.tok {
+	case tEOF:
+		return "end of file"
+	case tNewline:
+		return "newline"
+	case tString, tHeredoc:
+		return "string"
+	}
+	return strconv.Quote(string(d.src[d.start:d.end]))
+}
+
+// commentGroups returns the pending comments that start before offset end as
+// comment groups. Comments on consecutive lines form a single group.
+func (d *decoder) commentGroups(end int) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	lastLine := -1
+	for len(d.comments) > 0 && d.comments[0].start < end {
+		c := d.comments[0]
+		d.comments = d.comments[1:]
+
+		line := d.line(c.start)
+		if line > lastLine+1 || len(groups) == 0 {
+			groups = append(groups, &ast.CommentGroup{})
+		}
+		cg := groups[len(groups)-1]
+		for i, text := range c.text {
+			pos := token.NoPos
+			if i == 0 {
+				pos = d.relPos(c.start)
+			}
+			cg.List = append(cg.List, &ast.Comment{Slash: pos, Text: text})
+		}
+		d.prevEnd = c.end
+		lastLine = d.line(c.end)
+	}
+	return groups
+}
+
+// docComments attaches the pending comments that precede the element
+// starting at offset start. A comment group that ends on the line directly
+// before the element becomes its doc comment. Other groups are returned.
+func (d *decoder) docComments(start int, n ast.Node) (rest []*ast.CommentGroup) {
+	groups := d.commentGroups(start)
+	if len(groups) == 0 {
+		return nil
+	}
+	last := groups[len(groups)-1]
+	if d.line(d.prevEnd)+1 == d.line(start) {
+		last.Doc = true
+		ast.AddComment(n, last)
+		return groups[:len(groups)-1]
+	}
+	return groups
+}
+
+// lineComment attaches a pending comment that starts on the line on which
+// the element ends as a line comment to n.
+func (d *decoder) lineComment(n ast.Node) {
+	if len(d.comments) == 0 || d.prevEnd < 0 {
+		return
+	}
+	c := d.comments[0]
+	if d.line(c.start) != d.line(d.prevEnd) || len(c.text) > 1 {
+		return
+	}
+	d.comments = d.comments[1:]
+	ast.AddComment(n, &ast.CommentGroup{
+		Line:     true,
+		Position: 5,
+		List: []*ast.Comment{{
+			Slash: d.pos(c.start).WithRel(token.Blank),
+			Text:  c.text[0],
+		}},
+	})
+	d.prevEnd = c.end
+}
+
+// body converts the attributes and blocks of a body up to the end of the
+// file or, if block is true, the closing brace of a block.
+func (d *decoder) body(block bool) []ast.Decl {
+	var decls []ast.Decl
+	attrs := map[string]bool{}
+	blocks := map[string]*ast.Field{}
+
+	for {
+		d.skipNewlines()
+		if d.tok == tEOF || block && d.is("}") {
+			for _, cg := range d.commentGroups(d.start) {
+				decls = append(decls, cg)
+			}
+			if d.tok == tEOF && block {
+				d.errf(d.start, "expected \"}\", found end of file")
+			}
+			return decls
+		}
+		if d.tok != tIdent {
+			d.errf(d.start, "expected attribute or block, found %s", d.describe())
+		}
+
+		field := &ast.Field{}
+		for _, cg := range d.docComments(d.start, field) {
+			decls = append(decls, cg)
+		}
+		name, nameStart := d.lit, d.start
+		field.Label = d.label(name, d.relPos(nameStart))
+		d.advance()
+
+		if d.is("=") {
+			if attrs[name] {
+				d.errf(nameStart, "attribute %q redefined", name)
+			}
+			attrs[name] = true
+			field.Value = d.valueAfter()
+			if isBlockValue(field.Value) {
+				field.Attrs = append(field.Attrs, &ast.Attribute{Text: attrAttr})
+			}
+			d.endItem(field)
+			decls = append(decls, field)
+			continue
+		}
+
+		// Block with optional labels.
+		key := name
+		inner := field
+		for d.tok == tIdent || d.tok == tString {
+			label := d.label(d.lit, d.relPos(d.start))
+			key += "\x00" + d.lit
+			d.advance()
+			f := &ast.Field{Label: label}
+			inner.Value = &ast.StructLit{Elts: []ast.Decl{f}}
+			inner = f
+		}
+		if !d.is("{") {
+			d.errf(d.start, "expected block body, found %s", d.describe())
+		}
+		body := &ast.StructLit{Lbrace: d.relPos(d.start).WithRel(token.Blank)}
+		d.advance()
+		body.Elts = d.body(true)
+		body.Rbrace = d.relPos(d.start)
+		d.advance()
+
+		if prev, ok := blocks[key]; ok {
+			// Repeated blocks of the same type and labels are collected
+			// in a list.
+			list, ok := prev.Value.(*ast.ListLit)
+			if !ok {
+				first := prev.Value.(*ast.StructLit)
+				first.Lbrace = first.Lbrace.WithRel(token.NoSpace)
+				list = &ast.ListLit{Elts: []ast.Expr{first}}
+				prev.Value = list
+			}
+			for _, cg := range field.Comments() {
+				body.AddComment(cg)
+			}
+			list.Elts = append(list.Elts, body)
+			d.endItem(body)
+			continue
+		}
+		blocks[key] = inner
+		inner.Value = body
+		d.endItem(field)
+		decls = append(decls, field)
+	}
+}
+
+// endItem processes the end of an attribute or block, which must be
+// followed by a newline, unless it is the last item of a one-line block.
+func (d *decoder) endItem(n ast.Node) {
+	d.lineComment(n)
+	switch {
+	case d.tok == tNewline, d.tok == tEOF, d.is("}"):
+	default:
+		d.errf(d.start, "expected newline, found %s", d.describe())
+	}
+}
+
+// label returns the CUE label for an HCL name.
+func (d *decoder) label(name string, pos token.Pos) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
+
+// valueAfter converts the expression following the current token, which
+// is an equals sign or colon.
+func (d *decoder) valueAfter() ast.Expr {
+	d.advance()
+	return d.value()
+}
+
+// value converts an expression. Expressions that are not literal values,
+// such as references and function calls, are converted to a string holding
+// the expression as a template interpolation.
+func (d *decoder) value() ast.Expr {
+	start := d.start
+	rel := d.relPos(start)
+	x := d.expr()
+	if x != nil {
+		return x
+	}
+	src := strings.TrimSpace(string(d.src[start:d.prevEnd]))
+	return &ast.BasicLit{
+		ValuePos: rel,
+		Kind:     token.STRING,
+		Value:    strconv.Quote("${" + src + "}"),
+	}
+}
+
+// expr parses an expression and returns its CUE equivalent, or nil if it is
+// not a literal value.
+func (d *decoder) expr() ast.Expr {
+	x := d.binaryExpr()
+	if d.is("?") {
+		d.advance()
+		d.skipNewlines()
+		d.expr()
+		d.skipNewlines()
+		d.expect(":")
+		d.skipNewlines()
+		d.expr()
+		return nil
+	}
+	return x
+}
+
+var binaryOps = map[string]bool{
+	"||": true, "&&": true, "==": true, "!=": true,
+	"<": true, "<=": true, ">": true, ">=": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true,
+}
+
+func (d *decoder) binaryExpr() ast.Expr {
+	x := d.unaryExpr()
+	for d.tok == tPunct && binaryOps[d.lit] {
+		d.advance()
+		d.skipNewlines()
+		d.unaryExpr()
+		x = nil
+	}
+	return x
+}
+
+func (d *decoder) unaryExpr() ast.Expr {
+	if d.is("-") || d.is("!") {
+		op, pos := d.lit, d.relPos(d.start)
+		d.advance()
+		x := d.unaryExpr()
+		if lit, ok := x.(*ast.BasicLit); ok && op == "-" &&
+			(lit.Kind == token.INT || lit.Kind == token.FLOAT) {
+			return &ast.UnaryExpr{OpPos: pos, Op: token.SUB, X: lit}
+		}
+		return nil
+	}
+	return d.postfixExpr()
+}
+
+func (d *decoder) postfixExpr() ast.Expr {
+	x := d.primaryExpr()
+	for {
+		switch {
+		case d.is("."):
+			d.advance()
+			if d.is("*") || d.tok == tIdent || d.tok == tNumber {
+				d.advance()
+			} else {
+				d.errf(d.start, "expected attribute name, found %s", d.describe())
+			}
+			x = nil
+
+		case d.is("["):
+			d.advance()
+			d.skipNewlines()
+			if d.is("*") {
+				d.advance()
+			} else {
+				d.expr()
+			}
+			d.skipNewlines()
+			d.expect("]")
+			x = nil
+
+		default:
+			return x
+		}
+	}
+}
+
+func (d *decoder) primaryExpr() ast.Expr {
+	start := d.start
+	switch d.tok {
+	case tNumber:
+		lit := &ast.BasicLit{ValuePos: d.relPos(start), Kind: token.INT, Value: d.lit}
+		if strings.ContainsAny(d.lit, ".eE") {
+			lit.Kind = token.FLOAT
+		}
+		d.advance()
+		return lit
+
+	case tString, tHeredoc:
+		lit := ast.NewString(d.lit)
+		lit.ValuePos = d.relPos(start)
+		d.advance()
+		return lit
+
+	case tIdent:
+		name := d.lit
+		pos := d.relPos(start)
+		d.advance()
+		switch name {
+		case "true":
+			return &ast.BasicLit{ValuePos: pos, Kind: token.TRUE, Value: name}
+		case "false":
+			return &ast.BasicLit{ValuePos: pos, Kind: token.FALSE, Value: name}
+		case "null":
+			return &ast.BasicLit{ValuePos: pos, Kind: token.NULL, Value: name}
+		}
+		if d.is("(") {
+			d.skipBalanced()
+		}
+		return nil
+
+	case tPunct:
+		switch d.lit {
+		case "(":
+			d.skipBalanced()
+			return nil
+		case "[":
+			return d.tuple()
+		case "{":
+			return d.object()
+		}
+	}
+	d.errf(start, "expected expression, found %s", d.describe())
+	return nil
+}
+
+// skipBalanced skips tokens up to and including the closing delimiter
+// matching the current opening delimiter.
+func (d *decoder) skipBalanced() {
+	start := d.start
+	depth := 0
+	for {
+		switch {
+		case d.tok == tEOF:
+			d.errf(start, "unbalanced %q", string(d.src[start]))
+		case d.is("("), d.is("["), d.is("{"):
+			depth++
+		case d.is(")"), d.is("]"), d.is("}"):
+			depth--
+		}
+		d.advance()
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// isFor reports whether the current delimiter starts a for expression.
+func (d *decoder) isFor() bool {
+	s := d.scanner
+	s.comments = nil
+	s.next()
+	for s.tok == tNewline {
+		s.next()
+	}
+	return s.tok == tIdent && s.lit == "for"
+}
+
+func (d *decoder) tuple() ast.Expr {
+	if d.isFor() {
+		d.skipBalanced()
+		return nil
+	}
+	list := &ast.ListLit{Lbrack: d.relPos(d.start)}
+	d.advance()
+	for {
+		d.skipNewlines()
+		if d.is("]") {
+			break
+		}
+		groups := d.commentGroups(d.start)
+		elem := d.value()
+		for _, cg := range groups {
+			cg.Doc = true
+			elem.AddComment(cg)
+		}
+		list.Elts = append(list.Elts, elem)
+		d.skipNewlines()
+		if !d.is(",") {
+			break
+		}
+		d.advance()
+		d.lineComment(elem)
+	}
+	d.skipNewlines()
+	list.Rbrack = d.relPos(d.start)
+	d.expect("]")
+	return list
+}
+
+func (d *decoder) object() ast.Expr {
+	if d.isFor() {
+		d.skipBalanced()
+		return nil
+	}
+	obj := &ast.StructLit{Lbrace: d.relPos(d.start)}
+	d.advance()
+	keys := map[string]bool{}
+	for {
+		d.skipNewlines()
+		if d.is("}") {
+			break
+		}
+		field := &ast.Field{}
+		for _, cg := range d.docComments(d.start, field) {
+			obj.Elts = append(obj.Elts, cg)
+		}
+
+		if d.tok != tIdent && d.tok != tString {
+			d.errf(d.start, "object keys other than names and strings are not supported")
+		}
+		name, pos := d.lit, d.relPos(d.start)
+		field.Label = d.label(name, pos)
+		d.advance()
+		if keys[name] {
+			d.errf(pos.Offset(), "duplicate object key %q", name)
+		}
+		keys[name] = true
+
+		if !d.is("=") && !d.is(":") {
+			d.errf(d.start, "expected \"=\" or \":\", found %s", d.describe())
+		}
+		field.Value = d.valueAfter()
+		obj.Elts = append(obj.Elts, field)
+
+		if d.is(",") {
+			d.advance()
+		}
+		d.lineComment(field)
+		if d.tok != tNewline && !d.is("}") {
+			d.errf(d.start, "expected newline or \",\", found %s", d.describe())
+		}
+	}
+	for _, cg := range d.commentGroups(d.start) {
+		obj.Elts = append(obj.Elts, cg)
+	}
+	obj.Rbrace = d.relPos(d.start)
+	d.advance()
+	return obj
+}
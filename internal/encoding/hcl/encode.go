@@ -0,0 +1,516 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// encode converts a CUE AST to HCL.
+//
+// The given node must be a File or StructLit and only contain values that
+// can be directly supported by HCL:
+//
+//	Type          Restrictions
+//	BasicLit
+//	File          no imports, aliases, or definitions
+//	StructLit     no embeddings, aliases, or definitions
+//	List
+//	Field         must be regular; label must be a BasicLit or Ident
+//	CommentGroup
+func encode(n ast.Node) (b []byte, err error) {
+	e := &encoder{}
+	switch x := n.(type) {
+	case *ast.File:
+		err = e.body(x.Decls)
+	case *ast.StructLit:
+		err = e.body(x.Elts)
+	default:
+		return nil, errors.Newf(n.Pos(), "hcl: top-level value must be a struct, found %s", internal.DebugStr(n))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Len() > 0 {
+		e.WriteByte('\n')
+	}
+	return e.Bytes(), nil
+}
+
+type encoder struct {
+	bytes.Buffer
+	indent int
+}
+
+func (e *encoder) newline() {
+	e.WriteByte('\n')
+	for i := 0; i < e.indent; i++ {
+		e.WriteString("  ")
+	}
+}
+
+// body writes the attributes and blocks of a body. The current line must be
+// empty and indented.
+func (e *encoder) body(decls []ast.Decl) error {
+	first := true
+	wasBlock := false
+	sep := func(isBlock bool) {
+		if !first {
+			if isBlock || wasBlock {
+				e.WriteByte('\n')
+			}
+			e.newline()
+		}
+		first = false
+		wasBlock = isBlock
+	}
+
+	for i, d := range decls {
+		switch x := d.(type) {
+		default:
+			return errors.Newf(x.Pos(), "hcl: unsupported node %s (%T)", internal.DebugStr(x), x)
+
+		case *ast.Package:
+			if i > 0 {
+				return errors.Newf(x.Pos(), "invalid package clause")
+			}
+
+		case *ast.CommentGroup:
+			sep(true)
+			e.commentGroup(x)
+
+		case *ast.Field:
+			name, err := fieldName(x)
+			if err != nil {
+				return err
+			}
+			if isAttribute(x) {
+				sep(false)
+				if err := e.attribute(name, x); err != nil {
+					return err
+				}
+				continue
+			}
+			switch v := x.Value.(type) {
+			case *ast.StructLit:
+				sep(true)
+				err = e.block(name, nil, v, x)
+
+			case *ast.ListLit:
+				if !isBlockList(v) {
+					sep(false)
+					err = e.attribute(name, x)
+					break
+				}
+				for j, elem := range v.Elts {
+					sep(true)
+					var doc ast.Node
+					if j == 0 {
+						doc = x
+					}
+					err = e.block(name, nil, elem.(*ast.StructLit), doc)
+					if err != nil {
+						break
+					}
+				}
+
+			default:
+				sep(false)
+				err = e.attribute(name, x)
+			}
+			if err != nil {
+				return err
+			}
+
+		case *ast.EmbedDecl:
+			return errors.Newf(x.Pos(), "hcl: embedded values not allowed")
+		}
+	}
+	return nil
+}
+
+func fieldName(x *ast.Field) (string, error) {
+	if x.Token == token.ISA {
+		return "", errors.Newf(x.TokenPos, "hcl: definition not allowed")
+	}
+	if x.Optional != token.NoPos {
+		return "", errors.Newf(x.Optional, "hcl: optional fields not allowed")
+	}
+	name, _, err := ast.LabelName(x.Label)
+	if err != nil {
+		return "", errors.Newf(x.Label.Pos(), "hcl: only literal labels allowed")
+	}
+	return name, nil
+}
+
+// attrAttr is the CUE attribute that marks a field converted from an HCL
+// attribute whose value would otherwise be written as one or more blocks.
+const attrAttr = "@hcl(attr)"
+
+// isBlockValue reports whether x is written as one or more blocks, unless the
+// field with value x is marked as an attribute.
+func isBlockValue(x ast.Expr) bool {
+	switch v := x.(type) {
+	case *ast.StructLit:
+		return true
+	case *ast.ListLit:
+		return isBlockList(v)
+	}
+	return false
+}
+
+// isAttribute reports whether x is marked as an HCL attribute.
+func isAttribute(x *ast.Field) bool {
+	for _, a := range x.Attrs {
+		if key, body := a.Split(); key == "hcl" && strings.TrimSpace(body) == "attr" {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockList reports whether all elements of a non-empty list are structs.
+func isBlockList(x *ast.ListLit) bool {
+	for _, e := range x.Elts {
+		if _, ok := e.(*ast.StructLit); !ok {
+			return false
+		}
+	}
+	return len(x.Elts) > 0
+}
+
+// labels returns the fields of x if x is a non-empty struct of which all
+// fields are regular, not marked as attributes, and have a struct value, or
+// nil otherwise.
+func labels(x *ast.StructLit) []*ast.Field {
+	var fields []*ast.Field
+	for _, d := range x.Elts {
+		f, ok := d.(*ast.Field)
+		if !ok || f.Token == token.ISA || f.Optional != token.NoPos || isAttribute(f) {
+			return nil
+		}
+		if _, ok := f.Value.(*ast.StructLit); !ok {
+			return nil
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func (e *encoder) attribute(name string, x *ast.Field) error {
+	if !isIdent(name) {
+		return errors.Newf(x.Label.Pos(), "hcl: invalid attribute name %q", name)
+	}
+	e.docComments(x)
+	e.WriteString(name)
+	e.WriteString(" = ")
+	if err := e.expr(x.Value); err != nil {
+		return err
+	}
+	e.lineComments(x)
+	return nil
+}
+
+// block writes a block for struct x. The comments of doc, if not nil, are
+// written as the comments of the block.
+func (e *encoder) block(typ string, lbls []string, x *ast.StructLit, doc ast.Node) error {
+	if fields := labels(x); fields != nil {
+		if doc != nil {
+			e.docComments(doc)
+		}
+		for i, f := range fields {
+			name, err := fieldName(f)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				e.WriteByte('\n')
+				e.newline()
+			}
+			l := append(lbls[:len(lbls):len(lbls)], name)
+			if err := e.block(typ, l, f.Value.(*ast.StructLit), f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !isIdent(typ) {
+		return errors.Newf(x.Pos(), "hcl: invalid block type %q", typ)
+	}
+	if doc != nil {
+		e.docComments(doc)
+	}
+	e.docComments(x)
+	e.WriteString(typ)
+	for _, l := range lbls {
+		e.WriteByte(' ')
+		e.WriteString(quote(l))
+	}
+	e.WriteString(" {")
+	if len(x.Elts) > 0 {
+		e.indent++
+		e.newline()
+		if err := e.body(x.Elts); err != nil {
+			return err
+		}
+		e.indent--
+		e.newline()
+	}
+	e.WriteByte('}')
+	if doc != nil {
+		e.lineComments(doc)
+	}
+	return nil
+}
+
+func (e *encoder) expr(n ast.Expr) error {
+	switch x := n.(type) {
+	case *ast.BasicLit:
+		return e.basicLit(x)
+
+	case *ast.UnaryExpr:
+		b, ok := x.X.(*ast.BasicLit)
+		if ok && x.Op == token.SUB && (b.Kind == token.INT || b.Kind == token.FLOAT) {
+			e.WriteByte('-')
+			return e.basicLit(b)
+		}
+
+	case *ast.ListLit:
+		if len(x.Elts) == 0 {
+			e.WriteString("[]")
+			return nil
+		}
+		multiline := false
+		for _, elem := range x.Elts {
+			switch elem.(type) {
+			case *ast.StructLit, *ast.ListLit:
+				multiline = true
+			}
+		}
+		e.WriteByte('[')
+		if multiline {
+			e.indent++
+		}
+		for i, elem := range x.Elts {
+			switch {
+			case multiline:
+				e.newline()
+			case i > 0:
+				e.WriteString(", ")
+			}
+			if err := e.expr(elem); err != nil {
+				return err
+			}
+			if multiline {
+				e.WriteByte(',')
+			}
+		}
+		if multiline {
+			e.indent--
+			e.newline()
+		}
+		e.WriteByte(']')
+		return nil
+
+	case *ast.StructLit:
+		return e.object(x)
+	}
+	return errors.Newf(n.Pos(), "hcl: unsupported node %s (%T)", internal.DebugStr(n), n)
+}
+
+// object writes x as an object constructor expression.
+func (e *encoder) object(x *ast.StructLit) error {
+	if len(x.Elts) == 0 {
+		e.WriteString("{}")
+		return nil
+	}
+	e.WriteByte('{')
+	e.indent++
+	for _, d := range x.Elts {
+		e.newline()
+		switch f := d.(type) {
+		case *ast.CommentGroup:
+			e.commentGroup(f)
+
+		case *ast.Field:
+			name, err := fieldName(f)
+			if err != nil {
+				return err
+			}
+			e.docComments(f)
+			if isIdent(name) {
+				e.WriteString(name)
+			} else {
+				e.WriteString(quote(name))
+			}
+			e.WriteString(" = ")
+			if err := e.expr(f.Value); err != nil {
+				return err
+			}
+			e.lineComments(f)
+
+		default:
+			return errors.Newf(d.Pos(), "hcl: unsupported node %s (%T)", internal.DebugStr(d), d)
+		}
+	}
+	e.indent--
+	e.newline()
+	e.WriteByte('}')
+	return nil
+}
+
+func (e *encoder) basicLit(x *ast.BasicLit) error {
+	switch x.Kind {
+	case token.INT, token.FLOAT:
+		var ni literal.NumInfo
+		if err := literal.ParseNum(x.Value, &ni); err != nil {
+			return err
+		}
+		s := ni.String()
+		if strings.HasPrefix(s, ".") {
+			s = "0" + s
+		}
+		e.WriteString(s)
+
+	case token.TRUE, token.FALSE, token.NULL:
+		e.WriteString(x.Value)
+
+	case token.STRING:
+		s, err := literal.Unquote(x.Value)
+		if err != nil {
+			return err
+		}
+		if strings.Count(s, "\n") > 1 && strings.HasSuffix(s, "\n") {
+			e.heredoc(s)
+		} else {
+			e.WriteString(quote(s))
+		}
+
+	default:
+		return errors.Newf(x.Pos(), "hcl: unknown literal type %v", x.Kind)
+	}
+	return nil
+}
+
+// heredoc writes s, which must end with a newline, as a heredoc template.
+func (e *encoder) heredoc(s string) {
+	lines := strings.Split(s[:len(s)-1], "\n")
+	id := "EOT"
+	for i := 0; containsLine(lines, id); i++ {
+		id = fmt.Sprintf("EOT%d", i)
+	}
+	e.WriteString("<<")
+	e.WriteString(id)
+	e.WriteByte('\n')
+	e.WriteString(s)
+	e.WriteString(id)
+}
+
+func containsLine(lines []string, id string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// quote returns s as a quoted template. Template sequences in s are
+// retained.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < ' ' || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// isIdent reports whether s is a valid HCL identifier.
+func isIdent(s string) bool {
+	for i, r := range s {
+		if !isIdentStart(r) && (i == 0 || !isIdentPart(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (e *encoder) commentGroup(c *ast.CommentGroup) {
+	for i, c := range c.List {
+		if i > 0 {
+			e.newline()
+		}
+		e.WriteString(hclComment(c.Text))
+	}
+}
+
+// docComments writes the comments of n that precede it, each followed by a
+// newline.
+func (e *encoder) docComments(n ast.Node) {
+	for _, c := range ast.Comments(n) {
+		if c.Line || c.Position > 0 {
+			continue
+		}
+		e.commentGroup(c)
+		e.newline()
+	}
+}
+
+// lineComments writes the comments of n that follow it.
+func (e *encoder) lineComments(n ast.Node) {
+	for _, c := range ast.Comments(n) {
+		if c.Line || c.Position > 0 {
+			e.WriteByte(' ')
+			e.commentGroup(c)
+		}
+	}
+}
+
+// hclComment converts the text of a CUE comment to an HCL comment.
+func hclComment(text string) string {
+	if strings.HasPrefix(text, "//") {
+		return "#" + text[2:]
+	}
+	return text
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hcl converts HCL to and from CUE. When converting to CUE,
+// comments and position information are retained.
+//
+// An HCL body is converted to a struct. Attributes map to fields. Blocks map
+// to a field named after the block type, with a nested field for each
+// label:
+//
+//	resource "aws_instance" "web" {     resource: aws_instance: web: {
+//	  ami = "ami-a1b2c3d4"                  ami: "ami-a1b2c3d4"
+//	}                                   }
+//
+// Blocks of the same type and with the same labels that occur more than once
+// in a body are collected in a list. Attributes with an object value, or a
+// tuple of objects, are marked with a @hcl(attr) attribute to distinguish
+// them from blocks:
+//
+//	obj = { a = 1 }                     obj: {a: 1} @hcl(attr)
+//
+// Strings are templates, as in HCL's JSON syntax: interpolation sequences,
+// such as "${var.name}", are retained as is. Expressions other than literal
+// values, such as references, operators, and function calls, are converted
+// to a string holding the expression as a single interpolation sequence.
+//
+// When converting CUE to HCL, a field with a struct value is written as a
+// block. The fields of a non-empty struct whose fields all have struct
+// values are written as labels of the enclosing block. A list of structs is
+// written as a sequence of blocks. Fields marked with @hcl(attr) and all
+// other fields are written as attributes.
+package hcl
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/internal/source"
+)
+
+// Extract parses the HCL source to a CUE file.
+//
+// If src != nil, Extract parses the source from src and the filename is only
+// used when recording position information. The type of the argument for
+// the src parameter must be string, []byte, or io.Reader. If src == nil,
+// Extract parses the file specified by filename.
+func Extract(filename string, src interface{}) (f *ast.File, err error) {
+	b, err := source.Read(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		switch x := recover().(type) {
+		case nil:
+		case *scanError:
+			f, err = nil, x.err
+		default:
+			panic(x)
+		}
+	}()
+
+	d := &decoder{}
+	d.init(filename, b)
+	return d.file(filename), nil
+}
+
+// Decode converts an HCL file to a CUE value.
+func Decode(r *cue.Runtime, filename string, src interface{}) (*cue.Instance, error) {
+	file, err := Extract(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return r.CompileFile(file)
+}
+
+// Encode returns the HCL encoding of v, which must be a struct.
+func Encode(v cue.Value) ([]byte, error) {
+	n := v.Syntax(cue.Final())
+	return encode(n)
+}
@@ -0,0 +1,395 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name string
+		hcl  string
+		want string
+	}{{
+		name: "attributes",
+		hcl: `
+a = "foo"
+b = 1
+c = -2.5
+d = true
+e = null
+`,
+		want: `
+a: "foo"
+b: 1
+c: -2.5
+d: true
+e: null`,
+	}, {
+		name: "comments",
+		hcl: `
+# Doc comment.
+a = 1 # line comment
+
+/* Block
+ * comment */
+b = 2
+`,
+		want: `
+// Doc comment.
+a: 1 // line comment
+
+// Block
+// comment
+b: 2`,
+	}, {
+		name: "collections",
+		hcl: `
+list = [1, "two",
+  3]
+obj = {
+  name = "x"
+  "with-dash": 1,
+}
+`,
+		want: `
+list: [1, "two",
+	3]
+obj: {
+	name:        "x"
+	"with-dash": 1
+} @hcl(attr)`,
+	}, {
+		name: "blocks",
+		hcl: `
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+}
+
+empty {}
+`,
+		want: `
+resource: aws_instance: web: {
+	ami: "ami-123"
+}
+
+resource: aws_instance: db: {
+	ami: "ami-456"
+}
+
+empty: {}`,
+	}, {
+		name: "repeated blocks",
+		hcl: `
+ingress {
+  port = 80
+}
+ingress {
+  port = 443
+}
+`,
+		want: `
+ingress: [{
+	port: 80
+}, {
+	port: 443
+}]`,
+	}, {
+		name: "expressions",
+		hcl: `
+ref = var.name
+tmpl = "hello ${var.name}!"
+call = max(1, 2)
+cond = var.x ? 1 : 2
+for = [for s in var.list : upper(s)]
+`,
+		want: `
+ref:  "${var.name}"
+tmpl: "hello ${var.name}!"
+call: "${max(1, 2)}"
+cond: "${var.x ? 1 : 2}"
+for:  "${[for s in var.list : upper(s)]}"`,
+	}, {
+		name: "strings",
+		hcl: `
+escapes = "tab\t\"quote\" é $${literal}"
+heredoc = <<-EOT
+    line 1
+      line 2
+    EOT
+`,
+		want: `
+escapes: "tab\t\"quote\" é $${literal}"
+heredoc: "line 1\n  line 2\n"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Extract(tc.name, tc.hcl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		hcl string
+		err string
+	}{{
+		hcl: `a = "foo`,
+		err: "hcl: string literal not terminated",
+	}, {
+		hcl: "a {\n  b = 1\n",
+		err: `hcl: expected "}", found end of file`,
+	}, {
+		hcl: "a = 1\na = 2\n",
+		err: `hcl: attribute "a" redefined`,
+	}, {
+		hcl: "a = 1 b = 2\n",
+		err: `hcl: expected newline, found "b"`,
+	}, {
+		hcl: "a = @\n",
+		err: `hcl: invalid character '@'`,
+	}, {
+		hcl: "a = <<EOT\nfoo\n",
+		err: `hcl: heredoc not terminated; expected "EOT"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.hcl, func(t *testing.T) {
+			_, err := Extract("test", tc.hcl)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		name string
+		cue  string
+		want string
+	}{{
+		name: "attributes",
+		cue: `
+a: "foo"
+b: 1
+c: -2.5
+d: [1, 2]
+e: null
+`,
+		want: `
+a = "foo"
+b = 1
+c = -2.5
+d = [1, 2]
+e = null`,
+	}, {
+		name: "blocks",
+		cue: `
+region: "us-east-1"
+resource: aws_instance: {
+	web: ami: "ami-123"
+	db: ami:  "ami-456"
+}
+ingress: [{port: 80}, {port: 443}]
+empty: {}
+`,
+		want: `
+region = "us-east-1"
+
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+
+resource "aws_instance" "db" {
+  ami = "ami-456"
+}
+
+ingress {
+  port = 80
+}
+
+ingress {
+  port = 443
+}
+
+empty {}`,
+	}, {
+		name: "values",
+		cue: `
+list: [{a: 1}, [2]]
+str: "a \"b\"\n"
+`,
+		want: `
+list = [
+  {
+    a = 1
+  },
+  [2],
+]
+str = "a \"b\"\n"`,
+	}, {
+		name: "object attributes",
+		cue: `
+obj: {a: 1} @hcl(attr)
+objs: [{a: 1}, {a: 2}] @hcl(attr)
+block: {
+	inner: {b: 2} @hcl(attr)
+}
+`,
+		want: `
+obj = {
+  a = 1
+}
+objs = [
+  {
+    a = 1
+  },
+  {
+    a = 2
+  },
+]
+
+block {
+  inner = {
+    b = 2
+  }
+}`,
+	}, {
+		name: "heredoc",
+		cue: `
+text: "line 1\nline 2\n"
+`,
+		want: `
+text = <<EOT
+line 1
+line 2
+EOT`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, err := r.Compile(tc.name, tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Encode(inst.Value())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+
+			// Converting back to CUE must yield the same value.
+			back, err := Decode(r, tc.name, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := back.Value().Subsume(inst.Value()); err != nil {
+				t.Errorf("round trip: %v", err)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	const src = `
+obj = {
+  a = 1
+}
+objs = [
+  {
+    a = 1
+  },
+]
+
+block {
+  a = 1
+}
+
+blocks {
+  a = 1
+}
+
+blocks {
+  a = 2
+}
+`
+	r := &cue.Runtime{}
+	inst, err := Decode(r, "test", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encode(inst.Value())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(b)), strings.TrimSpace(src); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	testCases := []struct {
+		cue string
+		err string
+	}{{
+		cue: `[1, 2]`,
+		err: "hcl: top-level value must be a struct, found [1, 2]",
+	}, {
+		cue: `"a b": 1`,
+		err: `hcl: invalid attribute name "a b"`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			inst, err := r.Compile("test", tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Encode(inst.Value())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
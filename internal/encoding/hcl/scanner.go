@@ -0,0 +1,407 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hcl
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tNewline
+	tIdent
+	tNumber
+	tString  // quoted template
+	tHeredoc // heredoc template
+	tPunct   // operators and delimiters
+)
+
+// A comment is a comment in the source. Text holds the comment converted to
+// CUE's comment syntax, one line per element.
+type comment struct {
+	start, end int
+	text       []string
+}
+
+// A scanner splits HCL source into tokens. Comments are collected separately
+// in the order in which they appear.
+type scanner struct {
+	file *token.File
+	src  []byte
+	off  int
+
+	// The current token. For strings and heredocs, lit holds the template
+	// with escape sequences resolved.
+	tok        tokenKind
+	lit        string
+	start, end int
+
+	comments []comment
+}
+
+type scanError struct {
+	err errors.Error
+}
+
+func (s *scanner) init(filename string, src []byte) {
+	s.file = token.NewFile(filename, -1, len(src))
+	s.file.SetLinesForContent(src)
+	s.src = src
+}
+
+func (s *scanner) pos(offset int) token.Pos {
+	return s.file.Pos(offset, token.NoRelPos)
+}
+
+func (s *scanner) line(offset int) int {
+	return s.file.Position(s.pos(offset)).Line
+}
+
+func (s *scanner) errf(offset int, format string, args ...interface{}) {
+	panic(&scanError{errors.Newf(s.pos(offset), "hcl: "+format, args...)})
+}
+
+func (s *scanner) peek(i int) byte {
+	if s.off+i < len(s.src) {
+		return s.src[s.off+i]
+	}
+	return 0
+}
+
+// next advances to the next token.
+func (s *scanner) next() {
+	s.skipSpace()
+	s.start = s.off
+	s.lit = ""
+	if s.off >= len(s.src) {
+		s.tok = tEOF
+		s.end = s.off
+		return
+	}
+
+	switch c := s.src[s.off]; {
+	case c == '\n':
+		s.off++
+		s.tok = tNewline
+
+	case isIdentStart(s.runeAt(s.off)):
+		for s.off < len(s.src) && isIdentPart(s.runeAt(s.off)) {
+			_, n := utf8.DecodeRune(s.src[s.off:])
+			s.off += n
+		}
+		s.tok = tIdent
+		s.lit = string(s.src[s.start:s.off])
+
+	case '0' <= c && c <= '9':
+		s.number()
+
+	case c == '"':
+		s.off++
+		s.tok = tString
+		s.lit = s.template()
+
+	case c == '<' && s.peek(1) == '<':
+		s.heredoc()
+
+	default:
+		s.tok = tPunct
+		for _, op := range punctuation {
+			if strings.HasPrefix(string(s.src[s.off:]), op) {
+				s.off += len(op)
+				s.lit = op
+				break
+			}
+		}
+		if s.lit == "" {
+			r := s.runeAt(s.off)
+			s.errf(s.off, "invalid character %q", r)
+		}
+	}
+	s.end = s.off
+}
+
+// punctuation lists all operators and delimiters, longest first.
+var punctuation = []string{
+	"...",
+	"==", "!=", "<=", ">=", "&&", "||", "=>",
+	"{", "}", "[", "]", "(", ")", "=", ":", ",", ".", "?", "!",
+	"+", "-", "*", "/", "%", "<", ">",
+}
+
+func (s *scanner) runeAt(offset int) rune {
+	r, _ := utf8.DecodeRune(s.src[offset:])
+	return r
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// skipSpace skips white space other than newlines and collects comments.
+func (s *scanner) skipSpace() {
+	for s.off < len(s.src) {
+		switch c := s.src[s.off]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			s.off++
+
+		case c == '#' || c == '/' && s.peek(1) == '/':
+			start := s.off
+			for s.off < len(s.src) && s.src[s.off] != '\n' {
+				s.off++
+			}
+			text := string(s.src[start:s.off])
+			if c == '#' {
+				text = "//" + text[1:]
+			}
+			s.comments = append(s.comments, comment{
+				start: start,
+				end:   s.off,
+				text:  []string{strings.TrimRight(text, " \t\r")},
+			})
+
+		case c == '/' && s.peek(1) == '*':
+			start := s.off
+			i := strings.Index(string(s.src[s.off+2:]), "*/")
+			if i < 0 {
+				s.errf(start, "comment not terminated")
+			}
+			s.off += i + 4
+			s.comments = append(s.comments, comment{
+				start: start,
+				end:   s.off,
+				text:  blockComment(string(s.src[start+2 : s.off-2])),
+			})
+
+		default:
+			return
+		}
+	}
+}
+
+// blockComment converts the contents of a /* */ comment to line comments.
+// A leading * on continuation lines is removed.
+func blockComment(text string) []string {
+	lines := strings.Split(text, "\n")
+	var a []string
+	for i, l := range lines {
+		l = strings.TrimRight(l, " \t\r")
+		if i > 0 {
+			l = strings.TrimLeft(l, " \t")
+			if strings.HasPrefix(l, "*") {
+				l = l[1:]
+			} else if l != "" {
+				l = " " + l
+			}
+		}
+		if (i == 0 || i == len(lines)-1) && strings.TrimSpace(l) == "" {
+			continue
+		}
+		a = append(a, "//"+l)
+	}
+	if len(a) == 0 {
+		a = append(a, "//")
+	}
+	return a
+}
+
+func (s *scanner) number() {
+	s.tok = tNumber
+	digits := func() {
+		for s.off < len(s.src) && '0' <= s.src[s.off] && s.src[s.off] <= '9' {
+			s.off++
+		}
+	}
+	digits()
+	if s.peek(0) == '.' && '0' <= s.peek(1) && s.peek(1) <= '9' {
+		s.off++
+		digits()
+	}
+	if c := s.peek(0); c == 'e' || c == 'E' {
+		i := 1
+		if c := s.peek(1); c == '+' || c == '-' {
+			i++
+		}
+		if c := s.peek(i); '0' <= c && c <= '9' {
+			s.off += i
+			digits()
+		}
+	}
+	s.lit = string(s.src[s.start:s.off])
+}
+
+// template scans a quoted template after the opening quote and returns its
+// contents with escape sequences resolved. Template sequences, including the
+// escaped forms $${ and %%{, are retained verbatim.
+func (s *scanner) template() string {
+	var b strings.Builder
+	for {
+		if s.off >= len(s.src) || s.src[s.off] == '\n' {
+			s.errf(s.start, "string literal not terminated")
+		}
+		switch c := s.src[s.off]; {
+		case c == '"':
+			s.off++
+			return b.String()
+
+		case c == '\\':
+			s.escape(&b)
+
+		case (c == '$' || c == '%') && s.peek(1) == c && s.peek(2) == '{':
+			b.Write(s.src[s.off : s.off+3])
+			s.off += 3
+
+		case (c == '$' || c == '%') && s.peek(1) == '{':
+			start := s.off
+			s.off += 2
+			s.skipTemplateSequence(start)
+			b.Write(s.src[start:s.off])
+
+		default:
+			b.WriteByte(c)
+			s.off++
+		}
+	}
+}
+
+func (s *scanner) escape(b *strings.Builder) {
+	start := s.off
+	s.off++
+	c := s.peek(0)
+	s.off++
+	switch c {
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case '"', '\\':
+		b.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if s.off+n > len(s.src) {
+			s.errf(start, "invalid escape sequence")
+		}
+		v, err := strconv.ParseUint(string(s.src[s.off:s.off+n]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			s.errf(start, "invalid escape sequence")
+		}
+		b.WriteRune(rune(v))
+		s.off += n
+	default:
+		s.errf(start, "invalid escape sequence")
+	}
+}
+
+// skipTemplateSequence skips the contents of a template interpolation or
+// directive up to and including its closing brace.
+func (s *scanner) skipTemplateSequence(start int) {
+	depth := 1
+	for depth > 0 {
+		if s.off >= len(s.src) {
+			s.errf(start, "template sequence not terminated")
+		}
+		switch s.src[s.off] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			s.off++
+			s.template()
+			continue
+		}
+		s.off++
+	}
+}
+
+// heredoc scans a heredoc template. The flush form, <<-, removes the
+// smallest common indentation from all lines.
+func (s *scanner) heredoc() {
+	s.off += 2
+	flush := false
+	if s.peek(0) == '-' {
+		flush = true
+		s.off++
+	}
+	idStart := s.off
+	for s.off < len(s.src) && isIdentPart(s.runeAt(s.off)) {
+		s.off++
+	}
+	id := string(s.src[idStart:s.off])
+	if id == "" || s.peek(0) != '\n' && !(s.peek(0) == '\r' && s.peek(1) == '\n') {
+		s.errf(s.start, "invalid heredoc introducer")
+	}
+	s.off = strings.IndexByte(string(s.src[s.off:]), '\n') + s.off + 1
+
+	var lines []string
+	for {
+		if s.off >= len(s.src) {
+			s.errf(s.start, "heredoc not terminated; expected %q", id)
+		}
+		end := strings.IndexByte(string(s.src[s.off:]), '\n')
+		if end < 0 {
+			end = len(s.src) - s.off
+		}
+		line := string(s.src[s.off : s.off+end])
+		if strings.TrimSpace(line) == id {
+			s.off += len(strings.TrimRight(line, " \t\r"))
+			break
+		}
+		lines = append(lines, line)
+		s.off += end + 1
+	}
+
+	if flush {
+		indent := -1
+		for _, l := range lines {
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			n := len(l) - len(strings.TrimLeft(l, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, l := range lines {
+			if len(l) >= indent && indent > 0 {
+				lines[i] = l[indent:]
+			} else {
+				lines[i] = strings.TrimLeft(l, " \t")
+			}
+		}
+	}
+
+	s.tok = tHeredoc
+	s.lit = ""
+	for _, l := range lines {
+		s.lit += strings.TrimSuffix(l, "\r") + "\n"
+	}
+}
@@ -17,8 +17,13 @@ package json
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 	"math/big"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
@@ -39,8 +44,11 @@ import (
 //    Field         must be regular; label must be a BasicLit or Ident
 //
 // Comments and attributes are ignored.
-func Encode(n ast.Node) (b []byte, err error) {
+func Encode(n ast.Node, opts ...Option) (b []byte, err error) {
 	e := encoder{}
+	for _, o := range opts {
+		o(&e)
+	}
 	err = e.encode(n)
 	if err != nil {
 		return nil, err
@@ -48,7 +56,23 @@ func Encode(n ast.Node) (b []byte, err error) {
 	return e.w.Bytes(), nil
 }
 
+// An Option sets behavior of the encoder.
+type Option func(e *encoder)
+
+// Canonical causes the encoder to generate canonical JSON as defined by
+// RFC 8785, the JSON Canonicalization Scheme (JCS). The output contains no
+// whitespace, object members are sorted by the UTF-16 code units of their
+// names, and strings and numbers are formatted as ECMAScript's JSON.stringify
+// would. A number is written as the shortest decimal that converts to the same
+// IEEE 754 double. It is an error if that decimal does not have the exact
+// value of the number, as for 1.0000000000000001 but not for 1.1, or if a
+// string is not valid UTF-8.
+func Canonical() Option {
+	return func(e *encoder) { e.canonical = true }
+}
+
 type encoder struct {
+	canonical      bool
 	w              bytes.Buffer
 	tab            []byte
 	indentsAtLevel []int
@@ -99,6 +123,9 @@ func (e *encoder) indent() {
 }
 
 func (e *encoder) ws(pos token.Pos, default_ token.RelPos) {
+	if e.canonical {
+		return
+	}
 	rel := pos.RelPos()
 	if pos == token.NoPos {
 		rel = default_
@@ -154,6 +181,9 @@ func (e *encoder) encode(n ast.Node) error {
 		e.ws(foldNewline(x.Pos()), defPos)
 		l, ok := x.X.(*ast.BasicLit)
 		if ok && x.Op == token.SUB && (l.Kind == token.INT || l.Kind == token.FLOAT) {
+			if e.canonical {
+				return e.canonicalNum(l, "-")
+			}
 			e.writeByte('-')
 			return e.encodeScalar(l, false)
 		}
@@ -185,6 +215,9 @@ func (e *encoder) encodeScalar(l *ast.BasicLit, allowMinus bool) error {
 		if err != nil {
 			return err
 		}
+		if e.canonical {
+			return e.canonicalString(l.Pos(), str)
+		}
 		b, err := json.Marshal(str)
 		if err != nil {
 			return err
@@ -201,6 +234,9 @@ func (e *encoder) setNum(l *ast.BasicLit, allowMinus bool, x interface{}) error
 	if !allowMinus && strings.HasPrefix(l.Value, "-") {
 		return errors.Newf(l.Pos(), "double minus not allowed")
 	}
+	if e.canonical {
+		return e.canonicalNum(l, "")
+	}
 	var ni literal.NumInfo
 	if err := literal.ParseNum(l.Value, &ni); err != nil {
 		return err
@@ -209,6 +245,107 @@ func (e *encoder) setNum(l *ast.BasicLit, allowMinus bool, x interface{}) error
 	return nil
 }
 
+// canonicalNum writes the number l, prefixed with sign, in the format of
+// ECMAScript's Number.prototype.toString, as required by RFC 8785. It reports
+// an error if the result does not have the exact value of l.
+func (e *encoder) canonicalNum(l *ast.BasicLit, sign string) error {
+	if sign != "" && strings.HasPrefix(l.Value, "-") {
+		return errors.Newf(l.Pos(), "double minus not allowed")
+	}
+	var ni literal.NumInfo
+	if err := literal.ParseNum(l.Value, &ni); err != nil {
+		return err
+	}
+	num := sign + ni.String()
+	var want big.Rat
+	if _, ok := want.SetString(num); !ok {
+		return errors.Newf(l.Pos(), "json: invalid number %s", num)
+	}
+	f, _ := want.Float64()
+	if math.IsInf(f, 0) {
+		return errors.Newf(l.Pos(), "json: number %s out of range for canonical JSON", num)
+	}
+	s := formatNumber(f)
+	var got big.Rat
+	if _, ok := got.SetString(s); !ok || got.Cmp(&want) != 0 {
+		return errors.Newf(l.Pos(),
+			"json: number %s cannot be represented exactly in canonical JSON", num)
+	}
+	e.writeString(s)
+	return nil
+}
+
+// formatNumber formats f as ECMAScript's Number.prototype.toString.
+func formatNumber(f float64) string {
+	if f == 0 {
+		return "0" // also for negative zero
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Remove the leading zero of a single-digit exponent: e-07 to e-7.
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s
+}
+
+// canonicalString writes s as a string as defined by RFC 8785: only quotes,
+// backslashes, and control characters are escaped.
+func (e *encoder) canonicalString(pos token.Pos, s string) error {
+	if !utf8.ValidString(s) {
+		return errors.Newf(pos, "json: invalid UTF-8 in string %q", s)
+	}
+	const hex = "0123456789abcdef"
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteByte(hex[r>>4])
+				b.WriteByte(hex[r&0xF])
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	e.writeString(b.String())
+	return nil
+}
+
+// lessUTF16 reports whether a sorts before b when compared by their UTF-16
+// code units, as RFC 8785 requires for the names of object members.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
 // encodeDecls converts a sequence of declarations to a value. If it encounters
 // an embedded value, it will return this expression. This is more relaxed for
 // structs than is currently allowed for CUE, but the expectation is that this
@@ -259,6 +396,10 @@ func (e *encoder) encodeDecls(decls []ast.Decl, endPos token.Pos) error {
 		return nil
 	}
 
+	if e.canonical {
+		return e.encodeCanonicalFields(fields)
+	}
+
 	e.writeIndent('{')
 	pos := compactNewline(fields[0].Pos())
 	if endPos == token.NoPos && pos.RelPos() == token.Blank {
@@ -291,6 +432,46 @@ func (e *encoder) encodeDecls(decls []ast.Decl, endPos token.Pos) error {
 	return nil
 }
 
+// encodeCanonicalFields writes fields as an object with its members sorted
+// as required by RFC 8785.
+func (e *encoder) encodeCanonicalFields(fields []*ast.Field) error {
+	names := make([]string, len(fields))
+	for i, x := range fields {
+		name, _, err := ast.LabelName(x.Label)
+		if err != nil {
+			return errors.Newf(x.Label.Pos(), "json: only literal labels allowed")
+		}
+		names[i] = name
+	}
+	index := make([]int, len(fields))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return lessUTF16(names[index[i]], names[index[j]])
+	})
+
+	e.writeByte('{')
+	for i, k := range index {
+		if i > 0 {
+			if names[k] == names[index[i-1]] {
+				return errors.Newf(fields[k].Label.Pos(),
+					"json: duplicate field %q", names[k])
+			}
+			e.writeByte(',')
+		}
+		if err := e.canonicalString(fields[k].Label.Pos(), names[k]); err != nil {
+			return err
+		}
+		e.writeByte(':')
+		if err := e.encode(fields[k].Value); err != nil {
+			return err
+		}
+	}
+	e.writeByte('}')
+	return nil
+}
+
 func compactNewline(pos token.Pos) token.Pos {
 	if pos.RelPos() == token.NewSection {
 		pos = token.Newline.Pos()
@@ -309,3 +309,80 @@ func TestEncodeAST(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeCanonical(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		out  string
+	}{{
+		name: "whitespace",
+		in: `
+		b: [1, 2, {
+			y: true
+			x: null
+		}]
+		a: {}
+		c: []
+		`,
+		out: `{"a":{},"b":[1,2,{"x":null,"y":true}],"c":[]}`,
+	}, {
+		// Example from RFC 8785, Section 3.2.3.
+		name: "sort",
+		in: `
+		"\u20ac": "Euro Sign"
+		"\r": "Carriage Return"
+		"\ufb33": "Hebrew Letter Dalet With Dagesh"
+		"1": "One"
+		"\U0001f600": "Emoji: Grinning Face"
+		"\u0080": "Control"
+		"\u00f6": "Latin Small Letter O With Diaeresis"
+		`,
+		out: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\"," +
+			"\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\"," +
+			"\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+	}, {
+		// Example from RFC 8785, Section 3.2.2.3.
+		name: "strings",
+		in:   `a: "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"/<\u2028>"`,
+		out:  "{\"a\":\"€$\\u000f\\nA'B\\\"\\\\\\\\\\\"/<\u2028>\"}",
+	}, {
+		name: "numbers",
+		in: `
+		a: [0, -0, 1.0, 4.50, 2e-3, 1e-6, 1e-7, 0.000000000000000000000000001]
+		b: [1e20, 1e21, 1E30, -1.5e300, 9007199254740992, 1Ki]
+		`,
+		out: `{"a":[0,0,1,4.5,0.002,0.000001,1e-7,1e-27],` +
+			`"b":[100000000000000000000,1e+21,1e+30,-1.5e+300,9007199254740992,1024]}`,
+	}, {
+		name: "inexact",
+		in:   `a: 9007199254740993`,
+		out:  "json: number 9007199254740993 cannot be represented exactly in canonical JSON",
+	}, {
+		name: "range",
+		in:   `a: -1e400`,
+		out:  "json: number -1e400 out of range for canonical JSON",
+	}, {
+		name: "utf8",
+		in:   `a: '\x80'`,
+		out:  `json: invalid UTF-8 in string "\x80"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(tc.name, tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Encode(f, Canonical())
+			var got string
+			if err != nil {
+				got = err.Error()
+			} else {
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Error(cmp.Diff(got, tc.out))
+			}
+		})
+	}
+}
@@ -0,0 +1,623 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonc converts JSON with comments to CUE.
+//
+// The accepted syntax is that of JSON5, which is a superset of JSONC, the
+// JSON dialect used by tools such as the TypeScript compiler and VS Code.
+// Compared to JSON it allows
+//
+//   - line (//) and block (/* */) comments,
+//   - trailing commas in objects and arrays,
+//   - unquoted object keys that are valid identifiers,
+//   - single-quoted strings and line continuations in strings, and
+//   - hexadecimal numbers, numbers with a leading or trailing decimal point,
+//     and numbers with a leading plus sign.
+//
+// Infinity and NaN are not supported, as they cannot be represented in CUE.
+//
+// Comments are retained. Comments that precede an object member or array
+// element become its doc comments.
+package jsonc
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/source"
+)
+
+// Extract parses the JSONC or JSON5 source to a CUE expression.
+//
+// If src != nil, Extract parses the source from src and the filename is only
+// used when recording position information. The type of the argument for
+// the src parameter must be string, []byte, or io.Reader. If src == nil,
+// Extract parses the file specified by filename.
+func Extract(filename string, src interface{}) (expr ast.Expr, err error) {
+	b, err := source.Read(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		file:    token.NewFile(filename, -1, len(b)),
+		src:     b,
+		prevEnd: -1,
+	}
+	d.file.SetLinesForContent(b)
+
+	defer func() {
+		if x := recover(); x != nil {
+			e, ok := x.(*parseError)
+			if !ok {
+				panic(x)
+			}
+			expr, err = nil, e.err
+		}
+	}()
+
+	d.skip()
+	if d.offset >= len(d.src) {
+		d.errf(d.offset, "unexpected end of input")
+	}
+	start := d.offset
+	leading := d.commentGroups(start)
+	expr = d.value()
+	d.skip()
+	if d.offset < len(d.src) {
+		d.errf(d.offset, "unexpected %s after top-level value", d.describe())
+	}
+	trailing := d.commentGroups(len(d.src) + 1)
+
+	if obj, ok := expr.(*ast.StructLit); ok {
+		// The elements of a top-level struct become the declarations of a
+		// file, so comments are added as declarations.
+		var elts []ast.Decl
+		for _, cg := range leading {
+			elts = append(elts, cg)
+		}
+		obj.Elts = append(elts, obj.Elts...)
+		for _, cg := range trailing {
+			obj.Elts = append(obj.Elts, cg)
+		}
+		return obj, nil
+	}
+	for _, cg := range leading {
+		cg.Doc = true
+		ast.AddComment(expr, cg)
+	}
+	return expr, nil
+}
+
+type parseError struct{ err errors.Error }
+
+// A decoder converts JSON5 to a CUE AST.
+type decoder struct {
+	file   *token.File
+	src    []byte
+	offset int
+
+	// prevEnd is the end offset of the last token or comment that was
+	// converted. It is used to compute relative positions.
+	prevEnd int
+
+	comments []comment // comments that were skipped but not yet converted
+}
+
+type comment struct {
+	start, end int
+	text       []string // lines of the comment in CUE syntax
+}
+
+func (d *decoder) errf(offset int, format string, args ...interface{}) {
+	pos := d.file.Pos(offset, token.NoRelPos)
+	panic(&parseError{errors.Newf(pos, "jsonc: "+format, args...)})
+}
+
+func (d *decoder) line(offset int) int {
+	return d.file.Position(d.file.Pos(offset, 0)).Line
+}
+
+// relPos returns the position of the given offset, relative to the last
+// converted token.
+func (d *decoder) relPos(offset int) token.Pos {
+	pos := d.file.Pos(offset, token.NoRelPos)
+	if d.prevEnd < 0 {
+		return pos
+	}
+	switch n := d.line(offset) - d.line(d.prevEnd); {
+	case n >= 2:
+		pos = pos.WithRel(token.NewSection)
+	case n == 1:
+		pos = pos.WithRel(token.Newline)
+	case offset > d.prevEnd:
+		pos = pos.WithRel(token.Blank)
+	default:
+		pos = pos.WithRel(token.NoSpace)
+	}
+	return pos
+}
+
+func (d *decoder) describe() string {
+	if d.offset >= len(d.src) {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(d.src[d.offset:])
+	return strconv.QuoteRune(r)
+}
+
+// skip skips white space and comments, recording the comments.
+func (d *decoder) skip() {
+	for d.offset < len(d.src) {
+		r, size := utf8.DecodeRune(d.src[d.offset:])
+		switch {
+		case r == '/' && d.offset+1 < len(d.src) && d.src[d.offset+1] == '/':
+			start := d.offset
+			end := bytes.IndexByte(d.src[start:], '\n')
+			if end < 0 {
+				end = len(d.src)
+			} else {
+				end += start
+			}
+			text := strings.TrimRight(string(d.src[start:end]), " \t\r")
+			d.comments = append(d.comments, comment{start, end, []string{text}})
+			d.offset = end
+
+		case r == '/' && d.offset+1 < len(d.src) && d.src[d.offset+1] == '*':
+			start := d.offset
+			end := bytes.Index(d.src[start+2:], []byte("*/"))
+			if end < 0 {
+				d.errf(start, "comment not terminated")
+			}
+			end += start + 4
+			d.comments = append(d.comments, comment{start, end, blockComment(d.src[start+2 : end-2])})
+			d.offset = end
+
+		case r == '\uFEFF' || unicode.IsSpace(r):
+			d.offset += size
+
+		default:
+			return
+		}
+	}
+}
+
+// blockComment converts the text of a block comment to line comments.
+func blockComment(b []byte) []string {
+	lines := strings.Split(string(b), "\n")
+	var text []string
+	for i, l := range lines {
+		l = strings.TrimRight(l, " \t\r")
+		if i > 0 {
+			l = strings.TrimLeft(l, " \t")
+			if strings.HasPrefix(l, "*") {
+				l = strings.TrimPrefix(l[1:], " ")
+			}
+		} else {
+			l = strings.TrimLeft(l, "*")
+			l = strings.TrimPrefix(l, " ")
+		}
+		if (i == 0 || i == len(lines)-1) && l == "" && len(lines) > 1 {
+			continue
+		}
+		if l != "" {
+			l = " " + l
+		}
+		text = append(text, "//"+l)
+	}
+	if len(text) == 0 {
+		text = []string{"//"}
+	}
+	return text
+}
+
+// commentGroups returns the pending comments that start before offset end as
+// comment groups. Comments on consecutive lines form a single group.
+func (d *decoder) commentGroups(end int) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	lastLine := -1
+	for len(d.comments) > 0 && d.comments[0].start < end {
+		c := d.comments[0]
+		d.comments = d.comments[1:]
+
+		line := d.line(c.start)
+		if line > lastLine+1 || len(groups) == 0 {
+			groups = append(groups, &ast.CommentGroup{})
+		}
+		cg := groups[len(groups)-1]
+		for i, text := range c.text {
+			pos := token.NoPos
+			if i == 0 {
+				pos = d.relPos(c.start)
+			}
+			cg.List = append(cg.List, &ast.Comment{Slash: pos, Text: text})
+		}
+		d.prevEnd = c.end
+		lastLine = d.line(c.end)
+	}
+	return groups
+}
+
+// docComments attaches the pending comments that precede the element
+// starting at offset start. A comment group that ends on the line directly
+// before the element becomes its doc comment. Other groups are returned.
+func (d *decoder) docComments(start int, n ast.Node) (rest []*ast.CommentGroup) {
+	groups := d.commentGroups(start)
+	if len(groups) == 0 {
+		return nil
+	}
+	last := groups[len(groups)-1]
+	if d.line(d.prevEnd)+1 == d.line(start) {
+		last.Doc = true
+		ast.AddComment(n, last)
+		return groups[:len(groups)-1]
+	}
+	return groups
+}
+
+// lineComment attaches a pending comment that starts on the line on which
+// the last converted element ends as a line comment to n.
+func (d *decoder) lineComment(n ast.Node) {
+	if len(d.comments) == 0 || d.prevEnd < 0 {
+		return
+	}
+	c := d.comments[0]
+	if d.line(c.start) != d.line(d.prevEnd) || len(c.text) > 1 {
+		return
+	}
+	d.comments = d.comments[1:]
+	ast.AddComment(n, &ast.CommentGroup{
+		Line:     true,
+		Position: 5,
+		List: []*ast.Comment{{
+			Slash: d.file.Pos(c.start, token.Blank),
+			Text:  c.text[0],
+		}},
+	})
+	d.prevEnd = c.end
+}
+
+func (d *decoder) value() ast.Expr {
+	d.skip()
+	if d.offset >= len(d.src) {
+		d.errf(d.offset, "unexpected end of input")
+	}
+	switch c := d.src[d.offset]; {
+	case c == '{':
+		return d.object()
+	case c == '[':
+		return d.array()
+	case c == '"' || c == '\'':
+		start := d.offset
+		s := d.string()
+		lit := ast.NewString(s)
+		lit.ValuePos = d.relPos(start)
+		d.prevEnd = d.offset
+		return lit
+	case c == '-' || c == '+' || c == '.' || '0' <= c && c <= '9':
+		return d.number()
+	}
+
+	start := d.offset
+	name := d.ident()
+	var lit *ast.BasicLit
+	switch name {
+	case "true":
+		lit = &ast.BasicLit{Kind: token.TRUE, Value: name}
+	case "false":
+		lit = &ast.BasicLit{Kind: token.FALSE, Value: name}
+	case "null":
+		lit = &ast.BasicLit{Kind: token.NULL, Value: name}
+	case "Infinity", "NaN":
+		d.errf(start, "%s not supported", name)
+	case "":
+		d.errf(start, "unexpected %s", d.describe())
+	default:
+		d.errf(start, "unexpected identifier %s", name)
+	}
+	lit.ValuePos = d.relPos(start)
+	d.prevEnd = d.offset
+	return lit
+}
+
+func (d *decoder) object() ast.Expr {
+	obj := &ast.StructLit{Lbrace: d.relPos(d.offset)}
+	d.offset++
+	d.prevEnd = d.offset
+	for {
+		d.skip()
+		if d.offset >= len(d.src) {
+			d.errf(d.offset, "expected '}', found end of input")
+		}
+		start := d.offset
+		if d.src[start] == '}' {
+			for _, cg := range d.commentGroups(start) {
+				obj.Elts = append(obj.Elts, cg)
+			}
+			obj.Rbrace = d.relPos(start)
+			d.offset++
+			d.prevEnd = d.offset
+			return obj
+		}
+
+		field := &ast.Field{}
+		for _, cg := range d.docComments(start, field) {
+			obj.Elts = append(obj.Elts, cg)
+		}
+		field.Label = d.label()
+		d.skip()
+		if d.offset >= len(d.src) || d.src[d.offset] != ':' {
+			d.errf(d.offset, "expected ':', found %s", d.describe())
+		}
+		d.offset++
+		d.prevEnd = d.offset
+		field.Value = d.value()
+		obj.Elts = append(obj.Elts, field)
+		d.separator('}')
+		d.lineComment(field)
+	}
+}
+
+func (d *decoder) array() ast.Expr {
+	list := &ast.ListLit{Lbrack: d.relPos(d.offset)}
+	d.offset++
+	d.prevEnd = d.offset
+	for {
+		d.skip()
+		if d.offset >= len(d.src) {
+			d.errf(d.offset, "expected ']', found end of input")
+		}
+		start := d.offset
+		if d.src[start] == ']' {
+			if groups := d.commentGroups(start); len(groups) > 0 && len(list.Elts) > 0 {
+				last := list.Elts[len(list.Elts)-1]
+				for _, cg := range groups {
+					cg.Position = 5
+					ast.AddComment(last, cg)
+				}
+			}
+			list.Rbrack = d.relPos(start)
+			d.offset++
+			d.prevEnd = d.offset
+			return list
+		}
+
+		var doc []*ast.CommentGroup
+		if groups := d.commentGroups(start); len(groups) > 0 {
+			doc = groups
+		}
+		elem := d.value()
+		for _, cg := range doc {
+			cg.Doc = true
+			ast.AddComment(elem, cg)
+		}
+		list.Elts = append(list.Elts, elem)
+		d.separator(']')
+		d.lineComment(elem)
+	}
+}
+
+// separator consumes the comma following an element, if any, and reports an
+// error if the element is followed by anything other than a comma or the
+// given closing character.
+func (d *decoder) separator(close byte) {
+	d.skip()
+	if d.offset < len(d.src) {
+		switch d.src[d.offset] {
+		case ',':
+			d.offset++
+			d.prevEnd = d.offset
+			d.skip()
+			return
+		case close:
+			return
+		}
+	}
+	d.errf(d.offset, "expected ',' or '%c', found %s", close, d.describe())
+}
+
+func (d *decoder) label() ast.Label {
+	start := d.offset
+	var name string
+	switch d.src[start] {
+	case '"', '\'':
+		name = d.string()
+	default:
+		name = d.ident()
+		if name == "" {
+			d.errf(start, "expected object key, found %s", d.describe())
+		}
+	}
+	pos := d.relPos(start)
+	d.prevEnd = d.offset
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
+
+// ident scans an ECMAScript identifier.
+func (d *decoder) ident() string {
+	start := d.offset
+	for d.offset < len(d.src) {
+		r, size := utf8.DecodeRune(d.src[d.offset:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && (d.offset == start || !unicode.IsDigit(r)) {
+			break
+		}
+		d.offset += size
+	}
+	return string(d.src[start:d.offset])
+}
+
+func (d *decoder) string() string {
+	start := d.offset
+	quote := d.src[start]
+	d.offset++
+	var buf strings.Builder
+	for {
+		if d.offset >= len(d.src) {
+			d.errf(start, "string literal not terminated")
+		}
+		c := d.src[d.offset]
+		switch {
+		case c == quote:
+			d.offset++
+			return buf.String()
+
+		case c == '\n' || c == '\r':
+			d.errf(start, "string literal not terminated")
+
+		case c == '\\':
+			d.escape(&buf)
+
+		default:
+			buf.WriteByte(c)
+			d.offset++
+		}
+	}
+}
+
+// escape decodes the escape sequence at the current offset.
+func (d *decoder) escape(buf *strings.Builder) {
+	start := d.offset
+	d.offset++
+	if d.offset >= len(d.src) {
+		d.errf(start, "string literal not terminated")
+	}
+	c := d.src[d.offset]
+	d.offset++
+	switch c {
+	case 'b':
+		buf.WriteByte('\b')
+	case 'f':
+		buf.WriteByte('\f')
+	case 'n':
+		buf.WriteByte('\n')
+	case 'r':
+		buf.WriteByte('\r')
+	case 't':
+		buf.WriteByte('\t')
+	case 'v':
+		buf.WriteByte('\v')
+	case '0':
+		buf.WriteByte(0)
+	case '\r':
+		// Line continuation.
+		if d.offset < len(d.src) && d.src[d.offset] == '\n' {
+			d.offset++
+		}
+	case '\n':
+		// Line continuation.
+	case 'x':
+		buf.WriteRune(rune(d.hex(start, 2)))
+	case 'u':
+		r := rune(d.hex(start, 4))
+		if 0xD800 <= r && r < 0xDC00 && bytes.HasPrefix(d.src[d.offset:], []byte(`\u`)) {
+			d.offset += 2
+			lo := rune(d.hex(start, 4))
+			r = (r-0xD800)<<10 + (lo - 0xDC00) + 0x10000
+		}
+		buf.WriteRune(r)
+	default:
+		if '1' <= c && c <= '9' {
+			d.errf(start, "invalid escape sequence")
+		}
+		// Other characters, including quotes, backslash and slash, escape
+		// themselves.
+		d.offset--
+		r, size := utf8.DecodeRune(d.src[d.offset:])
+		buf.WriteRune(r)
+		d.offset += size
+	}
+}
+
+func (d *decoder) hex(start, n int) uint64 {
+	if d.offset+n > len(d.src) {
+		d.errf(start, "invalid escape sequence")
+	}
+	v, err := strconv.ParseUint(string(d.src[d.offset:d.offset+n]), 16, 32)
+	if err != nil {
+		d.errf(start, "invalid escape sequence")
+	}
+	d.offset += n
+	return v
+}
+
+var (
+	decimalRE = regexp.MustCompile(`^(0|[1-9][0-9]*)?(\.[0-9]*)?([eE][-+]?[0-9]+)?$`)
+	hexRE     = regexp.MustCompile(`^0[xX][0-9a-fA-F]+$`)
+)
+
+func (d *decoder) number() ast.Expr {
+	start := d.offset
+	neg := false
+	switch d.src[d.offset] {
+	case '-':
+		neg = true
+		d.offset++
+	case '+':
+		d.offset++
+	}
+	numStart := d.offset
+	for d.offset < len(d.src) {
+		c := d.src[d.offset]
+		if c != '.' && c != '+' && c != '-' && !isAlnum(c) {
+			break
+		}
+		if (c == '+' || c == '-') && !strings.ContainsAny(string(d.src[d.offset-1]), "eE") {
+			break
+		}
+		d.offset++
+	}
+	s := string(d.src[numStart:d.offset])
+
+	var lit *ast.BasicLit
+	switch {
+	case s == "Infinity" || s == "NaN":
+		d.errf(start, "%s not supported", s)
+	case hexRE.MatchString(s):
+		lit = &ast.BasicLit{Kind: token.INT, Value: strings.ToLower(s)}
+	case s != "." && decimalRE.MatchString(s) && !strings.HasPrefix(s, "e") && !strings.HasPrefix(s, "E"):
+		if strings.HasPrefix(s, ".") {
+			s = "0" + s
+		}
+		if i := strings.IndexByte(s, '.'); i >= 0 && (i+1 == len(s) || s[i+1] == 'e' || s[i+1] == 'E') {
+			s = s[:i+1] + "0" + s[i+1:]
+		}
+		lit = &ast.BasicLit{Kind: token.INT, Value: s}
+		if strings.ContainsAny(s, ".eE") {
+			lit.Kind = token.FLOAT
+		}
+	default:
+		d.errf(start, "invalid number %s", strconv.Quote(string(d.src[start:d.offset])))
+	}
+
+	pos := d.relPos(start)
+	d.prevEnd = d.offset
+	lit.ValuePos = pos
+	if neg {
+		return &ast.UnaryExpr{OpPos: pos, Op: token.SUB, X: lit}
+	}
+	return lit
+}
+
+func isAlnum(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonc
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "json",
+		in:   `{"a": 1, "b-c": [true, null, "x"]}`,
+		want: `a: 1, "b-c": [true, null, "x"]`,
+	}, {
+		name: "comments",
+		in: `{
+  // Compiler options.
+  "compilerOptions": {
+    "target": "es2017", // ES version
+    /* Block
+     * comment. */
+    "strict": true,
+  },
+
+  // Files.
+  "files": [
+    // First.
+    "a.ts",
+  ],
+}`,
+		want: `
+// Compiler options.
+compilerOptions: {
+	target: "es2017" // ES version
+	// Block
+	// comment.
+	strict: true
+}
+
+// Files.
+files: [
+	// First.
+	"a.ts",
+]`,
+	}, {
+		name: "json5",
+		in: `{
+  unquoted: 'single \'quoted\'',
+  $id: "line \
+continuation",
+  hex: 0xFF,
+  numbers: [.5, 5., +1, -2e3],
+  escapes: "\x41é😀",
+}`,
+		want: `
+unquoted: "single 'quoted'"
+$id:      "line continuation"
+hex:      0xff
+numbers: [0.5, 5.0, 1, -2e3]
+escapes: "Aé😀"`,
+	}, {
+		name: "scalar",
+		in:   "// Doc.\n'foo'\n",
+		want: "// Doc.\n\"foo\"",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := Extract(tc.name, tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(internal.ToFile(expr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		in  string
+		pos string
+		err string
+	}{{
+		in:  `{a: 1 b: 2}`,
+		pos: "test:1:7",
+		err: `jsonc: expected ',' or '}', found 'b'`,
+	}, {
+		in:  "{\n  a 1}",
+		pos: "test:2:5",
+		err: `jsonc: expected ':', found '1'`,
+	}, {
+		in:  `[1, 2`,
+		pos: "test:1:6",
+		err: `jsonc: expected ',' or ']', found end of input`,
+	}, {
+		in:  `{a: "foo}`,
+		pos: "test:1:5",
+		err: "jsonc: string literal not terminated",
+	}, {
+		in:  `/* foo`,
+		pos: "test:1:1",
+		err: "jsonc: comment not terminated",
+	}, {
+		in:  `[Infinity]`,
+		pos: "test:1:2",
+		err: "jsonc: Infinity not supported",
+	}, {
+		in:  `[-NaN]`,
+		pos: "test:1:2",
+		err: "jsonc: NaN not supported",
+	}, {
+		in:  `[01]`,
+		pos: "test:1:2",
+		err: `jsonc: invalid number "01"`,
+	}, {
+		in:  `{} {}`,
+		pos: "test:1:4",
+		err: `jsonc: unexpected '{' after top-level value`,
+	}, {
+		in:  ``,
+		pos: "test",
+		err: "jsonc: unexpected end of input",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := Extract("test", tc.in)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+			if got := err.(errors.Error).Position().String(); got != tc.pos {
+				t.Errorf("got position %s; want %s", got, tc.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package properties
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// Encode converts a CUE AST to a properties file.
+//
+// The given node must be a File or StructLit of which all fields are regular
+// and have a null, boolean, number, string, or struct value. The fields of
+// nested structs are written with dotted keys. As labels may contain dots
+// themselves, it is an error if two fields result in the same key. Null is
+// converted to an empty value. Non-ASCII characters are written as \uxxxx
+// escapes.
+func Encode(n ast.Node) ([]byte, error) {
+	var decls []ast.Decl
+	switch x := n.(type) {
+	case *ast.File:
+		decls = x.Decls
+	case *ast.StructLit:
+		decls = x.Elts
+	default:
+		return nil, errors.Newf(n.Pos(), "properties: top-level value must be a struct, found %s", internal.DebugStr(n))
+	}
+	e := &encoder{keys: map[string]bool{}}
+	if err := e.decls("", decls); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+type encoder struct {
+	bytes.Buffer
+	keys map[string]bool // keys written so far
+}
+
+func (e *encoder) decls(prefix string, decls []ast.Decl) error {
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Package:
+			continue
+
+		case *ast.CommentGroup:
+			e.comments(x)
+
+		case *ast.Field:
+			name, err := fieldName(x)
+			if err != nil {
+				return err
+			}
+			key := prefix + name
+			if obj, ok := x.Value.(*ast.StructLit); ok {
+				if err := e.decls(key+".", obj.Elts); err != nil {
+					return err
+				}
+				continue
+			}
+			s, err := text(key, x.Value)
+			if err != nil {
+				return err
+			}
+			if e.keys[key] {
+				return errors.Newf(x.Label.Pos(), "properties: duplicate property %q", key)
+			}
+			e.keys[key] = true
+			for _, c := range ast.Comments(x) {
+				if !c.Line && c.Position == 0 {
+					e.comments(c)
+				}
+			}
+			e.escape(key, true)
+			e.WriteByte('=')
+			e.escape(s, false)
+			e.WriteByte('\n')
+
+		default:
+			return errors.Newf(d.Pos(), "properties: unsupported node %s (%T)", internal.DebugStr(d), d)
+		}
+	}
+	return nil
+}
+
+func fieldName(x *ast.Field) (string, error) {
+	if x.Token == token.ISA {
+		return "", errors.Newf(x.TokenPos, "properties: definition not allowed")
+	}
+	if x.Optional != token.NoPos {
+		return "", errors.Newf(x.Optional, "properties: optional fields not allowed")
+	}
+	name, _, err := ast.LabelName(x.Label)
+	if err != nil {
+		return "", errors.Newf(x.Label.Pos(), "properties: only literal labels allowed")
+	}
+	return name, nil
+}
+
+// text returns the text of the value of the property with the given key.
+func text(key string, v ast.Expr) (string, error) {
+	switch x := v.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			return literal.Unquote(x.Value)
+		case token.INT, token.FLOAT:
+			var ni literal.NumInfo
+			if err := literal.ParseNum(x.Value, &ni); err != nil {
+				return "", err
+			}
+			return ni.String(), nil
+		case token.TRUE, token.FALSE:
+			return x.Value, nil
+		case token.NULL:
+			return "", nil
+		}
+
+	case *ast.UnaryExpr:
+		if b, ok := x.X.(*ast.BasicLit); ok && x.Op == token.SUB {
+			s, err := text(key, b)
+			return "-" + s, err
+		}
+
+	case *ast.ListLit:
+		return "", errors.Newf(v.Pos(), "properties: list value not allowed for property %q", key)
+	}
+	return "", errors.Newf(v.Pos(), "properties: unsupported value %s (%T) for property %q", internal.DebugStr(v), v, key)
+}
+
+// escape writes s as a key or value.
+func (e *encoder) escape(s string, isKey bool) {
+	for i, r := range s {
+		switch r {
+		case '\\':
+			e.WriteString(`\\`)
+		case '\t':
+			e.WriteString(`\t`)
+		case '\n':
+			e.WriteString(`\n`)
+		case '\r':
+			e.WriteString(`\r`)
+		case '\f':
+			e.WriteString(`\f`)
+		case ' ':
+			if isKey || i == 0 {
+				e.WriteByte('\\')
+			}
+			e.WriteByte(' ')
+		case '=', ':', '#', '!':
+			if isKey {
+				e.WriteByte('\\')
+			}
+			e.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, u := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(e, `\u%04x`, u)
+				}
+				continue
+			}
+			e.WriteRune(r)
+		}
+	}
+}
+
+func (e *encoder) comments(cg *ast.CommentGroup) {
+	for _, c := range cg.List {
+		e.WriteByte('#')
+		e.WriteString(strings.TrimPrefix(c.Text, "//"))
+		e.WriteByte('\n')
+	}
+}
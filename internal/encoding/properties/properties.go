@@ -0,0 +1,326 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package properties converts Java properties files to and from CUE.
+//
+// The syntax is that of java.util.Properties, except that the input is read
+// as UTF-8. Each property is converted to a field holding a string. Keys
+// containing dots are converted to nested structs, so that
+//
+//	server.host = example.com        server: {
+//	server.port = 8080                   host: "example.com"
+//	                                     port: "8080"
+//	                                 }
+//
+// Keys with empty components, such as "a..b", are not split. Comments
+// starting with # or ! are retained.
+//
+// Converting CUE to properties applies the reverse mapping: nested structs
+// are flattened to dotted keys.
+package properties
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/source"
+)
+
+// Extract parses the properties source to a CUE file.
+//
+// If src != nil, Extract parses the source from src and the filename is only
+// used when recording position information. The type of the argument for
+// the src parameter must be string, []byte, or io.Reader. If src == nil,
+// Extract parses the file specified by filename.
+func Extract(filename string, src interface{}) (*ast.File, error) {
+	b, err := source.Read(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		file:    token.NewFile(filename, -1, len(b)),
+		src:     string(b),
+		root:    &ast.StructLit{},
+		structs: map[string]*group{},
+		values:  map[string]bool{},
+	}
+	d.file.SetLinesForContent(b)
+	if err := d.decode(); err != nil {
+		return nil, err
+	}
+	return &ast.File{Filename: filename, Decls: d.root.Elts}, nil
+}
+
+type decoder struct {
+	file   *token.File
+	src    string
+	offset int
+
+	root    *ast.StructLit
+	structs map[string]*group // structs by dotted key
+	values  map[string]bool   // keys of properties
+
+	comments *ast.CommentGroup // pending comments
+	blank    bool              // a blank line precedes the current line
+	started  bool              // a comment or field has been converted
+}
+
+// A group is a struct for the properties sharing a dotted prefix.
+type group struct {
+	obj   *ast.StructLit
+	first string // key of the first property in the group
+}
+
+func (d *decoder) errf(offset int, format string, args ...interface{}) error {
+	pos := d.file.Pos(offset, token.NoRelPos)
+	return errors.Newf(pos, "properties: "+format, args...)
+}
+
+// rel returns the relative position of a declaration on the line starting at
+// offset.
+func (d *decoder) rel(offset int) token.Pos {
+	rel := token.Newline
+	switch {
+	case !d.started:
+		rel = token.NoRelPos
+	case d.blank:
+		rel = token.NewSection
+	}
+	d.started = true
+	d.blank = false
+	return d.file.Pos(offset, rel)
+}
+
+func (d *decoder) flush() {
+	if d.comments != nil {
+		d.root.Elts = append(d.root.Elts, d.comments)
+		d.comments = nil
+	}
+}
+
+func (d *decoder) decode() error {
+	for d.offset < len(d.src) {
+		start := d.offset
+		line := d.readLine()
+		trimmed := strings.TrimLeft(line, " \t\f")
+		start += len(line) - len(trimmed)
+		line = strings.TrimRight(trimmed, "\r")
+
+		switch {
+		case line == "":
+			d.flush()
+			d.blank = true
+
+		case line[0] == '#' || line[0] == '!':
+			if d.comments == nil {
+				d.comments = &ast.CommentGroup{}
+			}
+			text := "//" + strings.TrimRight(line[1:], " \t")
+			c := &ast.Comment{Slash: d.rel(start), Text: text}
+			d.comments.List = append(d.comments.List, c)
+
+		default:
+			firstLen := len(line)
+			for continues(line) {
+				next := strings.TrimRight(d.readLine(), "\r")
+				line = line[:len(line)-1] + strings.TrimLeft(next, " \t\f")
+			}
+			if err := d.property(line, start, firstLen); err != nil {
+				return err
+			}
+		}
+	}
+	d.flush()
+	return nil
+}
+
+// readLine returns the next physical line, excluding the newline.
+func (d *decoder) readLine() string {
+	rest := d.src[d.offset:]
+	i := strings.IndexByte(rest, '\n')
+	if i < 0 {
+		d.offset = len(d.src)
+		return rest
+	}
+	d.offset += i + 1
+	return rest[:i]
+}
+
+// continues reports whether line ends with an odd number of backslashes.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// property adds the property defined by the logical line that starts at
+// offset start. The first firstLen bytes of line are on the first physical
+// line.
+func (d *decoder) property(line string, start, firstLen int) error {
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+	if i > len(line) {
+		i = len(line)
+	}
+	key, err := unescape(line[:i])
+	if err != nil {
+		return d.errf(start, "%v", err)
+	}
+
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t' || line[i] == '\f') {
+		i++
+	}
+	if i < len(line) && (line[i] == '=' || line[i] == ':') {
+		i++
+	}
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t' || line[i] == '\f') {
+		i++
+	}
+	valueOffset := start
+	if i < firstLen {
+		valueOffset += i
+	}
+	s, err := unescape(line[i:])
+	if err != nil {
+		return d.errf(valueOffset, "%v", err)
+	}
+	value := ast.NewString(s)
+	value.ValuePos = d.file.Pos(valueOffset, token.Blank)
+
+	return d.add(key, value, start)
+}
+
+// add adds a field for the given key, creating structs for the dotted
+// components of the key as needed.
+func (d *decoder) add(key string, value ast.Expr, offset int) error {
+	path := strings.Split(key, ".")
+	for _, p := range path {
+		if p == "" {
+			path = []string{key}
+			break
+		}
+	}
+
+	parent := d.root
+	for i, name := range path {
+		prefix := strings.Join(path[:i+1], ".")
+		last := i == len(path)-1
+		if d.values[prefix] {
+			if last {
+				return d.errf(offset, "property %q redefined", key)
+			}
+			return d.errf(offset, "property %q conflicts with %q", key, prefix)
+		}
+		if g, ok := d.structs[prefix]; ok {
+			if last {
+				return d.errf(offset, "property %q conflicts with %q", key, g.first)
+			}
+			parent = g.obj
+			continue
+		}
+
+		field := &ast.Field{Label: label(name, d.rel(offset))}
+		parent.Elts = append(parent.Elts, field)
+		if d.comments != nil {
+			d.comments.Doc = true
+			ast.AddComment(field, d.comments)
+			d.comments = nil
+		}
+
+		if last {
+			d.values[prefix] = true
+			field.Value = value
+			break
+		}
+		obj := &ast.StructLit{Lbrace: d.file.Pos(offset, token.Blank)}
+		d.structs[prefix] = &group{obj: obj, first: key}
+		field.Value = obj
+		parent = obj
+	}
+	return nil
+}
+
+// unescape interprets the escape sequences of a key or value.
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	var surrogate rune
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
+		switch c = s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", errors.Newf(token.NoPos, `malformed \uxxxx encoding`)
+			}
+			n, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errors.Newf(token.NoPos, `malformed \uxxxx encoding`)
+			}
+			i += 4
+			r := rune(n)
+			switch {
+			case utf16.IsSurrogate(r) && surrogate == 0:
+				surrogate = r
+				continue
+			case surrogate != 0:
+				r = utf16.DecodeRune(surrogate, r)
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(c)
+		}
+		surrogate = 0
+	}
+	return b.String(), nil
+}
+
+func label(name string, pos token.Pos) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
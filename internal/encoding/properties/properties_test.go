@@ -0,0 +1,206 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package properties
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name  string
+		props string
+		want  string
+	}{{
+		name: "separators",
+		props: `a=1
+b : 2
+c 3
+d
+e=\ leading\tand\u00e9\ud83d\ude00
+`,
+		want: `
+a: "1"
+b: "2"
+c: "3"
+d: ""
+e: " leading\tandé😀"`,
+	}, {
+		name: "continuation",
+		props: `a = one \
+    two \\
+b = three
+`,
+		want: `
+a: "one two \\"
+b: "three"`,
+	}, {
+		name: "nested",
+		props: `# Server.
+server.host=example.com
+server.port=8080
+
+! Other.
+other.a..b=1
+key\ with\ spaces\=x=2
+server.tls.enabled=true
+`,
+		want: `
+// Server.
+server: {
+	host: "example.com"
+	port: "8080"
+	tls: {
+		enabled: "true"
+	}
+}
+
+// Other.
+"other.a..b":        "1"
+"key with spaces=x": "2"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Extract(tc.name, tc.props)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		props string
+		pos   string
+		err   string
+	}{{
+		props: "a=1\na=2\n",
+		pos:   "test:2:1",
+		err:   `properties: property "a" redefined`,
+	}, {
+		props: "a=1\na.b=2\n",
+		pos:   "test:2:1",
+		err:   `properties: property "a.b" conflicts with "a"`,
+	}, {
+		props: "a.b=1\na=2\n",
+		pos:   "test:2:1",
+		err:   `properties: property "a" conflicts with "a.b"`,
+	}, {
+		props: "a=\\u12\n",
+		pos:   "test:1:3",
+		err:   `properties: malformed \uxxxx encoding`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.props, func(t *testing.T) {
+			_, err := Extract("test", tc.props)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+			if got := err.(errors.Error).Position().String(); got != tc.pos {
+				t.Errorf("got position %s; want %s", got, tc.pos)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	r := &cue.Runtime{}
+	inst, err := r.Compile("test", `
+server: {
+	host: "example.com"
+	port: 8080
+	tls: enabled: true
+}
+"key with:sep": " leading é\n"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encode(inst.Value().Syntax(cue.Final()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `server.host=example.com
+server.port=8080
+server.tls.enabled=true
+key\ with\:sep=\ leading \u00e9\n
+`
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Converting back must yield the same strings.
+	f, err := Extract("test", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := r.CompileFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := back.Lookup("key with:sep").String()
+	if want, _ := inst.Lookup("key with:sep").String(); got != want {
+		t.Errorf("round trip: got %q; want %q", got, want)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	testCases := []struct {
+		cue string
+		err string
+	}{{
+		cue: `[1]`,
+		err: "properties: top-level value must be a struct, found [1]",
+	}, {
+		cue: `a: b: [1]`,
+		err: `properties: list value not allowed for property "a.b"`,
+	}, {
+		cue: `a: b: 1, "a.b": 2`,
+		err: `properties: duplicate property "a.b"`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			inst, err := r.Compile("test", tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Encode(inst.Value().Syntax(cue.Final()))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// Encode converts a CUE AST to XML.
+//
+// The given node must be a File or StructLit with a single field, which
+// represents the root element. It must only contain values that can be
+// directly supported by XML:
+//
+//	Type          Restrictions
+//	BasicLit
+//	File          no imports, aliases, or definitions
+//	StructLit     no embeddings, aliases, or definitions
+//	List          no lists of lists; not allowed for attributes
+//	Field         must be regular; label must be a BasicLit or Ident
+func Encode(n ast.Node) (b []byte, err error) {
+	var decls []ast.Decl
+	switch x := n.(type) {
+	case *ast.File:
+		decls = x.Decls
+	case *ast.StructLit:
+		decls = x.Elts
+	default:
+		return nil, errors.Newf(n.Pos(), "xml: top-level value must be a struct, found %s", internal.DebugStr(n))
+	}
+
+	var root *ast.Field
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Package, *ast.CommentGroup:
+			continue
+		case *ast.Field:
+			if root == nil {
+				root = x
+				continue
+			}
+		}
+		return nil, errors.Newf(d.Pos(), "xml: top-level value must be a struct with a single field")
+	}
+	if root == nil {
+		return nil, errors.Newf(n.Pos(), "xml: top-level value must be a struct with a single field")
+	}
+
+	e := &encoder{}
+	e.WriteString(xml.Header)
+	if err := e.field(root); err != nil {
+		return nil, err
+	}
+	e.WriteByte('\n')
+	return e.Bytes(), nil
+}
+
+type encoder struct {
+	bytes.Buffer
+	indent int
+}
+
+func (e *encoder) newline() {
+	e.WriteByte('\n')
+	for i := 0; i < e.indent; i++ {
+		e.WriteString("  ")
+	}
+}
+
+func fieldName(x *ast.Field) (string, error) {
+	if x.Token == token.ISA {
+		return "", errors.Newf(x.TokenPos, "xml: definition not allowed")
+	}
+	if x.Optional != token.NoPos {
+		return "", errors.Newf(x.Optional, "xml: optional fields not allowed")
+	}
+	name, _, err := ast.LabelName(x.Label)
+	if err != nil {
+		return "", errors.Newf(x.Label.Pos(), "xml: only literal labels allowed")
+	}
+	return name, nil
+}
+
+// field writes the elements for field x.
+func (e *encoder) field(x *ast.Field) error {
+	name, err := fieldName(x)
+	if err != nil {
+		return err
+	}
+	if !isName(name) {
+		return errors.Newf(x.Label.Pos(), "xml: invalid element name %q", name)
+	}
+	e.comments(x)
+	list, ok := x.Value.(*ast.ListLit)
+	if !ok {
+		return e.element(name, x.Value)
+	}
+	for i, elem := range list.Elts {
+		if _, ok := elem.(*ast.ListLit); ok {
+			return errors.Newf(elem.Pos(), "xml: nested lists not allowed")
+		}
+		if i > 0 {
+			e.newline()
+		}
+		if err := e.element(name, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// element writes an element with the given name for value v.
+func (e *encoder) element(name string, v ast.Expr) error {
+	e.WriteByte('<')
+	e.WriteString(name)
+
+	obj, ok := v.(*ast.StructLit)
+	if !ok {
+		text, err := e.text(v)
+		if err != nil {
+			return err
+		}
+		if text == "" {
+			e.WriteString("/>")
+			return nil
+		}
+		e.WriteByte('>')
+		e.escape(text)
+		e.WriteString("</")
+		e.WriteString(name)
+		e.WriteByte('>')
+		return nil
+	}
+
+	var children []*ast.Field
+	text := ""
+	for _, d := range obj.Elts {
+		switch x := d.(type) {
+		case *ast.CommentGroup:
+			continue
+		case *ast.Field:
+			fname, err := fieldName(x)
+			if err != nil {
+				return err
+			}
+			switch {
+			case fname == TextField:
+				if text, err = e.text(x.Value); err != nil {
+					return err
+				}
+			case strings.HasPrefix(fname, AttrPrefix):
+				attr := fname[len(AttrPrefix):]
+				if !isName(attr) {
+					return errors.Newf(x.Label.Pos(), "xml: invalid attribute name %q", attr)
+				}
+				s, err := e.text(x.Value)
+				if err != nil {
+					return err
+				}
+				e.WriteByte(' ')
+				e.WriteString(attr)
+				e.WriteString(`="`)
+				e.escape(s)
+				e.WriteByte('"')
+			default:
+				children = append(children, x)
+			}
+		default:
+			return errors.Newf(d.Pos(), "xml: unsupported node %s (%T)", internal.DebugStr(d), d)
+		}
+	}
+
+	if text == "" && len(children) == 0 {
+		e.WriteString("/>")
+		return nil
+	}
+	e.WriteByte('>')
+	e.escape(text)
+	if len(children) > 0 {
+		e.indent++
+		for _, c := range children {
+			e.newline()
+			if err := e.field(c); err != nil {
+				return err
+			}
+		}
+		e.indent--
+		e.newline()
+	}
+	e.WriteString("</")
+	e.WriteString(name)
+	e.WriteByte('>')
+	return nil
+}
+
+// text returns the text for a scalar value.
+func (e *encoder) text(v ast.Expr) (string, error) {
+	switch x := v.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			return literal.Unquote(x.Value)
+		case token.INT, token.FLOAT:
+			var ni literal.NumInfo
+			if err := literal.ParseNum(x.Value, &ni); err != nil {
+				return "", err
+			}
+			return ni.String(), nil
+		case token.TRUE, token.FALSE:
+			return x.Value, nil
+		case token.NULL:
+			return "", nil
+		}
+
+	case *ast.UnaryExpr:
+		if b, ok := x.X.(*ast.BasicLit); ok && x.Op == token.SUB {
+			s, err := e.text(b)
+			return "-" + s, err
+		}
+	}
+	return "", errors.Newf(v.Pos(), "xml: unsupported value %s (%T)", internal.DebugStr(v), v)
+}
+
+func (e *encoder) escape(s string) {
+	_ = xml.EscapeText(&e.Buffer, []byte(s))
+}
+
+// comments writes the doc comments of n as XML comments.
+func (e *encoder) comments(n ast.Node) {
+	for _, c := range ast.Comments(n) {
+		if c.Line || c.Position > 0 {
+			continue
+		}
+		text := strings.TrimSpace(c.Text())
+		text = strings.Replace(text, "--", "- -", -1)
+		e.WriteString("<!-- ")
+		e.WriteString(text)
+		e.WriteString(" -->")
+		e.newline()
+	}
+}
+
+// isName reports whether s is a valid XML name, optionally with a namespace
+// prefix.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r == ':' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r >= 0x80:
+		case i > 0 && (r == '-' || r == '.' || '0' <= r && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xml converts XML to and from CUE.
+//
+// An XML document is converted to a struct with a single field for the root
+// element. An element is converted to a field with the name of the element,
+// including its namespace prefix, if any. Its value is
+//
+//   - a string holding the text content, if the element has no attributes
+//     and no child elements, or
+//   - a struct otherwise, with a field for each attribute, each child
+//     element, and the text content, if it is not only white space.
+//
+// Attributes are converted to fields with the name of the attribute prefixed
+// with AttrPrefix. The text content of an element with attributes or child
+// elements is stored in a field named TextField, with leading and trailing
+// white space removed. Child elements with the same name are collected in a
+// list. All values are strings. For example,
+//
+//	<config version="2">             config: {
+//	  <name>web</name>                   "@version": "2"
+//	  <port>80</port>                    name:       "web"
+//	  <port>443</port>                   port: ["80", "443"]
+//	</config>                        }
+//
+// Comments preceding an element are retained. Processing instructions and
+// directives are dropped.
+//
+// Converting CUE to XML applies the reverse mapping. Numbers, booleans, and
+// null are converted to text.
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/source"
+)
+
+const (
+	// AttrPrefix is the prefix of fields that correspond to attributes.
+	AttrPrefix = "@"
+
+	// TextField is the name of the field holding the text content of an
+	// element with attributes or child elements.
+	TextField = "#text"
+)
+
+// Extract parses the XML source to a CUE file.
+//
+// If src != nil, Extract parses the source from src and the filename is only
+// used when recording position information. The type of the argument for
+// the src parameter must be string, []byte, or io.Reader. If src == nil,
+// Extract parses the file specified by filename.
+func Extract(filename string, src interface{}) (*ast.File, error) {
+	b, err := source.Read(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		file: token.NewFile(filename, -1, len(b)),
+		xml:  xml.NewDecoder(bytes.NewReader(b)),
+	}
+	d.file.SetLinesForContent(b)
+
+	f := &ast.File{Filename: filename}
+	var root *ast.Field
+	for {
+		start := int(d.xml.InputOffset())
+		tok, err := d.xml.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, d.wrap(err)
+		}
+		switch x := tok.(type) {
+		case xml.StartElement:
+			if root != nil {
+				return nil, errors.Newf(d.pos(start), "xml: multiple root elements")
+			}
+			doc := d.docComment()
+			root, err = d.element(x, start)
+			if err != nil {
+				return nil, err
+			}
+			if doc != nil {
+				root.AddComment(doc)
+			}
+			f.Decls = append(f.Decls, root)
+
+		case xml.CharData:
+			if len(bytes.TrimSpace(x)) > 0 {
+				return nil, errors.Newf(d.pos(start), "xml: text outside root element")
+			}
+
+		case xml.Comment:
+			d.comment(x, start)
+		}
+	}
+	if root == nil {
+		return nil, errors.Newf(token.NoPos, "xml: no root element")
+	}
+	for _, c := range d.comments {
+		f.Decls = append(f.Decls, &ast.CommentGroup{List: []*ast.Comment{c}})
+	}
+	return f, nil
+}
+
+type decoder struct {
+	file     *token.File
+	xml      *xml.Decoder
+	comments []*ast.Comment
+}
+
+func (d *decoder) pos(offset int) token.Pos {
+	return d.file.Pos(offset, token.NoRelPos)
+}
+
+func (d *decoder) wrap(err error) error {
+	pos := d.pos(int(d.xml.InputOffset()))
+	if x, ok := err.(*xml.SyntaxError); ok {
+		return errors.Newf(pos, "xml: %s", x.Msg)
+	}
+	return errors.Wrapf(err, pos, "xml")
+}
+
+// comment records an XML comment to be attached to the next element.
+func (d *decoder) comment(x xml.Comment, offset int) {
+	for i, line := range strings.Split(strings.TrimSpace(string(x)), "\n") {
+		pos := token.NoPos
+		if i == 0 {
+			pos = d.file.Pos(offset, token.Newline)
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if i > 0 {
+			line = strings.TrimLeft(line, " \t")
+		}
+		if line != "" {
+			line = " " + line
+		}
+		d.comments = append(d.comments, &ast.Comment{Slash: pos, Text: "//" + line})
+	}
+}
+
+// docComment returns the recorded comments as a doc comment, or nil if
+// there are none.
+func (d *decoder) docComment() *ast.CommentGroup {
+	if len(d.comments) == 0 {
+		return nil
+	}
+	cg := &ast.CommentGroup{Doc: true, List: d.comments}
+	d.comments = nil
+	return cg
+}
+
+// element converts the element started by start, which starts at offset,
+// up to and including its end element.
+func (d *decoder) element(start xml.StartElement, offset int) (*ast.Field, error) {
+	field := &ast.Field{
+		Label: label(qualifiedName(start.Name), d.file.Pos(offset, token.Newline)),
+	}
+	obj := &ast.StructLit{Lbrace: d.file.Pos(offset, token.Blank)}
+
+	for _, a := range start.Attr {
+		obj.Elts = append(obj.Elts, &ast.Field{
+			Label: label(AttrPrefix+qualifiedName(a.Name), d.file.Pos(offset, token.Newline)),
+			Value: ast.NewString(a.Value),
+		})
+	}
+
+	var text bytes.Buffer
+	textPos := token.NoPos
+	children := map[string]*ast.Field{}
+	hasChildren := false
+	for {
+		offset := int(d.xml.InputOffset())
+		tok, err := d.xml.RawToken()
+		if err == io.EOF {
+			return nil, errors.Newf(d.pos(offset), "xml: element <%s> not closed", qualifiedName(start.Name))
+		}
+		if err != nil {
+			return nil, d.wrap(err)
+		}
+
+		switch x := tok.(type) {
+		case xml.StartElement:
+			hasChildren = true
+			doc := d.docComment()
+			child, err := d.element(x, offset)
+			if err != nil {
+				return nil, err
+			}
+			name := qualifiedName(x.Name)
+			prev, ok := children[name]
+			if !ok {
+				if doc != nil {
+					child.AddComment(doc)
+				}
+				children[name] = child
+				obj.Elts = append(obj.Elts, child)
+				continue
+			}
+			list, ok := prev.Value.(*ast.ListLit)
+			if !ok {
+				list = &ast.ListLit{Elts: []ast.Expr{prev.Value}}
+				prev.Value = list
+			}
+			if doc != nil {
+				child.Value.AddComment(doc)
+			}
+			list.Elts = append(list.Elts, child.Value)
+
+		case xml.EndElement:
+			if x.Name != start.Name {
+				return nil, errors.Newf(d.pos(offset),
+					"xml: element <%s> closed by </%s>", qualifiedName(start.Name), qualifiedName(x.Name))
+			}
+			if len(obj.Elts) == 0 && !hasChildren {
+				lit := ast.NewString(text.String())
+				lit.ValuePos = textPos
+				field.Value = lit
+				return field, nil
+			}
+			if s := strings.TrimSpace(text.String()); s != "" {
+				lit := ast.NewString(s)
+				lit.ValuePos = textPos
+				obj.Elts = append(obj.Elts, &ast.Field{
+					Label: label(TextField, d.file.Pos(offset, token.Newline)),
+					Value: lit,
+				})
+			}
+			obj.Rbrace = d.file.Pos(offset, token.Newline)
+			field.Value = obj
+			for _, c := range d.comments {
+				obj.Elts = append(obj.Elts, &ast.CommentGroup{List: []*ast.Comment{c}})
+			}
+			d.comments = nil
+			return field, nil
+
+		case xml.CharData:
+			if !textPos.IsValid() && len(bytes.TrimSpace(x)) > 0 {
+				textPos = d.pos(offset)
+			}
+			text.Write(x)
+
+		case xml.Comment:
+			d.comment(x, offset)
+		}
+	}
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func label(name string, pos token.Pos) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "_") {
+		return &ast.Ident{NamePos: pos, Name: name}
+	}
+	return &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: strconv.Quote(name)}
+}
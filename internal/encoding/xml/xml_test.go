@@ -0,0 +1,225 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name string
+		xml  string
+		want string
+	}{{
+		name: "text",
+		xml:  `<a>foo</a>`,
+		want: `a: "foo"`,
+	}, {
+		name: "empty",
+		xml:  `<a/>`,
+		want: `a: ""`,
+	}, {
+		name: "structure",
+		xml: `<?xml version="1.0"?>
+<!-- The configuration. -->
+<config version="2">
+  <name>web</name>
+  <port>80</port>
+  <port>443</port>
+  <tls enabled="true">strict</tls>
+</config>`,
+		want: `
+// The configuration.
+config: {
+	"@version": "2"
+	name:       "web"
+	port: ["80", "443"]
+	tls: {
+		"@enabled": "true"
+		"#text":    "strict"
+	}
+}`,
+	}, {
+		name: "namespaces",
+		xml:  `<x:a xmlns:x="urn:x"><x:b>1</x:b></x:a>`,
+		want: `
+"x:a": {
+	"@xmlns:x": "urn:x"
+	"x:b":      "1"
+}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Extract(tc.name, tc.xml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	testCases := []struct {
+		xml string
+		pos string
+		err string
+	}{{
+		xml: `<a><b></a>`,
+		pos: "test:1:7",
+		err: "xml: element <b> closed by </a>",
+	}, {
+		xml: "<a>\n<b>",
+		pos: "test:2:4",
+		err: "xml: element <b> not closed",
+	}, {
+		xml: `<a/><b/>`,
+		pos: "test:1:5",
+		err: "xml: multiple root elements",
+	}, {
+		xml: `text`,
+		pos: "test:1:1",
+		err: "xml: text outside root element",
+	}, {
+		xml: ``,
+		pos: "-",
+		err: "xml: no root element",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.xml, func(t *testing.T) {
+			_, err := Extract("test", tc.xml)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+			if got := err.(errors.Error).Position().String(); got != tc.pos {
+				t.Errorf("got position %s; want %s", got, tc.pos)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		name string
+		cue  string
+		want string
+	}{{
+		name: "structure",
+		cue: `
+config: {
+	"@version": "2"
+	name:       "web & <db>"
+	port: [80, 443]
+	tls: {
+		"@enabled": true
+		"#text":    "strict"
+	}
+	empty: ""
+}`,
+		want: `<?xml version="1.0" encoding="UTF-8"?>
+<config version="2">
+  <name>web &amp; &lt;db&gt;</name>
+  <port>80</port>
+  <port>443</port>
+  <tls enabled="true">strict</tls>
+  <empty/>
+</config>`,
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, err := r.Compile(tc.name, tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Encode(inst.Value().Syntax(cue.Final()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(string(b))
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+
+			// Converting back must yield the original XML.
+			f, err := Extract(tc.name, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := r.CompileFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b2, err := Encode(back.Value().Syntax(cue.Final()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b2) != string(b) {
+				t.Errorf("round trip:\n%s\nwant:\n%s", b2, b)
+			}
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	testCases := []struct {
+		cue string
+		err string
+	}{{
+		cue: `a: 1, b: 2`,
+		err: "xml: top-level value must be a struct with a single field",
+	}, {
+		cue: `"a b": 1`,
+		err: `xml: invalid element name "a b"`,
+	}, {
+		cue: `a: [[1]]`,
+		err: "xml: nested lists not allowed",
+	}, {
+		cue: `a: "@b": {}`,
+		err: "xml: unsupported value {} (*ast.StructLit)",
+	}}
+	r := &cue.Runtime{}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			inst, err := r.Compile("test", tc.cue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Encode(inst.Value().Syntax(cue.Final()))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := err.Error(); got != tc.err {
+				t.Errorf("got %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
+	yamlv2 "cuelang.org/go/internal/third_party/yaml"
 )
 
 // Encode converts a CUE AST to YAML.
@@ -114,7 +115,7 @@ func encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
 		if err != nil {
 			return nil, err
 		}
-		n.SetString(str)
+		setString(n, str)
 
 	default:
 		return nil, errors.Newf(b.Pos(), "unknown literal type %v", b.Kind)
@@ -122,6 +123,15 @@ func encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
 	return n, nil
 }
 
+// setString sets n to the string s, quoting it if it may be decoded as a
+// different type by either YAML 1.1 or YAML 1.2 decoders.
+func setString(n *yaml.Node, s string) {
+	n.SetString(s)
+	if !yamlv2.IsPlainString(s) {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+}
+
 func setNum(n *yaml.Node, s string, x interface{}) error {
 	if yaml.Unmarshal([]byte(s), x) == nil {
 		n.Value = s
@@ -192,7 +202,7 @@ func encodeDecls(decls []ast.Decl) (n *yaml.Node, err error) {
 
 			label := &yaml.Node{}
 			addDocs(x.Label, label, label)
-			label.SetString(name)
+			setString(label, name)
 
 			value, err := encode(x.Value)
 			if err != nil {
@@ -137,17 +137,26 @@ modes: def: {
 
 // Extension maps file extensions to default file properties.
 extensions: {
-	"":        _
-	".cue":    tags.cue
-	".json":   tags.json
-	".jsonl":  tags.jsonl
-	".ldjson": tags.jsonl
-	".ndjson": tags.jsonl
-	".yaml":   tags.yaml
-	".yml":    tags.yaml
-	".txt":    tags.text
-	".go":     tags.go
-	".proto":  tags.proto
+	"":            _
+	".cue":        tags.cue
+	".json":       tags.json
+	".jsonl":      tags.jsonl
+	".ldjson":     tags.jsonl
+	".ndjson":     tags.jsonl
+	".jsonc":      tags.jsonc
+	".json5":      tags.jsonc
+	".yaml":       tags.yaml
+	".yml":        tags.yaml
+	".txt":        tags.text
+	".go":         tags.go
+	".ts":         tags.ts
+	".proto":      tags.proto
+	".hcl":        tags.hcl
+	".xml":        tags.xml
+	".csv":        tags.csv
+	".tsv":        tags.tsv
+	".env":        tags.env
+	".properties": tags.properties
 	// TODO: jsonseq,
 	// ".textproto": tags.textpb
 	// ".pb":        tags.binpb
@@ -176,12 +185,33 @@ tags: {
 	dag: form:    "dag"
 	data: form:   "data"
 
+	// docs includes doc comments in the output of data encodings that
+	// support comments.
+	docs: tags: docs: "true"
+
+	// refs represents YAML anchors and aliases as CUE aliases and references
+	// instead of expanding them.
+	refs: tags: refs: "true"
+
+	// 1.2 selects the YAML 1.2 core schema for resolving unquoted YAML values.
+	"1.2": tags: version: "1.2"
+
+	// canonical selects canonical JSON output as defined by RFC 8785.
+	canonical: tags: canonical: "true"
+
 	cue: encoding: "cue"
 
-	json: encoding:  "json"
-	jsonl: encoding: "jsonl"
-	yaml: encoding:  "yaml"
-	proto: encoding: "proto"
+	json: encoding:       "json"
+	jsonl: encoding:      "jsonl"
+	jsonc: encoding:      "jsonc"
+	yaml: encoding:       "yaml"
+	proto: encoding:      "proto"
+	hcl: encoding:        "hcl"
+	xml: encoding:        "xml"
+	csv: encoding:        "csv"
+	tsv: encoding:        "tsv"
+	env: encoding:        "env"
+	properties: encoding: "properties"
 	// "textpb": encodings.textproto
 	// "binpb":  encodings.binproto
 	text: {
@@ -193,6 +223,11 @@ tags: {
 		interpretation: ""
 		tags: lang: "go"
 	}
+	ts: {
+		encoding:       "code"
+		interpretation: ""
+		tags: lang: "ts"
+	}
 	code: {
 		encoding:       "code"
 		interpretation: ""
@@ -291,6 +326,11 @@ encodings: jsonl: {
 	stream: true
 }
 
+encodings: jsonc: {
+	forms.data
+	stream: false
+}
+
 encodings: text: {
 	forms.data
 	stream: false
@@ -301,6 +341,36 @@ encodings: toml: {
 	stream: false
 }
 
+encodings: hcl: {
+	forms.data
+	stream: false
+}
+
+encodings: xml: {
+	forms.data
+	stream: false
+}
+
+encodings: csv: {
+	forms.data
+	stream: true
+}
+
+encodings: tsv: {
+	forms.data
+	stream: true
+}
+
+encodings: env: {
+	forms.data
+	stream: false
+}
+
+encodings: properties: {
+	forms.data
+	stream: false
+}
+
 encodings: proto: {
 	forms.schema
 	encoding: "proto"
@@ -48,4 +48,4 @@ func cuegenMake(name string, x interface{}) cue.Value {
 }
 
 // Data size: 1122 bytes.
-var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xccW_k\xe46\x10\xb76)T\xe2Z\xee\v\x14T?\x1c\u05c5\xeeC_\n\vG^\xae\aG\xa1\x94\xbe\x86#(^\xed\xc6=[2\xb6\\\x12\x9a}h{\xbd\xf6S\u07d6\xd1H\x96%{\x13\x92\x96r~I\xf67\x9a?\xfa\u034c4\xfa\xec\xf0\u05c2,\x0e\u007fg\xe4\xf0{\x96}{\xf8\ud110'\xa5\xea\x8cP\x85|)\x8c\x00\x9c\x9c\x90\u04df\xb46d\x91\x91\xd3\x1f\x85\xb9\"O2\xf2\u026b\xb2\x92\x1d9\xbc\u03f2\xec\x8b\u00df\vB>?\u007fS\xf4r\xb5-+\xa7\xf9>#\x87wY\xf6\xfc\xf0\xc7\t!\x9f\x06\xfc]F\x16\xe4\xf4\aQK0tjA\x96e\u0647\xa7\xdfC$\x84,\b\xa1\u6991\u076a\xe8%\xf9\xf0\xf4\x9bF\x14o\xc5N\xf2\u02fe\xac6\x8c\x81k\xbe^\xf3_\x19\x05\xabJ\xd4r\xcd\xdd\u05d9\xb6T;F\xa5*\xf4\xa6T\xbbA\xf0\x9d\x03\x18-\x95\x91m\xd3J#L\xa9\xd5\u065a\xbf\x8e\x00F\xb7\xba\xad\xcf\x06E\xce\xf9+\xdd\u058c\x1a\xb1\xeb\u03acWz\x8en\u07ac\a\u007f{\xb6g\xde\x05\u0106\u07d7/\xf2\x9c\xc5\xe6A\xe8\x94\xc0lX;\x8a\x1e<YGF^\x1b\xf4\x18\xf6\x93\x03\x983j\xc3D\xe5|#\x8c\xc8!\b\n\xff\xa1\x06\x8aG\xa2\xa2\x97\x13[E/Q\xd8\x15W\xb2\x8e5\x11B\xf1\u03ddV\x13e\x00\x83\xb8\x9a\x95W\xb8\xe0F\xd4S9\x80(\xde\xe9T\x88_^\xe8\r\x04\x98\xe4l\xcds\x00\a\x9a(\xad\x84\xb5\xb8\u04c0\xef\xad\u0366\xd5fb6\xb7\xa8s\u068a\xe6*\u06b1E<\x8f\xbb\x84\u019dcQo&4>*X_9.\\\xd1O\xa3\xc5o\x89D\xf3[~1g\x1c\x14C\x16\xc6y|\xa0\xa1\xa0\x8e\xe6t#\x95h\xcaG\xd9r\xba96\xc6K\xb9\x15}e\xa0\xd6m\xef>\x8b[w\x99\u007f\r\x86\x1c!{\xdb\u07ef\xd5V\xbb\x1e\u01d2\xf6\xdf\u04b4\xbd\xe4\xb7|+\xaaN2\xda\u02adl\xa5*d\xb7\x9e\n\x8b\x9b\xa2B\xc1\x8c\xe6FnKUB\xbc\xb0\xe2R\xeb\n\xb6\f\xbfE\x85*\x88\x15Zu\xa6\x15\xa52a\xdd[)\x1b\xb7\xa9n\xed\xb0R\x15\xban*i\xeca\u4c3a\u046d\xf1\x11 \u0599V\x8a\xda\a\x85\xd8F\x17]\xd8\"b\u0098\xb6\xbc\xec\rn\xc0\u017et\xc1\x03El\xcfj\xbd\x91XU\xa5jzwT\x8c\u0636\xe5\x16R\xb7\xb4\xfd\xeerFW\xab\x15V\x1fM\b\xa7.\x9e\x84\xb0\xb1\x86\xb7\xe9Kz8[(]B\xc7t+\xac$\xefk\xef\xf5\xae\x8dT\x1drn\x97\xe7+[G^9-\xa4%\x96wd\x06\xcb\aT\xed\x01\xf2H\xd5\aj\xdaBFuy\rI\xbd\x9f\xebq\x83\xdcOv\x92\xe0\x87\x91\r-r,\\\xdd7\u0197\xc6\xff\xed[\xfe\"\xaa\xff\xb8(\xffU\xac\xdbR\x89\xeaX\xb0\x1b\xb9\xfd\xe8\x1b\bN\xd3XuP\xf4\xe7\x8as6l+\\\xdd~\x05r\xc7o-\x97\x8c\x0e\xa7\x8f\x0f3\xe4ud&\xdc\xe0\x91#H\x853\x83j\xf6\x0eM\xdc'\x8a\xd1\xfa\xc4M\x98x\x92\xfd\x1cY\xaeg\xa3:\xb6\xfc\ue640\x8e\x89\x1fi\x85+\u007f\xde\xc9H\x81Y\xc4.>\x87\t\xf7\xc50(\xfa\x12\xf1F\xf3|\xcd/\xfc\x8f\xe9\xf45\\w~\f\xe3\xb7<\xb7\xe5k\xff\xf3\x93Jr\x8b\xa5\x05\x17\xdfg\xcf#\xf1W\xfcY\x8a0\x9a\xdcv\xa9\xbd\xf8\xdeK\xa5\xf1\r8\x91Fwa*\x8do\u0164\u0247LX\x02\xe6hr\xd4L\xb6<\x1f\xf8\xd1\xf4\xa1\x97\xc9`\x18\x92\x81\xbcC\x06` \u013fv\xbeN\xa6\x14\xdfYQv\xe6\xb3\xe2\u0458\xf9\xbb\x03\x8f\x99\x9eg8\xe5.\x99h\xed\xe3d(#?)\xc5\u0324\xbd\x9c>.\xc2+\a\xc7\xe3x\x1a\x1b66\x9e\xc2f9\x98\xa5`vWiw\xbb\x97W2V@o\xb9\uf085\x11\x83s\x18\xc2\xed/\x16\xa6\a\x87\xc2/6\f\x06)\n\xc7~@\vH.\x9a\xb5\x8b\a\xb3vm\xb5q\xfebX\xcd\xc3\xe6\u068c,\xc3\xf1\a\xe8N\xbb-Xt\xa7\x01\xc3c\u02bb\xb3\xbf\xe0\xc0)+\x19N\x97t\xb4\u03b7Z\xaf\xdc;m\xf4^\xf4c\xfe\x9e\u0173\xcf\xc3O\xae\xf0l9\xd2L\xc7\x1f%\xf1\x84tD\xfd\xc8#\xe4\x1e]\x96e\xff\x04\x00\x00\xff\xff\xbe\xea\xa5\xf0\xdb\x10\x00\x00")
+var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xccX\u07cb\x1b7\x10^9W\u82b4Oy-(z\b\xa9\xa1\x86\x16\xda\aC\xc8K\x1a\xc8K)}\r!\xe8dy\xbd\u036e\xb4\xacd\xe3\xa3g\u06a6i\xdf\xfb\x0f\xc7e\xf4cw\xa5]\u07dd\xd3Pj\x02\xb9\x9bo\xbe\x99\xd1\xcch<\xba\u03ce\x7f\xce\xd0\xec\xf8W\x86\x8e\xbfe\xd9w\xbf\xdeC\xe8~)\xb5a\x92\x8bg\xcc0\x10\xa3{\xe8\xe2'\xa5\f\x9ae\xe8\xe2Gf6\xe8~\x86>y^VB\xa3\xe3\xbb,\u02fe8\xfe1C\xe8\xf3\x97\xaf\xf8V,\xd6e\xe5\x99\xef2t|\x9be\x8f\x8f\xbf\xdfC\xe8\xd3^\xfe6C3t\xf1\x03\xab\x05\x18\xba\xb0B\x9ce\xd9\xfb\a\x7fC \b\xcd\x10\xca\xcdU#\xf4\x82o\x05z\xff`\xdf0\xfe\x86\x15\x82\\n\xcbj\x851\xb8&\xcb%\xf9\x05\xe7`U\xb2Z,\x89\xffh\u04d6\xb2\xc0\xb9\x90\\\xadJYt\xc0\xf7^\x80\xf3R\x1a\xd16\xad0\u0314J>]\x92\x17\x91\x00\xe7k\xd5\xd6O;\"!\xe4\xb9jk\x9c\x1bV\xe8\xa7\xd6k\xfe\u04b9y\xb5\xec\xfc\x1d\xf0\x01\a\x17\x10\x9b#>|B)\x8e\u0353eG\x02\xb3\xbd\xee z\xf0d\x1d\x19\xb17\xf6\x87\xc1y(\b)\xcem\x98\x8eLW\xcc0\nA\xe4B\xeeF\x04!w\x0e\x045\x87:\ue037R\\;\xa8s\x9e{!5\xedV\x80\u00c3\xd5\xe4[1\xf2\xc0\xad\xc2\x01\xe7\x9aoD\x1d\xfbp\"\a\xff\xac\x95\x1c\x91A\xd8\xc3\xd5$^\xf5\n|R\x81;\x85+V\x8f\r\x80\xd0\xc1\x85JAH\x1f!\x94\xab\x15\x9c \xe9\x8d%\xa14\xceH\u0140D\v\xd5\xe7\xc3\xe8\x8fc\xd3\xe8\xdef\xd3*3\n\x95Z\xa9;\u0206\x8f\x8f\xb9\xe1\xfe\x94\xfb\x89\x1c\xecC\n\xb8\x1e7\b\u05feA\xcc\x04h\x02\u0634\xaa\x11\xad)\xc5\xe8\xc0\xb4\x87|\x9e[\xd6l\xa2.\xb0\x92\u0405E\x04\xadX\xe1\x80V\xac\xbd\xe9Av\x9c0\xe9A\xfa\xf5\xe2\x1b:R\u0749V\xbb\xaa\x01\xdc)s&\x95,9\xabF\x84\x01\x926\xb9Z\x89\x8fRV\x7f\xa3\x83]\xb6\x1d\xd7\u055a%s\xdb\u0194\\\x93\xd7S\u0181\xd8\u07c1\xe15;\xd3POw\xe6T#$k\xca\x0f\xb2\xe5\xb9\xd6\xd0\x01?\x13k\xb6\xad\fL4;\xa1\x1f\xc5\x03zN\xbf\x02C>!\a;\xc5_\u0235\xf2\x93\x1cFQpK\xc8\x1c\xcaA\xae\u025aUZ\xd8\xce\x10\xad\x90\x1cZo\x04\xf2+^9`\x82\xb9\x12\xebR\x96\x10/h\\*U\xc1\x91\xe1w\xe8\a 8\x19WR\x9b\x96\x95\xd2\xf4zo\x84h\xfc\xa1\xf4\xd2\xcbJ\xc9U\xddT\xc2\u062f\x1c/\xab\x1b\u055a\x10\x81\x93i\xd3\nV\x87\xa0\x9c\u030d\xd3\xf0q2fL[^n\x8d;\x80\x8f}\ue0c7\x14\xe1\x03\xae\xd5\xca\u07f9R6[\xff\x850\xc86TnP\xba\xb9\x1d\u01fef\xf9b\xb1p]\x9d'\t\x0f\xe3=I\u0610\x11lv\xf7%\x8c\xfe<\x9f\xc3\xe5\xd5\v\xd7I\xc1\xd7!\xf0\xf6FH\xb8\x8a\x81H\x17\xb6\x8f\x029m\xa4\xb9k\xef\xc8\fy\x14\xa8v|\x7f \xf5L\xa6mdG\x17{(\xea\xed\xb9\x1e^\x90\u06d3\x9d\x14\xf8\xbcd\xc3\x159\x15\xae\xda6&\xb4\xc6\x7f\xed[\xecXu{\xa2\xcej\xca\x7f\x15\ube94\xac:\x15\xecJ\xac\xff\xf7\x17\b\x96\u0258\xda\x11\xc3\\\xf1\u03bac\xf5\x9bU\xd0pu&\u05f6\xee8\x04K\xba0\xfb\xba\x0e\xcc\xf4\xfbS\xe4\bJ\xe1\xcd8\x9a\xfd:O\xdc'\xc4H?q3\xd8\xe3\"?\xa7\xf4\xfb=\xf8n\uaaae\xceP\xdf\xf0s\xb4\xf7\xf59\xda\u077au\x97\xb4\x98s\x94\x85L\x94o\x8c#]\xde\xeeJ\xbaa\r\xf5\x1c\xd7\xc1\x03V\xbf;M;\x19\x10\xb0\x95X\xe5\x97\xf0 |\u04bd\xab\xc2]\vF)]\x92\xd7\xe1\x97\xf1+#|\xe6\xe1\xb9A\xae\t\xb5s\x00fs\xb7}&\xeb@zs\xe3\xc5\xe0q\x04\x7fI\x1e\xa5\x12\x9c'kC\x04\xe3<Y R4^%Fh\xb4T\xa4h\u022c\x05\xd3\xc9\xdeU\xc2&`*M>5\xa3#O\a~\xb2|\xae\x1a\xa3e?x\x99\xfb\xbcC\x05`\xc9w\xff\u06d7j\xb2\xee\xf9>\x8f\xab3]\x95 \x8d3\x7fs\xe0q\xa6\xa73\x9c\xe6.y\xa5\u0637|\xd7Fa\xe5\f\xa6\xa6\x87b\xfa\xdc\xf6\x9f\x87Ol6p\x1e\xaf\xb5\xc1V\xb0\x1d\x9d6\xca\xc1d\n&O\x95\xde\xee\xc2\x7f\xb7\xc4\xfb\x19\xa5]l\xf0\xef5\xee\xf75_\x1eVh+\xc1\xfd:6@@\x82\xbbmk\n\x81\xef\xd3\x18\xe1Pp\xe7\xa6#un*\x80\xaa\xd5 \x86\x18\x92\xa7!\x008]&q\xf3\x00};\r\x99\xbd\t\x80\x87\xe0\x1b\a\x90B\xf5\x80C\n\x05r\xa3S\xb9\xd1 wot\x0fY}+\x01\b\xde\xe7\x1d\xc7B\x1bnO\xba\x1f\xe5m\xef\u04e6w\t\xc0\xf5\x0e\x003\x02\x8c\x03\x84L\x01!w>\xae\xf0B_vqy\t>`x\xa6\xf5C7}\xba\u0475R\v\xffg\x9a\xbe\x95\xbb\xbf\xec\x1cp\xbc[\x9f?\xd0\xfbg\xf1\x89\x19s\xfa\xd1\x1bo\xe0'\xe8'\x1e\xb9\xb7pq\x96\xfd3\x00J\xfe\xd6\xdc \x15\x00\x00")
@@ -70,6 +70,9 @@ var FromGoType func(instance, x interface{}) interface{}
 // UnifyBuiltin returns the given Value unified with the given builtin template.
 var UnifyBuiltin func(v interface{}, kind string) interface{}
 
+// BuiltinPackages reports the import paths of all builtin packages.
+var BuiltinPackages func() []string
+
 // GetRuntime reports the runtime for an Instance or Value.
 var GetRuntime func(instance interface{}) interface{}
 
@@ -81,6 +84,10 @@ var MakeInstance func(value interface{}) (instance interface{})
 // keys.
 var CheckAndForkRuntime func(runtime, value interface{}) interface{}
 
+// ArchiveFiles parses the files of the root instances stored in data
+// generated by cue.Runtime.Marshal. The names of the files are joined to dir.
+var ArchiveFiles func(data []byte, dir string) ([]*ast.File, error)
+
 // BaseContext is used as CUEs default context for arbitrary-precision decimals
 var BaseContext = apd.BaseContext.WithPrecision(24)
 
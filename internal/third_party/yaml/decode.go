@@ -33,8 +33,12 @@ type node struct {
 	tag      string
 	// For an alias node, alias holds the resolved alias.
 	alias    *node
+	anchor   string
 	value    string
 	implicit bool
+	// For a mapping or sequence node, flow reports whether it uses the flow
+	// style.
+	flow     bool
 	children []*node
 	anchors  map[string]*node
 }
@@ -47,6 +51,7 @@ type parser struct {
 	event    yaml_event_t
 	doc      *node
 	info     *token.File
+	lines    [][]byte
 	last     *node
 	doneInit bool
 }
@@ -82,7 +87,7 @@ func newParser(filename string, src interface{}) (*parser, error) {
 	}
 	info := token.NewFile(filename, -1, len(b)+2)
 	info.SetLinesForContent(b)
-	p := parser{info: info}
+	p := parser{info: info, lines: bytes.Split(b, []byte("\n"))}
 	if !yaml_parser_initialize(&p.parser, filename) {
 		panic("failed to initialize YAML emitter")
 	}
@@ -161,6 +166,7 @@ func (p *parser) fail() {
 
 func (p *parser) anchor(n *node, anchor []byte) {
 	if anchor != nil {
+		n.anchor = string(anchor)
 		p.doc.anchors[string(anchor)] = n
 	}
 }
@@ -198,6 +204,10 @@ func (p *parser) node(kind int) *node {
 func (p *parser) document() *node {
 	n := p.node(documentNode)
 	n.anchors = make(map[string]*node)
+	// The value of a document node is its YAML version, if specified.
+	if v := p.event.version_directive; v != nil {
+		n.value = fmt.Sprintf("%d.%d", v.major, v.minor)
+	}
 	p.doc = n
 	p.expect(yaml_DOCUMENT_START_EVENT)
 	n.children = append(n.children, p.parse())
@@ -228,6 +238,7 @@ func (p *parser) scalar() *node {
 
 func (p *parser) sequence() *node {
 	n := p.node(sequenceNode)
+	n.flow = p.event.sequence_style() == yaml_FLOW_SEQUENCE_STYLE
 	p.anchor(n, p.event.anchor)
 	p.expect(yaml_SEQUENCE_START_EVENT)
 	for p.peek() != yaml_SEQUENCE_END_EVENT {
@@ -242,6 +253,7 @@ func (p *parser) sequence() *node {
 
 func (p *parser) mapping() *node {
 	n := p.node(mappingNode)
+	n.flow = p.event.mapping_style() == yaml_FLOW_MAPPING_STYLE
 	p.anchor(n, p.event.anchor)
 	p.expect(yaml_MAPPING_START_EVENT)
 	for p.peek() != yaml_MAPPING_END_EVENT {
@@ -261,6 +273,11 @@ type decoder struct {
 	p            *parser
 	doc          *node
 	aliases      map[*node]bool
+	refs         bool             // represent aliases as references
+	core         bool             // use the YAML 1.2 core schema
+	anchors      map[*node]string // referenced anchors to CUE alias names
+	defined      map[*node]bool
+	defs         []ast.Decl
 	mapType      reflect.Type
 	terrors      []string
 	prev         token.Pos
@@ -300,6 +317,9 @@ func (d *decoder) terror(n *node, tag string) string {
 }
 
 func (d *decoder) unmarshal(n *node) (node ast.Expr) {
+	if _, ok := d.anchors[n]; ok && !d.aliases[n] {
+		return d.reference(n, d.pos(n.startPos))
+	}
 	switch n.kind {
 	case documentNode:
 		node = d.document(n)
@@ -320,46 +340,152 @@ func (d *decoder) unmarshal(n *node) (node ast.Expr) {
 	return node
 }
 
-func (d *decoder) attachDocComments(m yaml_mark_t, pos int8, expr ast.Node) {
-	comments := []*ast.Comment{}
-	line := 0
+// comment converts a YAML comment to a CUE comment.
+func (d *decoder) comment(c yaml_comment_t) *ast.Comment {
+	return &ast.Comment{
+		Slash: d.pos(c.mark),
+		Text:  "//" + c.text[1:],
+	}
+}
+
+// commentGroups consumes the pending comments that start before m and returns
+// them as comment groups. Comments on consecutive lines form a single group.
+// It also returns the line of the last comment.
+func (d *decoder) commentGroups(m yaml_mark_t) (groups []*ast.CommentGroup, line int) {
 	for len(d.p.parser.comments) > 0 {
 		c := d.p.parser.comments[0]
 		if c.mark.index >= m.index {
 			break
 		}
-		comments = append(comments, &ast.Comment{
-			Slash: d.pos(c.mark),
-			Text:  "//" + c.text[1:],
-		})
 		d.p.parser.comments = d.p.parser.comments[1:]
+		if len(groups) == 0 || c.mark.line > line+1 {
+			groups = append(groups, &ast.CommentGroup{})
+		}
+		g := groups[len(groups)-1]
+		g.List = append(g.List, d.comment(c))
 		line = c.mark.line
 	}
-	if len(comments) > 0 {
-		expr.AddComment(&ast.CommentGroup{
-			Doc:      pos == 0 && line+1 == m.line,
-			Position: pos,
-			List:     comments,
-		})
+	return groups, line
+}
+
+// attachDocComments attaches the pending comments that start before m to
+// expr. A comment group that ends on the line directly before m becomes the
+// doc comment of expr. If rest is false, the other groups are attached to
+// expr as well. Otherwise they are returned.
+func (d *decoder) attachDocComments(m yaml_mark_t, expr ast.Node, rest bool) []*ast.CommentGroup {
+	groups, line := d.commentGroups(m)
+	if len(groups) == 0 {
+		return nil
+	}
+	last := groups[len(groups)-1]
+	if line+1 == m.line {
+		last.Doc = true
+	}
+	if !rest || !last.Doc {
+		for _, g := range groups {
+			expr.AddComment(g)
+		}
+		return nil
 	}
+	expr.AddComment(last)
+	return groups[:len(groups)-1]
 }
 
-func (d *decoder) attachLineComment(m yaml_mark_t, pos int8, expr ast.Node) {
+// prependComment adds cg to n before any of the existing comments of n.
+func prependComment(n ast.Node, cg *ast.CommentGroup) {
+	ast.SetComments(n, append([]*ast.CommentGroup{cg}, n.Comments()...))
+}
+
+// hasLineComment reports whether a pending comment starts on the line on
+// which the node ending at m ends.
+func (d *decoder) hasLineComment(m yaml_mark_t) bool {
 	if len(d.p.parser.comments) == 0 {
-		return
+		return false
+	}
+	c := d.p.parser.comments[0]
+	// Block scalars end at the start of the line following their content.
+	return c.mark.line == m.line && c.mark.index >= m.index && m.column != 0
+}
+
+// lineComment consumes and returns a pending comment that starts on the line
+// on which the node ending at m ends. It returns nil if there is no such
+// comment.
+func (d *decoder) lineComment(m yaml_mark_t) *ast.CommentGroup {
+	if !d.hasLineComment(m) {
+		return nil
 	}
 	c := d.p.parser.comments[0]
-	if c.mark.index == m.index {
-		comment := &ast.Comment{
-			Slash: d.pos(c.mark),
-			Text:  "//" + c.text[1:],
+	d.p.parser.comments = d.p.parser.comments[1:]
+	return &ast.CommentGroup{List: []*ast.Comment{d.comment(c)}}
+}
+
+// attachLineComment attaches a pending comment that starts on the line on
+// which the node ending at m ends as a line comment to expr.
+func (d *decoder) attachLineComment(m yaml_mark_t, pos int8, expr ast.Node) {
+	if g := d.lineComment(m); g != nil {
+		g.Line = true
+		g.Position = pos
+		expr.AddComment(g)
+	}
+}
+
+// footComments consumes the pending comments that follow the node ending at
+// m, are indented by at least col columns, and are only separated from this
+// node by other comments and blank lines. Such comments trail the collection
+// of which this node is the last element. A last group of comments that is
+// directly followed by a node at the same level documents that node instead
+// and is not consumed.
+func (d *decoder) footComments(m yaml_mark_t, col int) []*ast.CommentGroup {
+	comments := d.p.parser.comments
+	n, group := 0, 0
+	line := m.line
+	for ; n < len(comments); n++ {
+		c := comments[n].mark
+		if c.index < m.index || c.line == m.line || c.column < col ||
+			!d.isBlankOrComment(c.line) {
+			break
+		}
+		blank := line
+		for blank+1 < c.line && d.isBlankOrComment(blank+1) {
+			blank++
+		}
+		if blank+1 < c.line {
+			break
+		}
+		if c.line > line+1 {
+			group = n
 		}
-		expr.AddComment(&ast.CommentGroup{
-			Line:     true,
-			Position: pos,
-			List:     []*ast.Comment{comment},
-		})
+		line = c.line
+	}
+	if n == 0 {
+		return nil
+	}
+	if next := line + 1; next < len(d.p.lines) && !d.isBlankOrComment(next) &&
+		indentation(d.p.lines[next]) >= col {
+		n = group
+	}
+	if n == 0 {
+		return nil
+	}
+	end := comments[n-1].mark
+	end.index++
+	groups, _ := d.commentGroups(end)
+	return groups
+}
+
+// isBlankOrComment reports whether the given zero-based line of the source
+// consists of only whitespace and an optional comment.
+func (d *decoder) isBlankOrComment(line int) bool {
+	if line >= len(d.p.lines) {
+		return true
 	}
+	s := bytes.TrimLeft(d.p.lines[line], " \t\r")
+	return len(s) == 0 || s[0] == '#'
+}
+
+// indentation returns the number of leading spaces of line.
+func indentation(line []byte) int {
+	return len(line) - len(bytes.TrimLeft(line, " "))
 }
 
 func (d *decoder) pos(m yaml_mark_t) token.Pos {
@@ -411,12 +537,40 @@ func (d *decoder) ident(n *node, name string) *ast.Ident {
 func (d *decoder) document(n *node) ast.Expr {
 	if len(n.children) == 1 {
 		d.doc = n
-		return d.unmarshal(n.children[0])
+		if n.value == "1.2" {
+			d.core = true
+		}
+		c := n.children[0]
+		if c.kind != scalarNode && c.kind != aliasNode {
+			if d.refs && c.kind == mappingNode {
+				d.initAnchors(c)
+			}
+			expr := d.unmarshal(c)
+			if s, ok := expr.(*ast.StructLit); ok && len(d.defs) > 0 {
+				s.Elts = append(d.defs, s.Elts...)
+			}
+			return expr
+		}
+		groups, _ := d.commentGroups(c.startPos)
+		expr := d.unmarshal(c)
+		for _, g := range groups {
+			g.Doc = true
+			expr.AddComment(g)
+		}
+		d.attachLineComment(c.endPos, 10, expr)
+		for _, g := range d.footComments(c.endPos, 0) {
+			g.Position = 10
+			expr.AddComment(g)
+		}
+		return expr
 	}
 	return &ast.BottomLit{} // TODO: more informatives
 }
 
 func (d *decoder) alias(n *node) ast.Expr {
+	if _, ok := d.anchors[n.alias]; ok {
+		return d.reference(n.alias, d.pos(n.startPos))
+	}
 	if d.aliases[n] {
 		// TODO this could actually be allowed in some circumstances.
 		d.p.failf(n.startPos.line, "anchor '%s' value contains itself", n.value)
@@ -427,6 +581,82 @@ func (d *decoder) alias(n *node) ast.Expr {
 	return node
 }
 
+// initAnchors assigns a CUE alias name to each anchor in the document with
+// root mapping n that is referenced by a YAML alias. The names are chosen
+// not to clash with any of the keys in the document.
+func (d *decoder) initAnchors(n *node) {
+	used := map[string]bool{
+		"_": true, "true": true, "false": true, "null": true,
+		"if": true, "for": true, "in": true, "let": true,
+	}
+	var anchors []*node
+	seen := map[*node]bool{}
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch n.kind {
+		case aliasNode:
+			if !seen[n.alias] {
+				seen[n.alias] = true
+				anchors = append(anchors, n.alias)
+			}
+			return
+		case mappingNode:
+			for i := 0; i < len(n.children); i += 2 {
+				used[n.children[i].value] = true
+			}
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	d.anchors = map[*node]string{}
+	d.defined = map[*node]bool{}
+	for _, a := range anchors {
+		base := anchorName(a.anchor)
+		name := base
+		for i := 1; used[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[name] = true
+		d.anchors[a] = name
+	}
+}
+
+// anchorName converts a YAML anchor to a valid CUE identifier.
+func anchorName(anchor string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.In(r, unicode.L, unicode.N) || r == '_' {
+			return r
+		}
+		return '_'
+	}, anchor)
+	if name == "" || unicode.In(rune(name[0]), unicode.N) {
+		name = "anchor" + name
+	}
+	return name
+}
+
+// reference returns a reference at pos to the CUE alias for the anchored node
+// n, declaring the alias the first time it is referenced.
+func (d *decoder) reference(n *node, pos token.Pos) ast.Expr {
+	name := d.anchors[n]
+	if d.aliases[n] {
+		d.p.failf(n.startPos.line, "anchor '%s' value contains itself", n.anchor)
+	}
+	if !d.defined[n] {
+		d.defined[n] = true
+		d.aliases[n] = true
+		expr := d.unmarshal(n)
+		delete(d.aliases, n)
+		alias := &ast.Alias{Ident: ast.NewIdent(name), Expr: expr}
+		ast.SetRelPos(alias, token.Newline)
+		d.defs = append(d.defs, alias)
+	}
+	return &ast.Ident{NamePos: pos, Name: name}
+}
+
 var zeroValue reflect.Value
 
 func (d *decoder) scalar(n *node) ast.Expr {
@@ -495,13 +725,19 @@ func (d *decoder) scalar(n *node) ast.Expr {
 		// Convert YAML octal to CUE octal. If YAML accepted an invalid
 		// integer, just convert it as well to ensure CUE will fail.
 		s := n.value
-		if len(s) > 1 && s[0] == '0' && s[1] <= '9' {
+		switch {
+		case d.core:
+			s = coreNumber(s)
+		case len(s) > 1 && s[0] == '0' && s[1] <= '9':
 			s = "0o" + s[1:]
 		}
 		return d.makeNum(n, s, token.INT)
 
 	case yaml_FLOAT_TAG:
 		value := n.value
+		if d.core {
+			value = coreNumber(value)
+		}
 		if f, ok := resolved.(float64); ok {
 			switch {
 			case math.IsInf(f, -1),
@@ -636,11 +872,48 @@ func (d *decoder) sequence(n *node) ast.Expr {
 
 	noNewline := true
 	single := d.isOneLiner(n.startPos, n.endPos)
+	// A comment after a single-line flow sequence follows its closing
+	// bracket and is left to the enclosing node. A comment after a
+	// single-line block sequence belongs to its element, which then needs a
+	// line of its own.
+	inline := single && n.flow
+	if single && !n.flow && d.hasLineComment(n.endPos) {
+		single = false
+	}
+	commented := false
 	for _, c := range n.children {
 		d.forceNewline = !single
+		groups, _ := d.commentGroups(c.startPos)
 		elem := d.unmarshal(c)
+		// Comments on a struct element are attached to its first field to
+		// avoid breaking up the list.
+		var doc ast.Node = elem
+		s, isStruct := elem.(*ast.StructLit)
+		if isStruct && len(s.Elts) > 0 {
+			doc = s.Elts[0]
+		}
+		for i := len(groups) - 1; i >= 0; i-- {
+			groups[i].Doc = true
+			prependComment(doc, groups[i])
+		}
+		// A struct element following a line comment starts on a new line.
+		if isStruct && commented {
+			s.Lbrace = s.Lbrace.WithRel(token.Newline)
+		}
+		commented = !inline && d.hasLineComment(c.endPos)
+		if commented {
+			d.attachLineComment(c.endPos, 10, elem)
+		}
 		list.Elts = append(list.Elts, elem)
-		_, noNewline = elem.(*ast.StructLit)
+		noNewline = isStruct && !commented
+	}
+	if ln := len(list.Elts); ln > 0 {
+		last := list.Elts[ln-1]
+		for _, g := range d.footComments(n.endPos, n.startPos.column) {
+			g.Position = 10
+			last.AddComment(g)
+			noNewline = false
+		}
 	}
 	if !single && !noNewline {
 		list.Rbrack = list.Rbrack.WithRel(token.Newline)
@@ -659,12 +932,30 @@ func (d *decoder) mapping(n *node) ast.Expr {
 
 	structure := &ast.StructLit{}
 	d.insertMap(n, structure, false)
+	var foot []*ast.CommentGroup
+	if ln := len(structure.Elts); ln > 0 {
+		last := structure.Elts[ln-1]
+		foot = d.footComments(n.endPos, n.startPos.column)
+		for _, g := range foot {
+			// Comments directly following the last field trail this field.
+			// Comments separated by a blank line stand on their own.
+			if _, ok := last.(*ast.Field); ok && g.Pos().RelPos() < token.NewSection {
+				g.Position = 5
+				last.AddComment(g)
+				continue
+			}
+			structure.Elts = append(structure.Elts, g)
+		}
+	}
 
 	// NOTE: we currently translate YAML without curly braces to CUE with
 	// curly braces, even for single elements. Removing the following line
 	// would generate the folded form.
 	structure.Lbrace = d.absPos(n.startPos).WithRel(token.NoSpace)
 	structure.Rbrace = d.absPos(n.endPos).WithRel(token.Newline)
+	if len(foot) > 0 {
+		structure.Rbrace = foot[len(foot)-1].End().WithRel(token.Newline)
+	}
 	if d.isOneLiner(n.startPos, n.endPos) && !newline {
 		if len(structure.Elts) != 1 {
 			structure.Lbrace = d.absPos(n.startPos).WithRel(token.Blank)
@@ -678,10 +969,20 @@ func (d *decoder) mapping(n *node) ast.Expr {
 
 func (d *decoder) insertMap(n *node, m *ast.StructLit, merge bool) {
 	l := len(n.children)
+	// A comment after a single-line flow mapping follows its closing brace
+	// and is left to the enclosing node.
+	inline := n.flow && d.isOneLiner(n.startPos, n.endPos)
 outer:
 	for i := 0; i < l; i += 2 {
 		if isMerge(n.children[i]) {
 			merge = true
+			if anchors := d.embeddable(n, n.children[i+1]); anchors != nil {
+				for _, a := range anchors {
+					pos := d.pos(n.children[i].startPos).WithRel(token.Newline)
+					m.Elts = append(m.Elts, &ast.EmbedDecl{Expr: d.reference(a, pos)})
+				}
+				continue
+			}
 			d.merge(n.children[i+1], m)
 			continue
 		}
@@ -693,16 +994,31 @@ outer:
 		}
 
 		field := &ast.Field{}
-		d.attachDocComments(n.children[i].startPos, 0, field)
+		for _, g := range d.attachDocComments(n.children[i].startPos, field, true) {
+			m.Elts = append(m.Elts, g)
+		}
 
 		label := d.label(n.children[i])
 		field.Label = label
-		d.attachLineComment(n.children[i].endPos, 1, label)
+
+		// A comment after the key of a value that starts on a later line,
+		// such as a block mapping or block scalar, documents the field.
+		if n.children[i+1].endPos.line > n.children[i].endPos.line {
+			if g := d.lineComment(n.children[i].endPos); g != nil {
+				g.Doc = true
+				c := g.List[0]
+				c.Slash = c.Slash.WithRel(token.Newline)
+				field.AddComment(g)
+			}
+		}
 
 		if merge {
 			key := labelStr(label)
 			for _, decl := range m.Elts {
-				f := decl.(*ast.Field)
+				f, ok := decl.(*ast.Field)
+				if !ok {
+					continue
+				}
 				name, _, err := ast.LabelName(f.Label)
 				if err == nil && name == key {
 					f.Value = d.unmarshal(n.children[i+1])
@@ -713,8 +1029,16 @@ outer:
 
 		value := d.unmarshal(n.children[i+1])
 		field.Value = value
-		d.attachDocComments(n.children[i+1].startPos, 0, value)
-		d.attachLineComment(n.children[i+1].endPos, 10, value)
+		d.attachDocComments(n.children[i+1].startPos, value, false)
+		if !inline {
+			// A comment after a struct is printed after its closing brace
+			// only if it is attached to the field.
+			if _, ok := value.(*ast.StructLit); ok {
+				d.attachLineComment(n.children[i+1].endPos, 5, field)
+			} else {
+				d.attachLineComment(n.children[i+1].endPos, 10, value)
+			}
+		}
 
 		m.Elts = append(m.Elts, field)
 	}
@@ -766,6 +1090,88 @@ func (d *decoder) merge(n *node, m *ast.StructLit) {
 	}
 }
 
+// embeddable returns the anchors merged into mapping n by merge value v if
+// they can be embedded as references instead of being expanded. As embedding
+// unifies values instead of overriding them, this is only the case if none of
+// the merged mappings and explicit keys of n have keys in common.
+func (d *decoder) embeddable(n, v *node) []*node {
+	if d.anchors == nil {
+		return nil
+	}
+	var anchors []*node
+	switch v.kind {
+	case aliasNode:
+		anchors = append(anchors, v.alias)
+	case sequenceNode:
+		for _, c := range v.children {
+			if c.kind != aliasNode {
+				return nil
+			}
+			anchors = append(anchors, c.alias)
+		}
+	default:
+		return nil
+	}
+	keys := map[string]bool{}
+	for i := 0; i < len(n.children); i += 2 {
+		if k := n.children[i]; !isMerge(k) {
+			if k.kind != scalarNode {
+				return nil
+			}
+			keys[k.value] = true
+		}
+	}
+	for _, a := range anchors {
+		if a.kind != mappingNode {
+			return nil
+		}
+		merged := map[string]bool{}
+		if !mergedKeys(a, merged, map[*node]bool{}) {
+			return nil
+		}
+		for k := range merged {
+			if keys[k] {
+				return nil
+			}
+			keys[k] = true
+		}
+	}
+	return anchors
+}
+
+// mergedKeys adds the keys of mapping n, including those of merged mappings,
+// to keys. It reports false if the keys cannot be determined.
+func mergedKeys(n *node, keys map[string]bool, visited map[*node]bool) bool {
+	if n.kind == aliasNode {
+		n = n.alias
+	}
+	if n.kind != mappingNode || visited[n] {
+		return false
+	}
+	visited[n] = true
+	defer delete(visited, n)
+	for i := 0; i < len(n.children); i += 2 {
+		k, v := n.children[i], n.children[i+1]
+		switch {
+		case isMerge(k):
+			if v.kind == sequenceNode {
+				for _, c := range v.children {
+					if !mergedKeys(c, keys, visited) {
+						return false
+					}
+				}
+			} else if !mergedKeys(v, keys, visited) {
+				return false
+			}
+		case k.kind != scalarNode:
+			return false
+		default:
+			keys[k.value] = true
+		}
+	}
+	return true
+}
+
 func isMerge(n *node) bool {
 	return n.kind == scalarNode && n.value == "<<" && (n.implicit == true || n.tag == yaml_MERGE_TAG)
 }
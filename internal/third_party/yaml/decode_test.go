@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/third_party/yaml"
@@ -197,7 +198,8 @@ apple: "newline"`,
 	// Literal block scalar
 	{
 		"scalar: | # Comment\n\n literal\n\n \ttext\n\n",
-		`scalar: """
+		`// Comment
+scalar: """
 
 		literal
 
@@ -209,7 +211,8 @@ apple: "newline"`,
 	// Folded block scalar
 	{
 		"scalar: > # Comment\n\n folded\n line\n \n next\n line\n  * one\n  * two\n\n last\n line\n\n",
-		`scalar: """
+		`// Comment
+scalar: """
 
 		folded line
 		next line
@@ -791,6 +794,153 @@ func TestDecoder(t *testing.T) {
 	}
 }
 
+var referenceTests = []struct {
+	data string
+	want string
+}{{
+	// Unreferenced anchors are left as is.
+	"a: &x 1\nb: 2",
+	"a: 1\nb: 2",
+}, {
+	"a: &x 1\nb: *x\nc: [*x]",
+	"x = 1\na: x\nb: x\nc: [x]",
+}, {
+	"a: &x {v: 1}\nb:\n  c: *x\n  x: 2",
+	"x_1 = {v: 1}\na: x_1\nb: {\n\tc: x_1\n\tx: 2\n}",
+}, {
+	"a: &1-b 1\nb: *1-b",
+	"anchor1_b = 1\na: anchor1_b\nb: anchor1_b",
+}, {
+	"a: &x {v: 1}\nb: &x {v: 2}\nc: *x",
+	"x = {v: 2}\na: {v: 1}\nb: x\nc: x",
+}, {
+	"a: &x\n  b: &y 1\nc: *x\nd: *y",
+	"y = 1\nx = {\n\tb: y\n}\na: x\nc: x\nd: y",
+}, {
+	"base: &b\n  x: 1\nmore: &m\n  w: 2\nc:\n  <<: [*b, *m]\n  z: 3",
+	"b = {\n\tx: 1\n}\nm = {\n\tw: 2\n}\nbase: b\nmore: m\nc: {\n\tb\n\tm\n\tz: 3\n}",
+}, {
+	// Overridden keys are expanded.
+	"base: &b\n  x: 1\nc:\n  <<: *b\n  x: 2",
+	"b = {\n\tx: 1\n}\nbase: b\nc: {\n\tx: 2\n}",
+}, {
+	// References are only used if the root is a mapping.
+	"[&x 1, *x]",
+	"[1, 1]",
+}}
+
+func TestDecoderReferences(t *testing.T) {
+	for i, item := range referenceTests {
+		t.Run(fmt.Sprintf("test %d: %q", i, item.data), func(t *testing.T) {
+			dec := newDecoder(t, item.data)
+			dec.UseReferences()
+			expr, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := cueStr(expr)
+			if got != item.want {
+				t.Errorf("\n got: %v;\nwant: %v", got, item.want)
+			}
+
+			// The result must evaluate to the expanded form.
+			expr, err = newDecoder(t, item.data).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var r cue.Runtime
+			var json [2][]byte
+			for i, src := range []string{got, cueStr(expr)} {
+				inst, err := r.Compile("test", src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if json[i], err = inst.Value().MarshalJSON(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if string(json[0]) != string(json[1]) {
+				t.Errorf("got %s; want %s", json[0], json[1])
+			}
+		})
+	}
+}
+
+func TestDecoderReferencesCycle(t *testing.T) {
+	dec := newDecoder(t, "a: &x\n  b: *x\n")
+	dec.UseReferences()
+	_, err := dec.Decode()
+	const want = "test.yaml:1: anchor 'x' value contains itself"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %s", err, want)
+	}
+}
+
+var coreSchemaTests = []struct {
+	data string
+	want string
+}{{
+	"a: NO\nb: on\nc: yes\nd: y",
+	"a: \"NO\"\nb: \"on\"\nc: \"yes\"\nd: \"y\"",
+}, {
+	"a: true\nb: False\nc: ~\nd: Null",
+	"a: true\nb: false\nc: null\nd: null",
+}, {
+	"a: 0755\nb: 0o17\nc: 0x1F\nd: +12\ne: -007",
+	"a: 755\nb: 0o17\nc: 0x1F\nd: 12\ne: -7",
+}, {
+	"a: 1_000\nb: 0b101\nc: 12:30\nd: 2001-12-14",
+	"a: \"1_000\"\nb: \"0b101\"\nc: \"12:30\"\nd: \"2001-12-14\"",
+}, {
+	"a: .5\nb: 1.\nc: +1e3\nd: -01.5e-3",
+	"a: 0.5\nb: 1.0\nc: 1e3\nd: -1.5e-3",
+}, {
+	"on: 1\nno: 2",
+	"on: 1\nno: 2",
+}}
+
+func TestDecoderCoreSchema(t *testing.T) {
+	for i, item := range coreSchemaTests {
+		t.Run(fmt.Sprintf("test %d: %q", i, item.data), func(t *testing.T) {
+			dec := newDecoder(t, item.data)
+			dec.UseCoreSchema()
+			expr, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cueStr(expr); got != item.want {
+				t.Errorf("\n got: %v;\nwant: %v", got, item.want)
+			}
+		})
+	}
+}
+
+func TestVersionDirective(t *testing.T) {
+	expr, err := newDecoder(t, "%YAML 1.2\n---\na: NO").Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cueStr(expr), `a: "NO"`; got != want {
+		t.Errorf("\n got: %v;\nwant: %v", got, want)
+	}
+}
+
+func TestIsPlainString(t *testing.T) {
+	for _, s := range []string{"foo", "NOPE", "1.2.3", "x-y", "0o", "true1"} {
+		if !yaml.IsPlainString(s) {
+			t.Errorf("IsPlainString(%q) = false; want true", s)
+		}
+	}
+	for _, s := range []string{
+		"", "NO", "on", "y", "~", "null", "true", "12", "0o17", "0x1F",
+		"1_000", "0b1", ".5", "1e3", ".inf", "12:30", "2001-12-14", "<<", "=",
+	} {
+		if yaml.IsPlainString(s) {
+			t.Errorf("IsPlainString(%q) = true; want false", s)
+		}
+	}
+}
+
 type errReader struct{}
 
 func (errReader) Read([]byte) (int, error) {
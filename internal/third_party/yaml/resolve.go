@@ -111,6 +111,10 @@ func (d *decoder) resolve(n *node) (rtag string, out interface{}) {
 		d.p.failf(n.startPos.line, "cannot decode %s `%s` as a %s", shortTag(rtag), in, shortTag(tag))
 	}()
 
+	if d.core && tag != yaml_STR_TAG && tag != yaml_BINARY_TAG {
+		return resolveCore(tag, in)
+	}
+
 	// Any data is accepted as a !!str or !!binary.
 	// Otherwise, the prefix is enough of a hint about what it might be.
 	hint := byte('N')
@@ -198,6 +202,111 @@ func (d *decoder) resolve(n *node) (rtag string, out interface{}) {
 	return yaml_STR_TAG, in
 }
 
+var (
+	coreInt   = regexp.MustCompile(`^([-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+)$`)
+	coreFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolveCore resolves a scalar according to the YAML 1.2 core schema. Unlike
+// YAML 1.1, it only recognizes true and false as booleans and does not accept
+// underscores, binary and sexagesimal numbers, or implicit timestamps.
+func resolveCore(tag, in string) (rtag string, out interface{}) {
+	switch in {
+	case "", "~", "null", "Null", "NULL":
+		return yaml_NULL_TAG, nil
+	case "true", "True", "TRUE":
+		return yaml_BOOL_TAG, true
+	case "false", "False", "FALSE":
+		return yaml_BOOL_TAG, false
+	case ".nan", ".NaN", ".NAN":
+		return yaml_FLOAT_TAG, math.NaN()
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return yaml_FLOAT_TAG, math.Inf(+1)
+	case "-.inf", "-.Inf", "-.INF":
+		return yaml_FLOAT_TAG, math.Inf(-1)
+	case "<<":
+		return yaml_MERGE_TAG, "<<"
+	}
+	if tag == yaml_TIMESTAMP_TAG {
+		if t, ok := parseTimestamp(in); ok {
+			return yaml_TIMESTAMP_TAG, t
+		}
+	}
+	switch {
+	case coreInt.MatchString(in):
+		num := coreNumber(in)
+		if intv, err := strconv.ParseInt(num, 0, 64); err == nil {
+			if intv == int64(int(intv)) {
+				return yaml_INT_TAG, int(intv)
+			}
+			return yaml_INT_TAG, intv
+		}
+		if uintv, err := strconv.ParseUint(num, 0, 64); err == nil {
+			return yaml_INT_TAG, uintv
+		}
+		// Integers of arbitrary size are preserved as is.
+		return yaml_INT_TAG, num
+	case coreFloat.MatchString(in):
+		floatv, err := strconv.ParseFloat(in, 64)
+		if err == nil {
+			return yaml_FLOAT_TAG, floatv
+		}
+		return yaml_FLOAT_TAG, coreNumber(in)
+	}
+	return yaml_STR_TAG, in
+}
+
+// coreNumber converts a YAML 1.2 core schema number to a CUE number literal.
+// It drops a leading plus sign and leading zeros, which denote octal numbers
+// in CUE, and adds the digits CUE requires around a decimal point.
+func coreNumber(s string) string {
+	sign := ""
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "-"):
+		sign, s = "-", s[1:]
+	}
+	if strings.HasPrefix(s, "0o") || strings.HasPrefix(s, "0x") {
+		return sign + s
+	}
+	i := 0
+	for i < len(s)-1 && s[i] == '0' && s[i+1] >= '0' && s[i+1] <= '9' {
+		i++
+	}
+	s = s[i:]
+	if strings.HasPrefix(s, ".") {
+		s = "0" + s
+	}
+	if p := strings.IndexByte(s, '.'); p >= 0 {
+		if p+1 == len(s) || s[p+1] < '0' || s[p+1] > '9' {
+			s = s[:p+1] + "0" + s[p+1:]
+		}
+	}
+	return sign + s
+}
+
+// IsPlainString reports whether s is decoded as a string if it appears
+// unquoted in YAML. This is the case if it decodes as a string under both
+// YAML 1.1 and the YAML 1.2 core schema. Sexagesimal numbers and the value
+// key of YAML 1.1 are considered ambiguous as well, even though this package
+// does not decode them as such.
+func IsPlainString(s string) bool {
+	if sexagesimal.MatchString(s) || s == "=" {
+		return false
+	}
+	n := &node{kind: scalarNode, value: s, implicit: true}
+	for _, core := range []bool{false, true} {
+		d := &decoder{core: core}
+		if tag, _ := d.resolve(n); tag != yaml_STR_TAG {
+			return false
+		}
+	}
+	return true
+}
+
+var sexagesimal = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+
 // encodeBase64 encodes s as base64 that is broken up into multiple lines
 // as appropriate for the resulting length.
 func encodeBase64(s string) string {
@@ -87,6 +87,8 @@ func Unmarshal(filename string, in []byte) (expr ast.Expr, err error) {
 // A Decorder reads and decodes YAML values from an input stream.
 type Decoder struct {
 	strict bool
+	refs   bool
+	core   bool
 	parser *parser
 }
 
@@ -102,6 +104,23 @@ func NewDecoder(filename string, src interface{}) (*Decoder, error) {
 	return &Decoder{parser: d}, nil
 }
 
+// UseReferences causes the decoder to represent anchors that are referenced
+// by aliases as CUE aliases and the aliases as references to them, instead of
+// expanding them in place. Merge keys are represented as embedded references
+// if this does not change the result. This only applies to documents of which
+// the root is a mapping.
+func (dec *Decoder) UseReferences() {
+	dec.refs = true
+}
+
+// UseCoreSchema causes the decoder to resolve unquoted scalars according to
+// the YAML 1.2 core schema instead of YAML 1.1. For instance, the values yes,
+// no, on and off are then decoded as strings instead of booleans. Documents
+// that start with a %YAML 1.2 directive always use the core schema.
+func (dec *Decoder) UseCoreSchema() {
+	dec.core = true
+}
+
 // Decode reads the next YAML-encoded value from its input and stores it in the
 // value pointed to by v. It returns io.EOF if there are no more value in the
 // stream.
@@ -110,6 +129,8 @@ func NewDecoder(filename string, src interface{}) (*Decoder, error) {
 // into a Go value.
 func (dec *Decoder) Decode() (expr ast.Expr, err error) {
 	d := newDecoder(dec.parser)
+	d.refs = dec.refs
+	d.core = dec.core
 	defer handleErr(&err)
 	node := dec.parser.parse()
 	if node == nil {
@@ -0,0 +1,79 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "unused-alias",
+		Doc:  "reports aliases that are not used",
+		Run:  unusedAliases,
+	})
+}
+
+func unusedAliases(p *Pass) {
+	for _, f := range p.Files {
+		used := map[ast.Node]bool{}
+		astutil.WalkRefs(f, func(x *ast.Ident) {
+			if x.Node != nil {
+				used[x.Node] = true
+			}
+		})
+		declAliases(f, func(a *ast.Alias, field *ast.Field) {
+			// References to a label alias resolve to its field.
+			var n ast.Node = a
+			if field != nil {
+				n = field
+			}
+			if used[n] {
+				return
+			}
+			p.Report(Diagnostic{
+				Pos:     a.Pos(),
+				Message: "alias " + a.Ident.Name + " is not used",
+				Fix: &Fix{
+					Message: "remove alias",
+					File:    f,
+					Apply:   removeAlias(a, field),
+				},
+			})
+		})
+	}
+}
+
+// removeAlias returns a function for astutil.Apply that removes the alias
+// declaration a or, if field is not nil, removes alias a from the label of
+// field.
+func removeAlias(a *ast.Alias, field *ast.Field) func(c astutil.Cursor) bool {
+	return func(c astutil.Cursor) bool {
+		switch n := c.Node(); {
+		case field == nil && n == a:
+			c.Delete()
+			return false
+
+		case field != nil && n == field:
+			if label, ok := a.Expr.(ast.Label); ok {
+				astutil.CopyPosition(label, a)
+				field.Label = label
+			}
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "bottom",
+		Doc:  "reports fields, including definitions, that always evaluate to an error",
+		Run:  bottomFields,
+	})
+}
+
+func bottomFields(p *Pass) {
+	if p.Instance == nil {
+		if len(p.Files) > 0 {
+			p.Reportf(p.Files[0].Pos(), "rule skipped: package could not be built")
+		}
+		return
+	}
+	err := p.Instance.Value().Validate(cue.Definitions(true))
+	for _, e := range errors.Errors(err) {
+		pos := e.Position()
+		if !pos.IsValid() {
+			for _, q := range errors.Positions(e) {
+				if q.IsValid() {
+					pos = q
+					break
+				}
+			}
+		}
+		if pos == token.NoPos {
+			continue
+		}
+		format, args := e.Msg()
+		msg := fmt.Sprintf(format, args...)
+		if path := strings.Join(e.Path(), "."); path != "" {
+			p.Reportf(pos, "field %s is always bottom: %s", path, msg)
+		} else {
+			p.Reportf(pos, "value is always bottom: %s", msg)
+		}
+	}
+}
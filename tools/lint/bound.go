@@ -0,0 +1,211 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"github.com/cockroachdb/apd/v2"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "redundant-constraint",
+		Doc:  "reports constraints that are implied by another constraint of the same conjunction",
+		Run:  redundantConstraints,
+	})
+}
+
+// redundantConstraints reports duplicate operands of conjunctions and bounds
+// that are implied by other bounds of the same conjunction, such as >=0 in
+// int & >=0 & >=1.
+func redundantConstraints(p *Pass) {
+	for _, f := range p.Files {
+		var check func(n ast.Node) bool
+		check = func(n ast.Node) bool {
+			x, ok := n.(*ast.BinaryExpr)
+			if !ok || x.Op != token.AND {
+				return true
+			}
+			terms := conjuncts(nil, x)
+			checkConjuncts(p, f, terms)
+			for _, t := range terms {
+				ast.Walk(t, check, nil)
+			}
+			return false
+		}
+		ast.Walk(f, check, nil)
+	}
+}
+
+// conjuncts appends the operands of the conjunction x to a.
+func conjuncts(a []ast.Expr, x ast.Expr) []ast.Expr {
+	if b, ok := x.(*ast.BinaryExpr); ok && b.Op == token.AND {
+		a = conjuncts(a, b.X)
+		return conjuncts(a, b.Y)
+	}
+	return append(a, x)
+}
+
+type bound struct {
+	expr   ast.Expr
+	lower  bool
+	strict bool
+	value  apd.Decimal
+}
+
+// stronger reports whether b excludes values accepted by c, which must
+// be a bound in the same direction.
+func (b *bound) stronger(c *bound) bool {
+	cmp := b.value.Cmp(&c.value)
+	if !b.lower {
+		cmp = -cmp
+	}
+	return cmp > 0 || cmp == 0 && b.strict && !c.strict
+}
+
+func checkConjuncts(p *Pass, f *ast.File, terms []ast.Expr) {
+	redundant := map[ast.Expr]ast.Expr{}
+
+	// Duplicate references.
+	for i, t := range terms {
+		x, ok := t.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		for _, u := range terms[:i] {
+			if y, ok := u.(*ast.Ident); ok && x.Name == y.Name && x.Node == y.Node {
+				redundant[t] = u
+				break
+			}
+		}
+	}
+
+	// Bounds implied by stronger bounds.
+	var strongest [2]*bound
+	var bounds []*bound
+	for _, t := range terms {
+		b := parseBound(t)
+		if b == nil {
+			continue
+		}
+		bounds = append(bounds, b)
+		i := 0
+		if b.lower {
+			i = 1
+		}
+		if strongest[i] == nil || b.stronger(strongest[i]) {
+			strongest[i] = b
+		}
+	}
+	for _, b := range bounds {
+		i := 0
+		if b.lower {
+			i = 1
+		}
+		if s := strongest[i]; s != b {
+			redundant[b.expr] = s.expr
+		}
+	}
+
+	for _, t := range terms {
+		by, ok := redundant[t]
+		if !ok {
+			continue
+		}
+		p.Report(Diagnostic{
+			Pos:     t.Pos(),
+			Message: "redundant constraint " + str(t) + ": implied by " + str(by),
+			Fix: &Fix{
+				Message: "remove constraint",
+				File:    f,
+				Apply:   removeConjunct(t),
+			},
+		})
+	}
+}
+
+// parseBound returns the bound represented by x or nil if x is not a bound
+// with a numeric literal.
+func parseBound(x ast.Expr) *bound {
+	u, ok := x.(*ast.UnaryExpr)
+	if !ok {
+		return nil
+	}
+	b := &bound{expr: x}
+	switch u.Op {
+	case token.GEQ:
+		b.lower = true
+	case token.GTR:
+		b.lower, b.strict = true, true
+	case token.LEQ:
+	case token.LSS:
+		b.strict = true
+	default:
+		return nil
+	}
+	neg := false
+	lit, ok := u.X.(*ast.BasicLit)
+	if n, isNeg := u.X.(*ast.UnaryExpr); isNeg && n.Op == token.SUB {
+		neg = true
+		lit, ok = n.X.(*ast.BasicLit)
+	}
+	if !ok || (lit.Kind != token.INT && lit.Kind != token.FLOAT) {
+		return nil
+	}
+	var info literal.NumInfo
+	if err := literal.ParseNum(lit.Value, &info); err != nil {
+		return nil
+	}
+	if err := info.Decimal(&b.value); err != nil {
+		return nil
+	}
+	if neg {
+		b.value.Neg(&b.value)
+	}
+	return b
+}
+
+// removeConjunct returns a function for astutil.Apply that removes the
+// operand x from the conjunction that contains it.
+func removeConjunct(x ast.Expr) func(c astutil.Cursor) bool {
+	return func(c astutil.Cursor) bool {
+		b, ok := c.Node().(*ast.BinaryExpr)
+		if !ok || b.Op != token.AND {
+			return true
+		}
+		switch x {
+		case b.X:
+			c.Replace(b.Y)
+			return false
+		case b.Y:
+			c.Replace(b.X)
+			return false
+		}
+		return true
+	}
+}
+
+func str(x ast.Expr) string {
+	b, err := format.Node(x)
+	if err != nil {
+		return "expression"
+	}
+	return string(b)
+}
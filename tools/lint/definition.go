@@ -0,0 +1,81 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/token"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "unused-definition",
+		Doc:  "reports unexported top-level definitions that are not used",
+		Run:  unusedDefinitions,
+	})
+}
+
+// unusedDefinitions reports top-level definitions that are not visible
+// outside the package and are not referenced from within the package.
+func unusedDefinitions(p *Pass) {
+	type def struct {
+		name  string
+		field *ast.Field
+	}
+	var defs []def
+	byValue := map[ast.Node]string{}
+	for _, f := range p.Files {
+		for _, d := range f.Decls {
+			x, ok := d.(*ast.Field)
+			if !ok || x.Token != token.ISA || x.Value == nil {
+				continue
+			}
+			name, isIdent, _ := ast.LabelName(x.Label)
+			if !isIdent || isExported(name) {
+				continue
+			}
+			defs = append(defs, def{name, x})
+			byValue[x.Value] = name
+		}
+	}
+	if len(defs) == 0 {
+		return
+	}
+
+	used := map[string]bool{}
+	for _, f := range p.Files {
+		astutil.WalkRefs(f, func(x *ast.Ident) {
+			if name, ok := byValue[x.Node]; ok {
+				used[name] = true
+			}
+		})
+	}
+	for _, d := range defs {
+		if !used[d.name] {
+			p.Reportf(d.field.Pos(), "definition %s is not used", d.name)
+		}
+	}
+}
+
+// isExported reports whether a definition with the given name is visible
+// outside its package.
+func isExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "unused-import",
+		Doc:  "reports imports that are not used",
+		Run:  unusedImports,
+	})
+}
+
+func unusedImports(p *Pass) {
+	for _, f := range p.Files {
+		used := map[*ast.ImportSpec]bool{}
+		unresolved := map[string]bool{}
+		astutil.WalkRefs(f, func(x *ast.Ident) {
+			switch n := x.Node.(type) {
+			case *ast.ImportSpec:
+				used[n] = true
+			case nil:
+				unresolved[x.Name] = true
+			}
+		})
+		for _, spec := range f.Imports {
+			if used[spec] || unresolved[astutil.ImportName(spec)] {
+				continue
+			}
+			p.Report(Diagnostic{
+				Pos:     spec.Pos(),
+				Message: "imported and not used: " + spec.Path.Value,
+				Fix: &Fix{
+					Message: "remove import",
+					File:    f,
+					Apply:   removeImport(f, spec),
+				},
+			})
+		}
+	}
+}
+
+// removeImport returns a function for astutil.Apply that removes spec from f.
+// The import declaration is removed altogether if it becomes empty.
+func removeImport(f *ast.File, spec *ast.ImportSpec) func(c astutil.Cursor) bool {
+	return func(c astutil.Cursor) bool {
+		switch x := c.Node().(type) {
+		case *ast.File:
+			return true
+		case *ast.ImportDecl:
+			k := 0
+			for _, s := range x.Specs {
+				if s != spec {
+					x.Specs[k] = s
+					k++
+				}
+			}
+			x.Specs = x.Specs[:k]
+			if k == 0 {
+				c.Delete()
+			}
+			for i, s := range f.Imports {
+				if s == spec {
+					f.Imports = append(f.Imports[:i], f.Imports[i+1:]...)
+					break
+				}
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint reports suspicious constructs in CUE files.
+//
+// Checks are implemented as rules, which are kept in a registry. Each rule
+// inspects the syntax of the files of an instance and, optionally, their
+// evaluated value. Rules may offer a fix for the problems they report, which
+// can be applied with ApplyFixes.
+//
+// Rules can be enabled or disabled individually using a configuration file
+// of the form
+//
+//	rules: {
+//		shadow:              false
+//		"unused-definition": true
+//	}
+//
+// Rules that are not mentioned in the configuration are enabled.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Rule checks for a single kind of problem.
+type Rule struct {
+	// Name identifies the rule in configurations and diagnostics.
+	Name string
+
+	// Doc is a one-line description of what the rule reports.
+	Doc string
+
+	// Run reports problems found in the files of p.
+	Run func(p *Pass)
+}
+
+var registry = map[string]*Rule{}
+
+// Register adds a rule to the registry. It panics if a rule with the same
+// name was already registered.
+func Register(r *Rule) {
+	if _, ok := registry[r.Name]; ok {
+		panic(fmt.Sprintf("lint: rule %q registered twice", r.Name))
+	}
+	registry[r.Name] = r
+}
+
+// Rules returns all registered rules sorted by name.
+func Rules() []*Rule {
+	a := make([]*Rule, 0, len(registry))
+	for _, r := range registry {
+		a = append(a, r)
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].Name < a[j].Name })
+	return a
+}
+
+// A Pass holds the input of a rule and collects the problems it reports.
+type Pass struct {
+	// Files holds the files of the instance being checked.
+	Files []*ast.File
+
+	// Instance is the evaluated instance, or nil if the files could not be
+	// built. Rules that rely on evaluation should report that they were
+	// skipped if Instance is nil.
+	Instance *cue.Instance
+
+	rule  *Rule
+	diags []Diagnostic
+}
+
+// Reportf reports a problem at the given position.
+func (p *Pass) Reportf(pos token.Pos, format string, args ...interface{}) {
+	p.Report(Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// Report reports the given diagnostic.
+func (p *Pass) Report(d Diagnostic) {
+	d.Rule = p.rule.Name
+	p.diags = append(p.diags, d)
+}
+
+// A Diagnostic describes a problem found by a rule.
+type Diagnostic struct {
+	Rule    string
+	Pos     token.Pos
+	Message string
+
+	// Fix, if not nil, removes the problem.
+	Fix *Fix
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%v: %s (%s)", d.Pos, d.Message, d.Rule)
+}
+
+// A Fix describes a change to a file that removes a problem.
+type Fix struct {
+	// Message describes the change.
+	Message string
+
+	// File is the file to be changed.
+	File *ast.File
+
+	// Apply is passed as the before function to astutil.Apply to change File.
+	Apply func(c astutil.Cursor) bool
+}
+
+// Config configures which rules are run.
+type Config struct {
+	// Rules reports for each rule name whether the rule is enabled. Rules that
+	// are not in the map are enabled.
+	Rules map[string]bool
+}
+
+func (c *Config) enabled(r *Rule) bool {
+	if c == nil {
+		return true
+	}
+	enabled, ok := c.Rules[r.Name]
+	return !ok || enabled
+}
+
+// ParseConfig parses a configuration file. The src argument is interpreted
+// as for parser.ParseFile. It is an error to configure a rule that does not
+// exist.
+func ParseConfig(filename string, src interface{}) (*Config, error) {
+	var r cue.Runtime
+	inst, err := r.Compile(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{Rules: map[string]bool{}}
+	v := inst.Value()
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	iter, err := v.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		if iter.Label() != "rules" {
+			return nil, errors.Newf(iter.Value().Pos(),
+				"unknown lint configuration field %q", iter.Label())
+		}
+	}
+
+	rules, err := v.Lookup("rules").Fields()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range Rules() {
+		names = append(names, r.Name)
+	}
+	for rules.Next() {
+		name := rules.Label()
+		if _, ok := registry[name]; !ok {
+			msg := fmt.Sprintf("unknown lint rule %q", name)
+			if s := astutil.Suggest(name, names); len(s) > 0 {
+				msg += fmt.Sprintf("; did you mean %q?", s[0])
+			}
+			return nil, errors.Newf(rules.Value().Pos(), "%s", msg)
+		}
+		enabled, err := rules.Value().Bool()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Rules[name] = enabled
+	}
+	return cfg, nil
+}
+
+// Run runs the rules enabled in cfg on the given files, which must be the
+// files of a single instance, and returns the reported problems ordered by
+// position. The evaluated instance inst may be nil if the files could not be
+// built. A nil cfg enables all rules.
+func Run(files []*ast.File, inst *cue.Instance, cfg *Config) []Diagnostic {
+	var diags []Diagnostic
+	for _, r := range Rules() {
+		if !cfg.enabled(r) {
+			continue
+		}
+		p := &Pass{Files: files, Instance: inst, rule: r}
+		r.Run(p)
+		diags = append(diags, p.diags...)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Pos.Position(), diags[j].Pos.Position()
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return diags
+}
+
+// ApplyFixes applies the fixes of the given diagnostics, if any, and returns
+// the files that were changed in the order in which they were first changed.
+func ApplyFixes(diags []Diagnostic) []*ast.File {
+	var files []*ast.File
+	changed := map[*ast.File]bool{}
+	for _, d := range diags {
+		if d.Fix == nil {
+			continue
+		}
+		astutil.Apply(d.Fix.File, d.Fix.Apply, nil)
+		if !changed[d.Fix.File] {
+			changed[d.Fix.File] = true
+			files = append(files, d.Fix.File)
+		}
+	}
+	return files
+}
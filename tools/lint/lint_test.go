@@ -0,0 +1,267 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule string
+		in   string
+		out  string // diagnostics
+		fix  string // formatted file after fixes; empty if unchanged
+	}{{
+		name: "unused import",
+		rule: "unused-import",
+		in: `
+import (
+	"strings"
+	"list"
+	m "math"
+)
+
+a: strings.ToUpper("a")
+`,
+		out: `
+3:2: imported and not used: "list" (unused-import)
+4:2: imported and not used: "math" (unused-import)
+`,
+		fix: `import (
+	"strings"
+)
+
+a: strings.ToUpper("a")
+`,
+	}, {
+		name: "remove import declaration",
+		rule: "unused-import",
+		in: `
+import "strings"
+
+a: 1
+`,
+		out: `
+1:8: imported and not used: "strings" (unused-import)
+`,
+		fix: `a: 1
+`,
+	}, {
+		name: "unused alias",
+		rule: "unused-alias",
+		in: `
+package foo
+
+X = 3
+Y = 4
+B=b: {
+	C=c: 1
+	d: C + Y
+}
+`,
+		out: `
+3:1: alias X is not used (unused-alias)
+5:1: alias B is not used (unused-alias)
+`,
+		fix: `package foo
+
+Y = 4
+b: {
+	C=c: 1
+	d:   C + Y
+}
+`,
+	}, {
+		name: "unused definition",
+		rule: "unused-definition",
+		in: `
+foo :: int
+bar :: string
+_baz :: bool
+Qux :: bool
+
+a: foo
+`,
+		out: `
+2:1: definition bar is not used (unused-definition)
+3:1: definition _baz is not used (unused-definition)
+`,
+	}, {
+		name: "shadow",
+		rule: "shadow",
+		in: `
+x: 1
+a: {
+	X = 2
+	x: 3 // shadowing fields is common and not reported
+	b: [ x for x in [1, 2] ]
+}
+c: {
+	for k, v in a { "\(k)": v }
+}
+y: 1
+d: [Y=string]: {
+	y: Y
+	for y in [1] {}
+}
+`,
+		out: `
+5:13: declaration of x shadows declaration at line 4 (shadow)
+13:6: declaration of y shadows declaration at line 12 (shadow)
+`,
+	}, {
+		name: "redundant constraint",
+		rule: "redundant-constraint",
+		in: `
+a: int & >=0 & >=1
+b: >=0 & <10 & <=10 & <10
+c: string & string & =~"a"
+d: >1.5 & >=1.5 & <=-1
+`,
+		out: `
+1:10: redundant constraint >=0: implied by >=1 (redundant-constraint)
+2:16: redundant constraint <=10: implied by <10 (redundant-constraint)
+2:23: redundant constraint <10: implied by <10 (redundant-constraint)
+3:13: redundant constraint string: implied by string (redundant-constraint)
+4:11: redundant constraint >=1.5: implied by >1.5 (redundant-constraint)
+`,
+		fix: `a: int & >=1
+b: >=0 & <10
+c: string & =~"a"
+d: >1.5 & <=-1
+`,
+	}, {
+		name: "bottom",
+		rule: "bottom",
+		in: `
+a: int & string
+b: c: >=1 & <=0
+Def :: {
+	x: 1
+	x: 2
+}
+ok: 1
+`,
+		out: `
+1:4: field a is always bottom: conflicting values int and string (mismatched types int and string) (bottom)
+2:7: field b.c is always bottom: conflicting bounds >=1 and <=0 (bottom)
+4:5: field Def.x is always bottom: conflicting values 1 and 2 (bottom)
+`,
+	}, {
+		name: "bottom without instance",
+		rule: "bottom",
+		in: `
+import "list"
+
+a: int & string
+`,
+		out: `
+1:1: rule skipped: package could not be built (bottom)
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile("test", tc.in[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			var r cue.Runtime
+			inst, err := r.CompileFile(f)
+			if err != nil {
+				inst = nil
+			}
+
+			cfg := &Config{Rules: map[string]bool{}}
+			for _, r := range Rules() {
+				cfg.Rules[r.Name] = r.Name == tc.rule
+			}
+			diags := Run([]*ast.File{f}, inst, cfg)
+
+			b := &strings.Builder{}
+			for _, d := range diags {
+				pos := d.Pos.Position()
+				fmt.Fprintf(b, "\n%d:%d: %s (%s)", pos.Line, pos.Column, d.Message, d.Rule)
+			}
+			if got := b.String() + "\n"; got != tc.out {
+				t.Errorf("diagnostics:\ngot:  %s\nwant: %s", got, tc.out)
+			}
+
+			files := ApplyFixes(diags)
+			if tc.fix == "" {
+				if len(files) > 0 {
+					t.Errorf("unexpected fixes")
+				}
+				return
+			}
+			out, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(out); got != tc.fix {
+				t.Errorf("fixed file:\ngot:\n%s\nwant:\n%s", got, tc.fix)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want map[string]bool
+		err  string
+	}{{
+		in: `rules: { shadow: false, "unused-import": true }`,
+		want: map[string]bool{
+			"shadow":        false,
+			"unused-import": true,
+		},
+	}, {
+		in:  `rules: shadows: false`,
+		err: `unknown lint rule "shadows"; did you mean "shadow"?`,
+	}, {
+		in:  `rule: shadow: false`,
+		err: `unknown lint configuration field "rule"`,
+	}, {
+		in:  `rules: shadow: bool`,
+		err: `incomplete value`,
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			cfg, err := ParseConfig("lint.cue", tc.in)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v; want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg.Rules, tc.want) {
+				t.Errorf("got %v; want %v", cfg.Rules, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"cuelang.org/go/cue/ast"
+)
+
+func init() {
+	Register(&Rule{
+		Name: "shadow",
+		Doc:  "reports aliases and comprehension variables that shadow an identifier of an enclosing scope",
+		Run:  shadowed,
+	})
+}
+
+// shadowed reports aliases and comprehension variables that shadow an
+// identifier of an enclosing scope. Fields that shadow other fields are not
+// reported, as this is common and references to such fields are resolved
+// as expected.
+func shadowed(p *Pass) {
+	for _, f := range p.Files {
+		c := &shadowChecker{pass: p}
+		c.walk(nil, f)
+	}
+}
+
+type shadowChecker struct {
+	pass *Pass
+}
+
+type shadowScope struct {
+	outer *shadowScope
+	names map[string]ast.Node
+}
+
+func (s *shadowScope) lookup(name string) ast.Node {
+	for ; s != nil; s = s.outer {
+		if n, ok := s.names[name]; ok {
+			return n
+		}
+	}
+	return nil
+}
+
+func newShadowScope(outer *shadowScope, decls []ast.Decl) *shadowScope {
+	s := &shadowScope{outer: outer, names: map[string]ast.Node{}}
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Field:
+			label := x.Label
+			if a, ok := label.(*ast.Alias); ok {
+				s.names[a.Ident.Name] = a.Ident
+				label, _ = a.Expr.(ast.Label)
+			}
+			if name, isIdent, _ := ast.LabelName(label); isIdent {
+				if _, ok := s.names[name]; !ok {
+					s.names[name] = label
+				}
+			}
+		case *ast.Alias:
+			s.names[x.Ident.Name] = x.Ident
+		}
+	}
+	return s
+}
+
+// check reports x if it shadows an identifier declared in s or one of its
+// enclosing scopes.
+func (c *shadowChecker) check(s *shadowScope, x *ast.Ident) {
+	if x == nil || x.Name == "_" {
+		return
+	}
+	if n := s.lookup(x.Name); n != nil {
+		c.pass.Reportf(x.Pos(), "declaration of %s shadows declaration at %s",
+			x.Name, relPos(x.Pos(), n.Pos()))
+	}
+}
+
+func (c *shadowChecker) walk(s *shadowScope, n ast.Node) {
+	ast.Walk(n, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.File:
+			s := newShadowScope(s, x.Decls)
+			for _, d := range x.Decls {
+				c.walkDecl(s, d)
+			}
+			return false
+
+		case *ast.StructLit:
+			s := newShadowScope(s, x.Elts)
+			for _, d := range x.Elts {
+				c.walkDecl(s, d)
+			}
+			return false
+
+		case *ast.Comprehension:
+			s := c.walkClauses(s, x.Clauses)
+			c.walk(s, x.Value)
+			return false
+
+		case *ast.ListComprehension:
+			s := c.walkClauses(s, x.Clauses)
+			c.walk(s, x.Expr)
+			return false
+		}
+		return true
+	}, nil)
+}
+
+// walkDecl walks a declaration of the struct or file with scope s.
+func (c *shadowChecker) walkDecl(s *shadowScope, d ast.Decl) {
+	switch x := d.(type) {
+	case *ast.Field:
+		label := x.Label
+		if a, ok := label.(*ast.Alias); ok {
+			c.check(s.outer, a.Ident)
+			label, _ = a.Expr.(ast.Label)
+		}
+		if l, ok := label.(*ast.ListLit); ok && len(l.Elts) == 1 {
+			if a, ok := l.Elts[0].(*ast.Alias); ok {
+				c.check(s, a.Ident)
+				c.walk(s, a.Expr)
+				s = &shadowScope{outer: s, names: map[string]ast.Node{
+					a.Ident.Name: a.Ident,
+				}}
+			} else {
+				c.walk(s, l.Elts[0])
+			}
+		}
+		if x.Value != nil {
+			c.walk(s, x.Value)
+		}
+
+	case *ast.Alias:
+		c.check(s.outer, x.Ident)
+		c.walk(s, x.Expr)
+
+	case *ast.Package, *ast.ImportDecl:
+
+	default:
+		c.walk(s, d)
+	}
+}
+
+// walkClauses walks the given comprehension clauses and returns the scope
+// in which the comprehension value is evaluated.
+func (c *shadowChecker) walkClauses(s *shadowScope, clauses []ast.Clause) *shadowScope {
+	for _, cl := range clauses {
+		switch x := cl.(type) {
+		case *ast.ForClause:
+			c.walk(s, x.Source)
+			c.check(s, x.Key)
+			c.check(s, x.Value)
+			names := map[string]ast.Node{}
+			if x.Key != nil {
+				names[x.Key.Name] = x.Key
+			}
+			names[x.Value.Name] = x.Value
+			s = &shadowScope{outer: s, names: names}
+
+		default:
+			c.walk(s, cl)
+		}
+	}
+	return s
+}
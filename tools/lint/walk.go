@@ -0,0 +1,58 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// declAliases calls fn for each alias declared in f that can be referenced
+// from within its scope: alias declarations, for which field is nil, and
+// aliases of field labels.
+func declAliases(f *ast.File, fn func(a *ast.Alias, field *ast.Field)) {
+	decls := func(list []ast.Decl) {
+		for _, d := range list {
+			switch x := d.(type) {
+			case *ast.Alias:
+				fn(x, nil)
+			case *ast.Field:
+				if a, ok := x.Label.(*ast.Alias); ok {
+					fn(a, x)
+				}
+			}
+		}
+	}
+	decls(f.Decls)
+	ast.Walk(f, func(n ast.Node) bool {
+		if x, ok := n.(*ast.StructLit); ok {
+			decls(x.Elts)
+		}
+		return true
+	}, nil)
+}
+
+// relPos returns a short description of pos relative to the position of
+// a diagnostic at from.
+func relPos(from, pos token.Pos) string {
+	a, b := from.Position(), pos.Position()
+	if a.Filename == b.Filename {
+		return fmt.Sprintf("line %d", b.Line)
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(b.Filename), b.Line)
+}
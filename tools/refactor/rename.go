@@ -0,0 +1,301 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refactor implements automated refactorings of CUE packages.
+package refactor
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Rename renames the top-level identifier name of the package with the given
+// import path to newName. References to the identifier are updated within
+// that package and, for qualified references such as pkg.Name, within all
+// instances in insts that import it. Typically, insts holds all packages of
+// a module.
+//
+// Rename modifies the files in place and returns the files that were changed.
+// It does not change any file and returns an error if newName is already
+// declared in the package, if a reference would be shadowed by another
+// declaration of newName, or if the identifier is referenced from other
+// packages and would no longer be visible outside its package.
+func Rename(insts []*build.Instance, importPath, name, newName string) ([]*ast.File, error) {
+	if !ast.IsValidIdent(newName) {
+		return nil, errors.Newf(token.NoPos, "invalid identifier %q", newName)
+	}
+	if name == newName {
+		return nil, nil
+	}
+
+	var pkg *build.Instance
+	for _, inst := range insts {
+		if inst.ImportPath == importPath {
+			pkg = inst
+			break
+		}
+	}
+	if pkg == nil {
+		return nil, errors.Newf(token.NoPos, "package %q not found", importPath)
+	}
+
+	r := &renamer{
+		pkg:     pkg,
+		name:    name,
+		newName: newName,
+		values:  map[ast.Node]bool{},
+		changed: map[*ast.File]bool{},
+	}
+	if err := r.findDecls(); err != nil {
+		return nil, err
+	}
+	if err := r.findRefs(); err != nil {
+		return nil, err
+	}
+	imported := false
+	for _, inst := range insts {
+		if inst != pkg {
+			imported = r.findQualifiedRefs(inst) || imported
+		}
+	}
+	if imported && !visible(newName, r.isDef) {
+		return nil, errors.Newf(token.NoPos,
+			"cannot rename %s to %s: %s would not be visible outside package %s",
+			name, newName, newName, pkg.PkgName)
+	}
+
+	for _, x := range r.idents {
+		x.Name = newName
+	}
+	return r.files, nil
+}
+
+type renamer struct {
+	pkg     *build.Instance
+	name    string
+	newName string
+	isDef   bool
+
+	// values holds the values of the declarations of name, to which
+	// references resolve.
+	values map[ast.Node]bool
+
+	labels  []*ast.Ident // labels of the declarations of name
+	idents  []*ast.Ident // labels and references to be renamed
+	files   []*ast.File
+	changed map[*ast.File]bool
+}
+
+func (r *renamer) rename(f *ast.File, x *ast.Ident) {
+	r.idents = append(r.idents, x)
+	if !r.changed[f] {
+		r.changed[f] = true
+		r.files = append(r.files, f)
+	}
+}
+
+// findDecls finds the top-level declarations of name and verifies that
+// newName is not declared at the top level.
+func (r *renamer) findDecls() error {
+	for _, f := range r.pkg.Files {
+		for _, spec := range f.Imports {
+			if astutil.ImportName(spec) == r.newName {
+				return errors.Newf(spec.Pos(),
+					"cannot rename %s to %s: conflicts with imported package name",
+					r.name, r.newName)
+			}
+		}
+		for _, d := range f.Decls {
+			switch x := d.(type) {
+			case *ast.Field:
+				label := x.Label
+				if a, ok := label.(*ast.Alias); ok {
+					if a.Ident.Name == r.newName {
+						return r.conflict(a.Ident)
+					}
+					label, _ = a.Expr.(ast.Label)
+				}
+				ident, ok := label.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				switch ident.Name {
+				case r.newName:
+					return r.conflict(ident)
+				case r.name:
+					r.isDef = r.isDef || x.Token == token.ISA
+					r.values[x.Value] = true
+					r.labels = append(r.labels, ident)
+					r.rename(f, ident)
+				}
+
+			case *ast.Alias:
+				if x.Ident.Name == r.newName {
+					return r.conflict(x.Ident)
+				}
+			}
+		}
+	}
+	if len(r.labels) == 0 {
+		return errors.Newf(token.NoPos, "%s not declared in package %s",
+			r.name, r.pkg.ImportPath)
+	}
+	return nil
+}
+
+func (r *renamer) conflict(x *ast.Ident) error {
+	return errors.Newf(x.Pos(), "cannot rename %s to %s: %s already declared",
+		r.name, r.newName, r.newName)
+}
+
+// findRefs finds the references to name within the package itself. It relies
+// on the identifiers of the package having been resolved with astutil.Resolve,
+// as is done when loading the package.
+func (r *renamer) findRefs() error {
+	for _, f := range r.pkg.Files {
+		imports := map[string]bool{}
+		for _, spec := range f.Imports {
+			imports[astutil.ImportName(spec)] = true
+		}
+		// Unresolved references may refer to declarations in other files.
+		unresolved := map[*ast.Ident]bool{}
+		for _, x := range f.Unresolved {
+			unresolved[x] = !imports[x.Name]
+		}
+		var refs []*ast.Ident
+		ast.Walk(f, func(n ast.Node) bool {
+			x, ok := n.(*ast.Ident)
+			if ok && x.Name == r.name && (r.values[x.Node] || unresolved[x]) {
+				refs = append(refs, x)
+			}
+			return true
+		}, nil)
+		if err := r.checkShadowed(f, refs); err != nil {
+			return err
+		}
+		for _, x := range refs {
+			r.rename(f, x)
+		}
+	}
+	return nil
+}
+
+// checkShadowed reports an error if any of the references refs in f would
+// refer to another declaration after the rename. It resolves f again, with the
+// declarations and refs renamed, and restores f afterwards.
+func (r *renamer) checkShadowed(f *ast.File, refs []*ast.Ident) (err error) {
+	type link struct{ node, scope ast.Node }
+	links := map[*ast.Ident]link{}
+	ast.Walk(f, func(n ast.Node) bool {
+		if x, ok := n.(*ast.Ident); ok {
+			links[x] = link{x.Node, x.Scope}
+			x.Node, x.Scope = nil, nil
+		}
+		return true
+	}, nil)
+	unresolved := f.Unresolved
+	renamed := append(append([]*ast.Ident(nil), r.labels...), refs...)
+
+	defer func() {
+		for _, x := range renamed {
+			x.Name = r.name
+		}
+		for x, l := range links {
+			x.Node, x.Scope = l.node, l.scope
+		}
+		f.Unresolved = unresolved
+	}()
+
+	for _, x := range renamed {
+		x.Name = r.newName
+	}
+	f.Unresolved = nil
+	astutil.Resolve(f, func(token.Pos, string, ...interface{}) {})
+
+	for _, x := range refs {
+		if x.Node != nil && !r.values[x.Node] {
+			return errors.Newf(x.Pos(),
+				"cannot rename %s to %s: reference would be shadowed by %s declared at line %d",
+				r.name, r.newName, r.newName, x.Node.Pos().Line())
+		}
+	}
+	return nil
+}
+
+// findQualifiedRefs finds references to name in inst through imports of the
+// renamed package and reports whether there were any.
+func (r *renamer) findQualifiedRefs(inst *build.Instance) bool {
+	found := false
+	for _, f := range inst.Files {
+		specs := map[string]*ast.ImportSpec{}
+		for _, spec := range f.Imports {
+			p, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if imp := inst.LookupImport(p); imp != r.pkg && p != r.pkg.ImportPath {
+				continue
+			}
+			name := r.pkg.PkgName
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			specs[name] = spec
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		ast.Walk(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			spec, ok := specs[x.Name]
+			if !ok || (x.Node != nil && x.Node != spec) {
+				return true
+			}
+			if sel.Sel.Name == r.name {
+				r.rename(f, sel.Sel)
+				found = true
+			}
+			return true
+		}, nil)
+	}
+	return found
+}
+
+// visible reports whether an identifier with the given name is visible
+// outside its package.
+func visible(name string, isDef bool) bool {
+	if strings.HasPrefix(name, "_") {
+		return false
+	}
+	if !isDef {
+		return true
+	}
+	c, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(c)
+}
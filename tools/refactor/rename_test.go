@@ -0,0 +1,163 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refactor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/load"
+)
+
+func TestRename(t *testing.T) {
+	testCases := []struct {
+		name    string
+		newName string
+		out     string
+		err     string
+	}{{
+		name:    "Foo",
+		newName: "Baz",
+		out: `
+-- a/a.cue --
+package a
+
+// Foo is a definition.
+Baz :: {
+	x: int
+}
+
+bar: Baz & {x: 1} // a reference
+
+s: {
+	Bar: 1
+	t:   Baz
+}
+
+-- a/b.cue --
+package a
+
+baz: Baz
+
+qux: {
+	Foo: 1
+	y:   Foo // refers to qux.Foo
+}
+
+-- b/b.cue --
+package b
+
+import "example.com/a"
+
+x: a.Baz & {x: 2}
+
+-- b/c.cue --
+package b
+
+import aa "example.com/a"
+
+y: aa.Baz
+z: {
+	a: aa.Baz
+}
+`,
+	}, {
+		name:    "Foo",
+		newName: "Bar",
+		err:     "cannot rename Foo to Bar: reference would be shadowed by Bar declared at line 11",
+	}, {
+		name:    "Foo",
+		newName: "t",
+		err:     "cannot rename Foo to t: reference would be shadowed by t declared at line 12",
+	}, {
+		name:    "Foo",
+		newName: "bar",
+		err:     "cannot rename Foo to bar: bar already declared",
+	}, {
+		name:    "bar",
+		newName: "Bar",
+		out: `
+-- a/a.cue --
+package a
+
+// Foo is a definition.
+Foo :: {
+	x: int
+}
+
+Bar: Foo & {x: 1} // a reference
+
+s: {
+	Bar: 1
+	t:   Foo
+}
+`,
+	}, {
+		name:    "Foo",
+		newName: "foo",
+		err:     "cannot rename Foo to foo: foo would not be visible outside package a",
+	}, {
+		name:    "Qux",
+		newName: "Quux",
+		err:     "Qux not declared in package example.com/a",
+	}, {
+		name:    "Foo",
+		newName: "1x",
+		err:     `invalid identifier "1x"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name+"->"+tc.newName, func(t *testing.T) {
+			dir, _ := filepath.Abs("testdata")
+			insts := load.Instances([]string{"./..."}, &load.Config{Dir: dir})
+			for _, inst := range insts {
+				if inst.Err != nil {
+					t.Fatal(inst.Err)
+				}
+			}
+
+			files, err := Rename(insts, "example.com/a", tc.name, tc.newName)
+			if tc.err != "" {
+				if err == nil {
+					t.Fatalf("expected error %q", tc.err)
+				}
+				msg := errors.Details(err, &errors.Config{Cwd: dir})
+				if !strings.Contains(msg, tc.err) {
+					t.Fatalf("got error %q; want %q", msg, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := &strings.Builder{}
+			for _, f := range files {
+				out, err := format.Node(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rel, _ := filepath.Rel(dir, f.Filename)
+				fmt.Fprintf(b, "\n-- %s --\n%s", filepath.ToSlash(rel), out)
+			}
+			if got := b.String(); got != tc.out {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.out)
+			}
+		})
+	}
+}
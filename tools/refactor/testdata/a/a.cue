@@ -0,0 +1,13 @@
+package a
+
+// Foo is a definition.
+Foo :: {
+	x: int
+}
+
+bar: Foo & {x: 1} // a reference
+
+s: {
+	Bar: 1
+	t:   Foo
+}
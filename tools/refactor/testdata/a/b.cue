@@ -0,0 +1,8 @@
+package a
+
+baz: Foo
+
+qux: {
+	Foo: 1
+	y:   Foo // refers to qux.Foo
+}
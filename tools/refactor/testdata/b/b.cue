@@ -0,0 +1,5 @@
+package b
+
+import "example.com/a"
+
+x: a.Foo & {x: 2}
@@ -0,0 +1,8 @@
+package b
+
+import aa "example.com/a"
+
+y: aa.Foo
+z: {
+	a: aa.Foo
+}